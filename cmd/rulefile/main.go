@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"xlsx-processor/pkg/rulefile"
+)
+
+// variables collects the repeated -var name=value flags
+type variables map[string]string
+
+func (v variables) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v variables) Set(value string) error {
+	name, variable, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("'%s' is invalid, expected name=value", value)
+	}
+	v[name] = variable
+	return nil
+}
+
+// Reads a rule file along with its includes and prints the rules as the JSON of a request body
+func main() {
+	vars := variables{}
+	flag.Var(vars, "var", "a variable substituted in the rules, eg: -var client_name=Acme")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: rulefile [-var name=value]... rules.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fileName := flag.Arg(0)
+	loader := rulefile.MakeLoader(rulefile.DirReader(filepath.Dir(fileName)), vars)
+	rules, err := loader.Load(filepath.Base(fileName))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(rules); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
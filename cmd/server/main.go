@@ -22,6 +22,24 @@ func main() {
 
 	router.POST("/xlsx-processor/transform-json", routes.TransformJson)
 
+	router.POST("/xlsx-processor/verify", routes.Verify)
+
+	router.POST("/xlsx-processor/scan", routes.Scan)
+
+	router.POST("/xlsx-processor/rules/validate", routes.ValidateRules)
+
+	router.GET("/xlsx-processor/rule-profiles", routes.ListRuleProfiles)
+
+	router.POST("/xlsx-processor/rule-profiles", routes.CreateRuleProfile)
+
+	router.GET("/xlsx-processor/rule-profiles/:id", routes.GetRuleProfile)
+
+	router.GET("/xlsx-processor/rule-profiles/:id/versions", routes.GetRuleProfileVersions)
+
+	router.PUT("/xlsx-processor/rule-profiles/:id", routes.UpdateRuleProfile)
+
+	router.DELETE("/xlsx-processor/rule-profiles/:id", routes.DeleteRuleProfile)
+
 	router.GET("/xlsx-processor/healthz/ready", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "ok"})
 	})
@@ -0,0 +1,52 @@
+package cell
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// MergeArea is the rectangle covered by a merged cell, the value lives in the top left cell
+type MergeArea struct {
+	StartCol int
+	StartRow int
+	EndCol   int
+	EndRow   int
+}
+
+// TopLeft returns the name of the cell holding the value of the merged area
+func (m MergeArea) TopLeft() string {
+	cellName, _ := excelize.CoordinatesToCellName(m.StartCol, m.StartRow)
+	return cellName
+}
+
+// GetMergeAreas returns the merged areas of a sheet
+func GetMergeAreas(f *excelize.File, sheetName string) ([]MergeArea, error) {
+	mergeCells, err := f.GetMergeCells(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeAreas := make([]MergeArea, 0, len(mergeCells))
+	for _, mergeCell := range mergeCells {
+		startCol, startRow, err := excelize.CellNameToCoordinates(mergeCell.GetStartAxis())
+		if err != nil {
+			return nil, err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(mergeCell.GetEndAxis())
+		if err != nil {
+			return nil, err
+		}
+		mergeAreas = append(mergeAreas, MergeArea{startCol, startRow, endCol, endRow})
+	}
+
+	return mergeAreas, nil
+}
+
+// FindMergeArea returns the merged area containing the cell, if any
+func FindMergeArea(mergeAreas []MergeArea, col, row int) (MergeArea, bool) {
+	for _, mergeArea := range mergeAreas {
+		if col >= mergeArea.StartCol && col <= mergeArea.EndCol && row >= mergeArea.StartRow && row <= mergeArea.EndRow {
+			return mergeArea, true
+		}
+	}
+	return MergeArea{}, false
+}
@@ -0,0 +1,66 @@
+package comment
+
+import (
+	"cmp"
+	"slices"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// GetComments returns the notes and the threaded comments of a sheet. Excel keeps a note with a
+// placeholder text next to every comment thread, those notes are left out
+func GetComments(f *excelize.File, sheetName string) ([]types.Comment, error) {
+	threadedComments, err := GetThreadedComments(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	threadedCells := map[string]bool{}
+	for _, threadedComment := range threadedComments {
+		threadedCells[threadedComment.Cell] = true
+	}
+
+	notes, err := f.GetComments(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	// Notes are listed in the order they were added, sorting them by row then column
+	slices.SortStableFunc(notes, func(a, b excelize.Comment) int {
+		aCol, aRow, _ := excelize.CellNameToCoordinates(a.Cell)
+		bCol, bRow, _ := excelize.CellNameToCoordinates(b.Cell)
+		return cmp.Or(cmp.Compare(aRow, bRow), cmp.Compare(aCol, bCol))
+	})
+
+	var comments []types.Comment
+	for _, note := range notes {
+		if threadedCells[note.Cell] {
+			continue
+		}
+		comments = append(comments, types.Comment{
+			Cell:   note.Cell,
+			Author: note.Author,
+			Text:   GetNoteText(note),
+		})
+	}
+	for _, threadedComment := range threadedComments {
+		comments = append(comments, types.Comment{
+			Cell:     threadedComment.Cell,
+			Author:   threadedComment.Author,
+			Text:     threadedComment.Text,
+			Threaded: true,
+			IsReply:  threadedComment.ParentID != "",
+		})
+	}
+
+	return comments, nil
+}
+
+// GetNoteText joins the text of a note with the text of its runs
+func GetNoteText(note excelize.Comment) string {
+	text := note.Text
+	for _, run := range note.Paragraph {
+		text += run.Text
+	}
+	return text
+}
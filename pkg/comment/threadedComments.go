@@ -0,0 +1,148 @@
+package comment
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+
+	"xlsx-processor/pkg/ooxml"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Threaded comments are not supported by excelize. Their elements never nest, so the parts are
+// edited textually and everything else in them, such as mentions, is kept as it is
+var (
+	threadedCommentPattern = regexp.MustCompile(`(?s)<threadedComment\b[^>]*?(?:/>|>.*?</threadedComment>)`)
+	textPattern            = regexp.MustCompile(`(?s)<text>(.*?)</text>`)
+	personPattern          = regexp.MustCompile(`<person\b[^>]*>`)
+	attrPatterns           = map[string]*regexp.Regexp{}
+)
+
+func init() {
+	for _, name := range []string{"ref", "id", "parentId", "personId", "displayName"} {
+		attrPatterns[name] = regexp.MustCompile(`\s` + name + `="([^"]*)"`)
+	}
+}
+
+// ThreadedComment is a comment or a reply of a comment thread
+type ThreadedComment struct {
+	Cell     string
+	ID       string
+	ParentID string
+	PersonID string
+	Author   string
+	Text     string
+}
+
+// getAttr returns the unescaped value of an attribute of an element
+func getAttr(element, name string) string {
+	matches := attrPatterns[name].FindStringSubmatch(element)
+	if matches == nil {
+		return ""
+	}
+	return html.UnescapeString(matches[1])
+}
+
+// parseThreadedComment reads a threaded comment element
+func parseThreadedComment(element string, authors map[string]string) ThreadedComment {
+	threadedComment := ThreadedComment{
+		Cell:     getAttr(element, "ref"),
+		ID:       getAttr(element, "id"),
+		ParentID: getAttr(element, "parentId"),
+		PersonID: getAttr(element, "personId"),
+	}
+	threadedComment.Author = authors[threadedComment.PersonID]
+	if matches := textPattern.FindStringSubmatch(element); matches != nil {
+		threadedComment.Text = html.UnescapeString(matches[1])
+	}
+	return threadedComment
+}
+
+// getAuthors maps the ids of the people of the workbook to their display names
+func getAuthors(f *excelize.File) (map[string]string, error) {
+	authors := map[string]string{}
+	personParts, err := ooxml.RelatedParts(f, "xl/workbook.xml", "/person")
+	if err != nil {
+		return nil, err
+	}
+	for _, personPart := range personParts {
+		for _, person := range personPattern.FindAllString(string(ooxml.ReadFilePart(f, personPart)), -1) {
+			authors[getAttr(person, "id")] = getAttr(person, "displayName")
+		}
+	}
+	return authors, nil
+}
+
+// getThreadedCommentsParts returns the threaded comments parts of a sheet
+func getThreadedCommentsParts(f *excelize.File, sheetName string) ([]string, error) {
+	sheetPath, err := ooxml.SheetPath(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	return ooxml.RelatedParts(f, sheetPath, "/threadedComment")
+}
+
+// GetThreadedComments returns the threaded comments and replies of a sheet
+func GetThreadedComments(f *excelize.File, sheetName string) ([]ThreadedComment, error) {
+	parts, err := getThreadedCommentsParts(f, sheetName)
+	if err != nil || len(parts) == 0 {
+		return nil, err
+	}
+	authors, err := getAuthors(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var threadedComments []ThreadedComment
+	for _, part := range parts {
+		for _, element := range threadedCommentPattern.FindAllString(string(ooxml.ReadFilePart(f, part)), -1) {
+			threadedComments = append(threadedComments, parseThreadedComment(element, authors))
+		}
+	}
+	return threadedComments, nil
+}
+
+// UpdateThreadedComments calls update for every threaded comment of a sheet. Changes to the
+// text are saved, returning false removes the comment along with its replies
+func UpdateThreadedComments(f *excelize.File, sheetName string, update func(threadedComment *ThreadedComment) bool) error {
+	parts, err := getThreadedCommentsParts(f, sheetName)
+	if err != nil || len(parts) == 0 {
+		return err
+	}
+	authors, err := getAuthors(f)
+	if err != nil {
+		return err
+	}
+
+	removedIDs := map[string]bool{}
+	for _, part := range parts {
+		var updateErr error
+		content := threadedCommentPattern.ReplaceAllStringFunc(string(ooxml.ReadFilePart(f, part)), func(element string) string {
+			threadedComment := parseThreadedComment(element, authors)
+			originalText := threadedComment.Text
+			// Replies follow their parent comment
+			if removedIDs[threadedComment.ParentID] || !update(&threadedComment) {
+				removedIDs[threadedComment.ID] = true
+				return ""
+			}
+			if threadedComment.Text == originalText {
+				return element
+			}
+
+			var text bytes.Buffer
+			if err := xml.EscapeText(&text, []byte(threadedComment.Text)); err != nil {
+				updateErr = err
+				return element
+			}
+			return textPattern.ReplaceAllLiteralString(element, "<text>"+text.String()+"</text>")
+		})
+		if updateErr != nil {
+			return updateErr
+		}
+		f.Pkg.Store(part, []byte(content))
+	}
+
+	return nil
+}
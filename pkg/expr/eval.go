@@ -0,0 +1,348 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxTextLength is the longest text an expression can build, the limit of an Excel cell
+const MaxTextLength = 32767
+
+// Function is a function the caller adds to an evaluation, eg: col("Country")
+type Function func(args []any) (any, error)
+
+// Env holds what an expression can read. Values are strings, float64 numbers, booleans, nil or
+// maps of strings, eg: the row by header. Nothing else is reachable from an expression
+type Env struct {
+	Variables map[string]any
+	Functions map[string]Function
+}
+
+// Eval evaluates the expression, the result is a string, a float64, a bool or nil
+func (e *Expression) Eval(env Env) (any, error) {
+	result, err := eval(e.root, env)
+	if err != nil {
+		return nil, err
+	}
+	if _, isMap := result.(map[string]string); isMap {
+		return nil, fmt.Errorf("the result is a map, expected a value such as row[\"Amount\"]")
+	}
+	return result, nil
+}
+
+// Check reports the unknown variables and functions without evaluating the expression
+func (e *Expression) Check(variables []string, functions []string) error {
+	return check(e.root, variables, functions)
+}
+
+func check(n node, variables []string, functions []string) error {
+	switch n := n.(type) {
+	case *identifier:
+		if !slices.Contains(variables, n.name) {
+			return fmt.Errorf("at %d: unknown variable '%s'", n.pos, n.name)
+		}
+	case *unary:
+		return check(n.operand, variables, functions)
+	case *binary:
+		if err := check(n.left, variables, functions); err != nil {
+			return err
+		}
+		return check(n.right, variables, functions)
+	case *index:
+		if err := check(n.target, variables, functions); err != nil {
+			return err
+		}
+		return check(n.key, variables, functions)
+	case *call:
+		if builtin, found := builtins[n.name]; found {
+			if len(n.args) < builtin.minArgs || (builtin.maxArgs >= 0 && len(n.args) > builtin.maxArgs) {
+				return fmt.Errorf("at %d: %s expects %s", n.pos, n.name, arity(builtin))
+			}
+		} else if n.name != "if" && !slices.Contains(functions, n.name) {
+			return fmt.Errorf("at %d: unknown function '%s'", n.pos, n.name)
+		} else if n.name == "if" && len(n.args) != 3 {
+			return fmt.Errorf("at %d: if expects 3 arguments", n.pos)
+		}
+		for _, arg := range n.args {
+			if err := check(arg, variables, functions); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func eval(n node, env Env) (any, error) {
+	switch n := n.(type) {
+	case *literal:
+		return n.value, nil
+	case *identifier:
+		value, found := env.Variables[n.name]
+		if !found {
+			return nil, fmt.Errorf("at %d: unknown variable '%s'", n.pos, n.name)
+		}
+		return value, nil
+	case *unary:
+		operand, err := eval(n.operand, env)
+		if err != nil {
+			return nil, err
+		}
+		if n.operator == "!" {
+			return !truthy(operand), nil
+		}
+		number, err := toNumber(operand)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: %w", n.pos, err)
+		}
+		return -number, nil
+	case *binary:
+		return evalBinary(n, env)
+	case *index:
+		target, err := eval(n.target, env)
+		if err != nil {
+			return nil, err
+		}
+		key, err := eval(n.key, env)
+		if err != nil {
+			return nil, err
+		}
+		values, isMap := target.(map[string]string)
+		if !isMap {
+			return nil, fmt.Errorf("at %d: only maps such as row can be indexed", n.pos)
+		}
+		value, found := values[toText(key)]
+		if !found {
+			return nil, fmt.Errorf("at %d: no column '%s'", n.pos, toText(key))
+		}
+		return value, nil
+	case *call:
+		return evalCall(n, env)
+	}
+	return nil, fmt.Errorf("unexpected node %T", n)
+}
+
+func evalBinary(n *binary, env Env) (any, error) {
+	left, err := eval(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	// The logical operators only evaluate their right side when needed
+	switch n.operator {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := eval(n.right, env)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := eval(n.right, env)
+		return truthy(right), err
+	}
+
+	right, err := eval(n.right, env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.operator {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "&":
+		return limitText(toText(left)+toText(right), n.pos)
+	case "<", "<=", ">", ">=":
+		comparison, err := compare(left, right)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: %w", n.pos, err)
+		}
+		switch n.operator {
+		case "<":
+			return comparison < 0, nil
+		case "<=":
+			return comparison <= 0, nil
+		case ">":
+			return comparison > 0, nil
+		}
+		return comparison >= 0, nil
+	}
+
+	a, err := toNumber(left)
+	if err != nil {
+		return nil, fmt.Errorf("at %d: %w", n.pos, err)
+	}
+	b, err := toNumber(right)
+	if err != nil {
+		return nil, fmt.Errorf("at %d: %w", n.pos, err)
+	}
+	switch n.operator {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("at %d: division by zero", n.pos)
+		}
+		return a / b, nil
+	}
+	if b == 0 {
+		return nil, fmt.Errorf("at %d: division by zero", n.pos)
+	}
+	return math.Mod(a, b), nil
+}
+
+func evalCall(n *call, env Env) (any, error) {
+	// if only evaluates the branch it returns
+	if n.name == "if" {
+		if len(n.args) != 3 {
+			return nil, fmt.Errorf("at %d: if expects 3 arguments", n.pos)
+		}
+		condition, err := eval(n.args[0], env)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(condition) {
+			return eval(n.args[1], env)
+		}
+		return eval(n.args[2], env)
+	}
+
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		value, err := eval(arg, env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	if builtin, found := builtins[n.name]; found {
+		if len(args) < builtin.minArgs || (builtin.maxArgs >= 0 && len(args) > builtin.maxArgs) {
+			return nil, fmt.Errorf("at %d: %s expects %s", n.pos, n.name, arity(builtin))
+		}
+		result, err := builtin.call(args)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: %s: %w", n.pos, n.name, err)
+		}
+		if text, isText := result.(string); isText {
+			return limitText(text, n.pos)
+		}
+		return result, nil
+	}
+	function, found := env.Functions[n.name]
+	if !found {
+		return nil, fmt.Errorf("at %d: unknown function '%s'", n.pos, n.name)
+	}
+	result, err := function(args)
+	if err != nil {
+		return nil, fmt.Errorf("at %d: %s: %w", n.pos, n.name, err)
+	}
+	return result, nil
+}
+
+func limitText(text string, pos int) (any, error) {
+	if len(text) > MaxTextLength {
+		return nil, fmt.Errorf("at %d: the text is longer than %d characters", pos, MaxTextLength)
+	}
+	return text, nil
+}
+
+// truthy is false for false, nil, 0 and the empty text
+func truthy(value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return value
+	case string:
+		return value != ""
+	case float64:
+		return value != 0
+	case map[string]string:
+		return len(value) > 0
+	}
+	return false
+}
+
+// toNumber converts the texts holding a number, cell values are texts
+func toNumber(value any) (float64, error) {
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a number", value)
+		}
+		return number, nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("a map is not a number")
+}
+
+// ToText converts a result to the text of a cell, numbers are written without trailing zeros
+func ToText(value any) string {
+	return toText(value)
+}
+
+func toText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+// equal compares as numbers when one side is a number, eg: row["Amount"] == 100
+func equal(left, right any) bool {
+	_, leftIsNumber := left.(float64)
+	_, rightIsNumber := right.(float64)
+	if leftIsNumber || rightIsNumber {
+		a, errLeft := toNumber(left)
+		b, errRight := toNumber(right)
+		if errLeft == nil && errRight == nil {
+			return a == b
+		}
+	}
+	return toText(left) == toText(right)
+}
+
+// compare orders as numbers when one side is a number, as texts otherwise
+func compare(left, right any) (int, error) {
+	_, leftIsNumber := left.(float64)
+	_, rightIsNumber := right.(float64)
+	if !leftIsNumber && !rightIsNumber {
+		return strings.Compare(toText(left), toText(right)), nil
+	}
+	a, err := toNumber(left)
+	if err != nil {
+		return 0, err
+	}
+	b, err := toNumber(right)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,87 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestEval(t *testing.T) {
+	env := Env{
+		Variables: map[string]any{
+			"value": "4111 1111 1111 1111",
+			"row":   map[string]string{"Amount": "100", "Country": "CA"},
+		},
+		Functions: map[string]Function{
+			"col": func(args []any) (any, error) { return "CA", nil },
+		},
+	}
+
+	tests := []struct {
+		expression string
+		result     any
+	}{
+		{`upper("jane") & " " & lower('DOE')`, "JANE doe"},
+		{`row["Amount"] * 2`, 200.0},
+		{`round(row["Amount"] * 1.1, 2)`, 110.0},
+		{`if(col("Country") == "CA", mask(value, 4), value)`, "***************1111"},
+		{`if(row["Country"] != "CA", 1 / 0, "kept")`, "kept"},
+		{`row["Amount"] == 100 && !(2 > 3) || false`, true},
+		{`-row["Amount"] % 30 + max(1, 5, 3)`, -5.0},
+		{`mid("abcdef", 2, 3) & left("xyz", 1) & right("xyz", 5)`, "bcdxxyz"},
+		{`left("xyz", "1e30") & right("xyz", "1e300") & mid("xyz", "1e30", 1) & mask("xyz", "1e30")`, "xyzxyzxyz"},
+		{`coalesce("", null, trim("  a "))`, "a"},
+		{`replace(value, " ", "") & len(value)`, "411111111111111119"},
+		{`isNumber(row["Amount"]) & isNumber("CA") & isEmpty("")`, "truefalsetrue"},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := Parse(test.expression)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			result, err := expression.Eval(env)
+			if err != nil {
+				t.Fatalf("failed to evaluate: %v", err)
+			}
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		message    string
+	}{
+		{`upper(value`, "at 12: expected ',' or ')' at the end"},
+		{`"open`, "at 1: unterminated string"},
+		{`value $ 1`, "at 7: unexpected '$'"},
+		{`1 +`, "at 4: expected a value at the end"},
+		{`open("/etc/passwd")`, "at 1: unknown function 'open'"},
+		{`env`, "at 1: unknown variable 'env'"},
+		{`upper(value, 1)`, "at 1: upper expects 1 argument"},
+		{`value * 2`, "at 7: 'abc' is not a number"},
+		{`row["Missing"]`, "at 4: no column 'Missing'"},
+		{`left(value, "NaN")`, "at 1: left: expected a number of characters, found 'NaN'"},
+		{`right(value, "-Inf")`, "at 1: right: expected a number of characters, found '-Inf'"},
+		{`mask(value, "+Inf")`, "at 1: mask: expected a number of characters, found '+Inf'"},
+		{`mid(value, "NaN", 1)`, "at 1: mid: the start must be a number from 1"},
+		{strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), "at 66: the expression is nested too deep"},
+	}
+	env := Env{Variables: map[string]any{"value": "abc", "row": map[string]string{}}}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := Parse(test.expression)
+			if err == nil {
+				err = expression.Check([]string{"value", "row"}, nil)
+			}
+			if err == nil {
+				_, err = expression.Eval(env)
+			}
+			assert.Equal(t, test.message, fmt.Sprint(err))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtin is a function of the language, maxArgs is -1 for any number of arguments
+type builtin struct {
+	minArgs int
+	maxArgs int
+	call    func(args []any) (any, error)
+}
+
+// builtins only transform their arguments, none of them reaches the filesystem or the network
+var builtins = map[string]builtin{
+	"upper": {1, 1, func(args []any) (any, error) { return strings.ToUpper(toText(args[0])), nil }},
+	"lower": {1, 1, func(args []any) (any, error) { return strings.ToLower(toText(args[0])), nil }},
+	"trim":  {1, 1, func(args []any) (any, error) { return strings.TrimSpace(toText(args[0])), nil }},
+	"len": {1, 1, func(args []any) (any, error) {
+		return float64(utf8.RuneCountInString(toText(args[0]))), nil
+	}},
+	"left": {2, 2, func(args []any) (any, error) {
+		runes := []rune(toText(args[0]))
+		count, err := count(args[1], len(runes))
+		return string(runes[:count]), err
+	}},
+	"right": {2, 2, func(args []any) (any, error) {
+		runes := []rune(toText(args[0]))
+		count, err := count(args[1], len(runes))
+		return string(runes[len(runes)-count:]), err
+	}},
+	// mid(text, start, length) starts at 1 like the MID function of Excel
+	"mid": {3, 3, func(args []any) (any, error) {
+		runes := []rune(toText(args[0]))
+		start, err := toNumber(args[1])
+		if err != nil || math.IsNaN(start) || start < 1 {
+			return nil, fmt.Errorf("the start must be a number from 1")
+		}
+		if start > float64(len(runes)) {
+			return "", nil
+		}
+		runes = runes[int(start)-1:]
+		count, err := count(args[2], len(runes))
+		return string(runes[:count]), err
+	}},
+	"replace": {3, 3, func(args []any) (any, error) {
+		text, old := toText(args[0]), toText(args[1])
+		if old == "" {
+			return text, nil
+		}
+		return strings.ReplaceAll(text, old, toText(args[2])), nil
+	}},
+	"contains": {2, 2, func(args []any) (any, error) {
+		return strings.Contains(toText(args[0]), toText(args[1])), nil
+	}},
+	"startsWith": {2, 2, func(args []any) (any, error) {
+		return strings.HasPrefix(toText(args[0]), toText(args[1])), nil
+	}},
+	"endsWith": {2, 2, func(args []any) (any, error) {
+		return strings.HasSuffix(toText(args[0]), toText(args[1])), nil
+	}},
+	// mask(text, keep, [character]) hides all but the last characters, eg: ************1111
+	"mask": {2, 3, func(args []any) (any, error) {
+		runes := []rune(toText(args[0]))
+		keep, err := count(args[1], len(runes))
+		if err != nil {
+			return nil, err
+		}
+		character := "*"
+		if len(args) == 3 && toText(args[2]) != "" {
+			character = string([]rune(toText(args[2]))[:1])
+		}
+		return strings.Repeat(character, len(runes)-keep) + string(runes[len(runes)-keep:]), nil
+	}},
+	"number": {1, 1, func(args []any) (any, error) { return toNumber(args[0]) }},
+	"text":   {1, 1, func(args []any) (any, error) { return toText(args[0]), nil }},
+	"isNumber": {1, 1, func(args []any) (any, error) {
+		_, err := toNumber(args[0])
+		_, isBool := args[0].(bool)
+		return err == nil && args[0] != nil && !isBool && args[0] != "", nil
+	}},
+	"isEmpty": {1, 1, func(args []any) (any, error) { return toText(args[0]) == "", nil }},
+	// round(number, [digits]) rounds half away from zero
+	"round": {1, 2, func(args []any) (any, error) {
+		number, err := toNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		digits := 0.0
+		if len(args) == 2 {
+			if digits, err = toNumber(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		scale := math.Pow(10, math.Trunc(digits))
+		return math.Round(number*scale) / scale, nil
+	}},
+	"abs": {1, 1, func(args []any) (any, error) {
+		number, err := toNumber(args[0])
+		return math.Abs(number), err
+	}},
+	"min": {1, -1, func(args []any) (any, error) { return extreme(args, -1) }},
+	"max": {1, -1, func(args []any) (any, error) { return extreme(args, 1) }},
+	// coalesce returns the first argument that is not empty
+	"coalesce": {1, -1, func(args []any) (any, error) {
+		for _, arg := range args {
+			if toText(arg) != "" {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	}},
+}
+
+// count reads a number of characters, capped to the length of the text before it is converted
+// so huge numbers cannot overflow
+func count(value any, length int) (int, error) {
+	number, err := toNumber(value)
+	if err != nil || number < 0 || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("expected a number of characters, found '%s'", toText(value))
+	}
+	return int(math.Min(number, float64(length))), nil
+}
+
+// extreme returns the smallest argument when sign is -1, the largest when it is 1
+func extreme(args []any, sign float64) (any, error) {
+	var result float64
+	for i, arg := range args {
+		number, err := toNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || (number-result)*sign > 0 {
+			result = number
+		}
+	}
+	return result, nil
+}
+
+func arity(b builtin) string {
+	switch {
+	case b.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", b.minArgs)
+	case b.minArgs == b.maxArgs && b.minArgs == 1:
+		return "1 argument"
+	case b.minArgs == b.maxArgs:
+		return fmt.Sprintf("%d arguments", b.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", b.minArgs, b.maxArgs)
+}
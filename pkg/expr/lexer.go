@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Token kinds
+const (
+	tokenEOF = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  int
+	text  string
+	value any
+	pos   int
+}
+
+// operators are matched longest first
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "&", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// tokenize splits an expression into tokens, positions are 1-based rune offsets for the errors
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("at %d: '%s' is not a number", pos, string(runes[start:i]))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), value: number, pos: pos})
+		case r == '"' || r == '\'':
+			text, end, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[i:end]), value: text, pos: pos})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: pos})
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("at %d: unexpected '%c'", pos, r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: operator, pos: pos})
+			i += len([]rune(operator))
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// readString reads a quoted string starting at the quote, it returns the text and the end offset
+func readString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var text strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case quote:
+			return text.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(runes) {
+				break
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				text.WriteRune('\n')
+			case 't':
+				text.WriteRune('\t')
+			default:
+				text.WriteRune(runes[i])
+			}
+		default:
+			text.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("at %d: unterminated string", start+1)
+}
@@ -0,0 +1,247 @@
+package expr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MaxLength and maxDepth keep the expressions small enough to review and to evaluate quickly
+const (
+	MaxLength = 2000
+	maxDepth  = 64
+)
+
+// The nodes of an expression
+type (
+	node interface{}
+
+	literal struct {
+		value any
+	}
+	identifier struct {
+		name string
+		pos  int
+	}
+	unary struct {
+		operator string
+		operand  node
+		pos      int
+	}
+	binary struct {
+		operator    string
+		left, right node
+		pos         int
+	}
+	call struct {
+		name string
+		args []node
+		pos  int
+	}
+	index struct {
+		target node
+		key    node
+		pos    int
+	}
+)
+
+// Binary operators from the lowest to the highest precedence
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"&"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+type parser struct {
+	tokens []token
+	next   int
+	depth  int
+}
+
+// Expression is a parsed expression, it can be evaluated any number of times
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse reads an expression, eg: if(col("Country") == "CA", mask(value, 4), value)
+func Parse(source string) (*Expression, error) {
+	if len(source) > MaxLength {
+		return nil, fmt.Errorf("the expression is longer than %d characters", MaxLength)
+	}
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("at %d: unexpected '%s'", t.pos, t.text)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+// expect consumes an operator or reports what was found instead
+func (p *parser) expect(operator string) error {
+	t := p.advance()
+	if t.kind != tokenOperator || t.text != operator {
+		return fmt.Errorf("at %d: expected '%s'%s", t.pos, operator, found(t))
+	}
+	return nil
+}
+
+func found(t token) string {
+	if t.kind == tokenEOF {
+		return " at the end"
+	}
+	return fmt.Sprintf(", found '%s'", t.text)
+}
+
+// parseBinary reads the operators of a precedence level, they are left associative
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || !slices.Contains(precedence[level], t.text) {
+			return left, nil
+		}
+		p.advance()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{operator: t.text, left: left, right: right, pos: t.pos}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "!" || t.text == "-") {
+		p.advance()
+		if p.depth++; p.depth > maxDepth {
+			return nil, fmt.Errorf("at %d: the expression is nested too deep", t.pos)
+		}
+		defer func() { p.depth-- }()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{operator: t.text, operand: operand, pos: t.pos}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix reads a primary expression followed by indexes, eg: row["Amount"]
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || t.text != "[" {
+			return target, nil
+		}
+		p.advance()
+		key, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("]"); err != nil {
+			return nil, err
+		}
+		target = &index{target: target, key: key, pos: t.pos}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literal{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null":
+			return &literal{value: nil}, nil
+		}
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			return p.parseCall(t)
+		}
+		return &identifier{name: t.text, pos: t.pos}, nil
+	case tokenOperator:
+		if t.text == "(" {
+			inner, err := p.parseNested()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("at %d: expected a value%s", t.pos, found(t))
+}
+
+// parseCall reads the arguments of a function call, the name is already read
+func (p *parser) parseCall(name token) (node, error) {
+	p.advance()
+	c := &call{name: name.text, pos: name.pos}
+	if t := p.peek(); t.kind == tokenOperator && t.text == ")" {
+		p.advance()
+		return c, nil
+	}
+	for {
+		arg, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		t := p.advance()
+		if t.kind == tokenOperator && t.text == ")" {
+			return c, nil
+		}
+		if t.kind != tokenOperator || t.text != "," {
+			return nil, fmt.Errorf("at %d: expected ',' or ')'%s", t.pos, found(t))
+		}
+	}
+}
+
+// parseNested reads a parenthesized expression, an argument or an index, which count towards the depth
+func (p *parser) parseNested() (node, error) {
+	if p.depth++; p.depth > maxDepth {
+		return nil, fmt.Errorf("at %d: the expression is nested too deep", p.peek().pos)
+	}
+	defer func() { p.depth-- }()
+	return p.parseBinary(0)
+}
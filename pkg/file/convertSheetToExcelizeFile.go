@@ -2,6 +2,7 @@ package file
 
 import (
 	"fmt"
+	"strings"
 	"xlsx-processor/pkg/types"
 
 	"github.com/xuri/excelize/v2"
@@ -60,5 +61,17 @@ func ConvertSheetToExcelizeFile(sheet types.Sheet) (*excelize.File, error) {
 		}
 	}
 
+	// Merge the cells once their values are set
+	for _, merge := range sheet.Merges {
+		cells := strings.Split(merge, ":")
+		if len(cells) != 2 {
+			return nil, fmt.Errorf("invalid merged area %s", merge)
+		}
+		err = f.MergeCell(sheet.SheetName, cells[0], cells[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge cells %s: %w", merge, err)
+		}
+	}
+
 	return f, nil
 }
@@ -131,4 +131,29 @@ func TestConvertSheetToExcelizeFile(t *testing.T) {
 		}
 		assert.Equal(t, "", val)
 	})
+
+	t.Run("convert sheet with merged cells", func(t *testing.T) {
+		sheet := types.Sheet{
+			SheetName: "MergedSheet",
+			Cells: [][]types.StyledCell{
+				{{Value: "Title"}, {Value: ""}},
+				{{Value: "A2"}, {Value: "B2"}},
+			},
+			Merges: []string{"A1:B1"},
+		}
+
+		f, err := ConvertSheetToExcelizeFile(sheet)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		// Verify the merged area and its value
+		mergeCells, err := f.GetMergeCells("MergedSheet")
+		if err != nil {
+			t.Fatalf("Failed to get merged cells: %v", err)
+		}
+		assert.Equal(t, 1, len(mergeCells))
+		assert.Equal(t, "A1:B1", mergeCells[0].GetStartAxis()+":"+mergeCells[0].GetEndAxis())
+		assert.Equal(t, "Title", mergeCells[0].GetCellValue())
+	})
 }
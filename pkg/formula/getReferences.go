@@ -0,0 +1,195 @@
+package formula
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/xuri/efp"
+	"github.com/xuri/excelize/v2"
+)
+
+// Reference is a rectangular area of a sheet that a formula depends on
+type Reference struct {
+	SheetName string
+	StartCol  int
+	StartRow  int
+	EndCol    int
+	EndRow    int
+}
+
+// Contains reports whether the cell is inside the referenced area
+func (r Reference) Contains(sheetName string, col, row int) bool {
+	return r.SheetName == sheetName && col >= r.StartCol && col <= r.EndCol && row >= r.StartRow && row <= r.EndRow
+}
+
+// GetReferences returns the areas a formula on the given sheet depends on. Defined names
+// and 3D references are resolved, structured and external references are skipped
+func GetReferences(f *excelize.File, sheetName, formula string) []Reference {
+	return getReferences(f, sheetName, formula, 0)
+}
+
+func getReferences(f *excelize.File, sheetName, formula string, depth int) []Reference {
+	var references []Reference
+
+	// Defined names may refer to other defined names, stop before a cycle gets out of hand
+	if depth > 10 {
+		return references
+	}
+
+	parser := efp.ExcelParser()
+	for _, token := range parser.Parse(strings.TrimPrefix(formula, "=")) {
+		if token.TType != efp.TokenTypeOperand || token.TSubType != efp.TokenSubTypeRange {
+			continue
+		}
+		references = append(references, resolveOperand(f, sheetName, token.TValue, depth)...)
+	}
+
+	return references
+}
+
+// resolveOperand converts a range operand such as Sheet2!$A$1:B4 into references
+func resolveOperand(f *excelize.File, sheetName, operand string, depth int) []Reference {
+	// External workbooks cannot be resolved
+	if strings.HasPrefix(operand, "[") {
+		return nil
+	}
+
+	sheetNames := []string{sheetName}
+	area := operand
+	if index := strings.LastIndex(operand, "!"); index != -1 {
+		sheetNames = resolveSheets(f, strings.Trim(operand[:index], "'"))
+		area = operand[index+1:]
+	}
+
+	startCol, startRow, endCol, endRow, ok := ParseArea(area)
+	if !ok {
+		// Not an area so it could be a defined name
+		return resolveDefinedName(f, sheetName, operand, depth)
+	}
+
+	references := make([]Reference, 0, len(sheetNames))
+	for _, name := range sheetNames {
+		references = append(references, Reference{
+			SheetName: name,
+			StartCol:  startCol,
+			StartRow:  startRow,
+			EndCol:    endCol,
+			EndRow:    endRow,
+		})
+	}
+	return references
+}
+
+// resolveSheets expands the sheet part of a reference, Sheet1:Sheet3 covers every sheet in between
+func resolveSheets(f *excelize.File, sheetPart string) []string {
+	bounds := strings.Split(sheetPart, ":")
+	if len(bounds) != 2 || f == nil {
+		return []string{sheetPart}
+	}
+
+	var sheetNames []string
+	inside := false
+	for _, name := range f.GetSheetList() {
+		if name == bounds[0] {
+			inside = true
+		}
+		if inside {
+			sheetNames = append(sheetNames, name)
+		}
+		if name == bounds[1] {
+			break
+		}
+	}
+	return sheetNames
+}
+
+// resolveDefinedName returns the references of a defined name visible from the sheet
+func resolveDefinedName(f *excelize.File, sheetName, name string, depth int) []Reference {
+	if f == nil {
+		return nil
+	}
+
+	var workbookScoped *excelize.DefinedName
+	for _, definedName := range f.GetDefinedName() {
+		if !strings.EqualFold(definedName.Name, name) {
+			continue
+		}
+		// Sheet scoped names take precedence over workbook scoped ones
+		if definedName.Scope == sheetName {
+			return getReferences(f, sheetName, definedName.RefersTo, depth+1)
+		}
+		if definedName.Scope == "" || definedName.Scope == "Workbook" {
+			workbookScoped = &definedName
+		}
+	}
+
+	if workbookScoped == nil {
+		return nil
+	}
+	return getReferences(f, sheetName, workbookScoped.RefersTo, depth+1)
+}
+
+// ParseArea converts an area such as B4, $A$1:C3, A:C or 2:5 into coordinates.
+// Whole columns and rows are expanded to the limits of a sheet
+func ParseArea(area string) (startCol, startRow, endCol, endRow int, ok bool) {
+	parts := strings.Split(strings.ReplaceAll(area, "$", ""), ":")
+	if len(parts) > 2 {
+		return 0, 0, 0, 0, false
+	}
+
+	startCol, startRow, ok = parseAreaPart(parts[0])
+	if !ok {
+		return 0, 0, 0, 0, false
+	}
+	endCol, endRow = startCol, startRow
+	// A lone column or row such as "Tax" or "2" is not an area, it is most likely a defined name
+	if len(parts) == 1 && (startCol == 0 || startRow == 0) {
+		return 0, 0, 0, 0, false
+	}
+	if len(parts) == 2 {
+		endCol, endRow, ok = parseAreaPart(parts[1])
+		if !ok {
+			return 0, 0, 0, 0, false
+		}
+	}
+
+	// Whole columns (A:C) and whole rows (2:5)
+	if (startRow == 0) != (endRow == 0) || (startCol == 0) != (endCol == 0) {
+		return 0, 0, 0, 0, false
+	}
+	if startRow == 0 {
+		startRow, endRow = 1, excelize.TotalRows
+	}
+	if startCol == 0 {
+		startCol, endCol = 1, excelize.MaxColumns
+	}
+
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	if startRow > endRow {
+		startRow, endRow = endRow, startRow
+	}
+	return startCol, startRow, endCol, endRow, true
+}
+
+// parseAreaPart parses one side of an area, a missing row or column is returned as 0
+func parseAreaPart(part string) (col, row int, ok bool) {
+	if part == "" {
+		return 0, 0, false
+	}
+
+	if rowNum, err := strconv.Atoi(part); err == nil {
+		return 0, rowNum, rowNum > 0
+	}
+
+	if colNum, err := excelize.ColumnNameToNumber(part); err == nil {
+		return colNum, 0, true
+	}
+
+	col, row, err := excelize.CellNameToCoordinates(part)
+	if err != nil {
+		return 0, 0, false
+	}
+	return col, row, true
+}
@@ -0,0 +1,40 @@
+package formula
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGetReferences(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if _, err := f.NewSheet("My Sheet"); err != nil {
+		t.Fatalf("failed to create sheet: %v", err)
+	}
+	err := f.SetDefinedName(&excelize.DefinedName{Name: "Salaries", RefersTo: "'My Sheet'!$B$2:$B$9"})
+	if err != nil {
+		t.Fatalf("failed to set defined name: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		formula  string
+		expected []Reference
+	}{
+		{"same sheet cell", "=B4*2", []Reference{{"Sheet1", 2, 4, 2, 4}}},
+		{"quoted sheet range", "SUM('My Sheet'!$A$1:C3)", []Reference{{"My Sheet", 1, 1, 3, 3}}},
+		{"whole column", "SUM(C:C)", []Reference{{"Sheet1", 3, 1, 3, excelize.TotalRows}}},
+		{"whole row", "SUM(2:3)", []Reference{{"Sheet1", 1, 2, excelize.MaxColumns, 3}}},
+		{"defined name", "SUM(Salaries)", []Reference{{"My Sheet", 2, 2, 2, 9}}},
+		{"3d reference", "SUM('Sheet1:My Sheet'!A1)", []Reference{{"Sheet1", 1, 1, 1, 1}, {"My Sheet", 1, 1, 1, 1}}},
+		{"unknown name and external reference", "Unknown+[1]Sheet1!A1", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetReferences(f, "Sheet1", tc.formula))
+		})
+	}
+}
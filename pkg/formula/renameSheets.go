@@ -0,0 +1,129 @@
+package formula
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// unquotedSheetPattern matches the sheet names a formula can use without quotes
+	unquotedSheetPattern = regexp.MustCompile(`^[A-Za-z_\\][A-Za-z0-9_.]*$`)
+	// cellLikePattern matches the names Excel would read as a cell, eg: AB12 or R1C1
+	cellLikePattern = regexp.MustCompile(`^(?i:[A-Z]{1,3}\d+|R\d*C\d*|R|C|TRUE|FALSE)$`)
+)
+
+// RenameSheets replaces the sheet names of the references of a formula, eg: SUM('Old Name'!A1)
+// becomes SUM(Sheet2!A1). Sheet names are compared without case as Excel does. String literals
+// and the sheets of external workbooks are left as they are
+func RenameSheets(formula string, renames map[string]string) string {
+	lowerRenames := make(map[string]string, len(renames))
+	for from, to := range renames {
+		lowerRenames[strings.ToLower(from)] = to
+	}
+	renameSheetPart := func(sheetPart string, quoted bool) string {
+		names := strings.Split(sheetPart, ":")
+		renamed := false
+		for i, name := range names {
+			if to, found := lowerRenames[strings.ToLower(name)]; found {
+				names[i] = to
+				renamed = true
+			}
+		}
+		if !renamed {
+			return ""
+		}
+		for _, name := range names {
+			quoted = quoted || needsQuotes(name)
+		}
+		if quoted {
+			return "'" + strings.ReplaceAll(strings.Join(names, ":"), "'", "''") + "'"
+		}
+		return strings.Join(names, ":")
+	}
+
+	var builder strings.Builder
+	external := false
+	for i := 0; i < len(formula); {
+		char := formula[i]
+		switch {
+		case char == '"':
+			end := closingQuote(formula, i, '"')
+			builder.WriteString(formula[i:end])
+			i = end
+		case char == '[':
+			// External workbooks, eg: [1]Sheet1!A1, and structured references, eg: Table1[[#This Row],[Salary]]
+			end, depth := i, 0
+			for ; end < len(formula); end++ {
+				if formula[end] == '[' {
+					depth++
+				} else if formula[end] == ']' {
+					depth--
+					if depth == 0 {
+						end++
+						break
+					}
+				}
+			}
+			builder.WriteString(formula[i:end])
+			external = true
+			i = end
+		case char == '\'':
+			end := closingQuote(formula, i, '\'')
+			sheetPart := strings.ReplaceAll(formula[i+1:end-1], "''", "'")
+			// A quoted sheet part may start with the external workbook, eg: '[1]Old Name'!A1
+			isSheet := end < len(formula) && formula[end] == '!' && !strings.HasPrefix(sheetPart, "[")
+			if renamed := renameSheetPart(sheetPart, true); isSheet && !external && renamed != "" {
+				builder.WriteString(renamed)
+			} else {
+				builder.WriteString(formula[i:end])
+			}
+			external = false
+			i = end
+		case isNameChar(char):
+			end := i
+			for end < len(formula) && (isNameChar(formula[end]) || formula[end] == ':') {
+				end++
+			}
+			isSheet := end < len(formula) && formula[end] == '!'
+			if renamed := renameSheetPart(formula[i:end], false); isSheet && !external && renamed != "" {
+				builder.WriteString(renamed)
+			} else {
+				builder.WriteString(formula[i:end])
+			}
+			external = false
+			i = end
+		default:
+			builder.WriteByte(char)
+			external = false
+			i++
+		}
+	}
+	return builder.String()
+}
+
+// closingQuote returns the position following the quote closing the one at start, doubled
+// quotes are escaped quotes
+func closingQuote(formula string, start int, quote byte) int {
+	for i := start + 1; i < len(formula); i++ {
+		if formula[i] != quote {
+			continue
+		}
+		if i+1 < len(formula) && formula[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(formula)
+}
+
+// isNameChar reports whether a byte can be part of an unquoted sheet name, a function or a cell
+func isNameChar(char byte) bool {
+	return char == '_' || char == '.' || char == '\\' || char >= 0x80 ||
+		(char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+// needsQuotes reports whether a sheet name must be quoted in a formula
+func needsQuotes(name string) bool {
+	return !unquotedSheetPattern.MatchString(name) || cellLikePattern.MatchString(name)
+}
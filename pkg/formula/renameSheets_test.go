@@ -0,0 +1,34 @@
+package formula
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRenameSheets(t *testing.T) {
+	renames := map[string]string{"Acme Corp": "Sheet1", "Budget": "Sheet 2", "Sheet1": "A1"}
+
+	testCases := []struct {
+		name     string
+		formula  string
+		expected string
+	}{
+		{"quoted sheet", "SUM('Acme Corp'!A1:B2)", "SUM('Sheet1'!A1:B2)"},
+		{"unquoted sheet needing quotes", "Budget!$A$1*2", "'Sheet 2'!$A$1*2"},
+		{"case insensitive", "budget!A1", "'Sheet 2'!A1"},
+		{"cell like name", "Sheet1!A1", "'A1'!A1"},
+		{"renames are simultaneous", "'Acme Corp'!A1+Sheet1!A1", "'Sheet1'!A1+'A1'!A1"},
+		{"3d reference", "SUM(Budget:Other!A1)", "SUM('Sheet 2:Other'!A1)"},
+		{"string literal", `"Budget!A1"&Budget!A1`, `"Budget!A1"&'Sheet 2'!A1`},
+		{"external workbook", "[1]Budget!A1+'[2]Acme Corp'!A1", "[1]Budget!A1+'[2]Acme Corp'!A1"},
+		{"escaped quote", "'O''Brien'!A1+Budget!A1", "'O''Brien'!A1+'Sheet 2'!A1"},
+		{"names and functions", "Budget+VLOOKUP(Table1[Budget],Budget!A:B,2)", "Budget+VLOOKUP(Table1[Budget],'Sheet 2'!A:B,2)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RenameSheets(tc.formula, renames))
+		})
+	}
+}
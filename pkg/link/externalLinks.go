@@ -0,0 +1,79 @@
+package link
+
+import (
+	"regexp"
+
+	"xlsx-processor/pkg/ooxml"
+)
+
+const workbookPath = "xl/workbook.xml"
+
+var externalReferencePattern = regexp.MustCompile(`<externalReference\b[^>]*/>|<externalReference\b[^>]*>\s*</externalReference>`)
+
+// ExternalLink is a link to another workbook. Formulas refer to it by its index, eg: [1]Sheet1!A1
+type ExternalLink struct {
+	Index int
+	// Part is the external link part, eg: xl/externalLinks/externalLink1.xml
+	Part string
+	// RelationshipID is the relationship of the workbook pointing to the part
+	RelationshipID string
+	// Target is the path of the other workbook
+	Target string
+	// TargetRelationshipID is the relationship of the part holding the path
+	TargetRelationshipID string
+}
+
+// GetExternalLinks returns the external workbook links in the order formulas number them
+func GetExternalLinks(p *ooxml.Package) ([]ExternalLink, error) {
+	workbook, _ := p.Part(workbookPath)
+	workbookRelsPath := ooxml.RelsPath(workbookPath)
+	workbookRelationships, err := p.Relationships(workbookRelsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var externalLinks []ExternalLink
+	for index, externalReference := range externalReferencePattern.FindAllString(string(workbook), -1) {
+		externalLink := ExternalLink{
+			Index:          index + 1,
+			RelationshipID: ooxml.Attribute(externalReference, "r:id"),
+		}
+		for _, relationship := range workbookRelationships.Relationships {
+			if relationship.ID == externalLink.RelationshipID {
+				externalLink.Part = ooxml.ResolveTarget(workbookRelsPath, relationship.Target)
+			}
+		}
+
+		// The path of the other workbook is the external relationship of the part
+		relationships, err := p.Relationships(ooxml.RelsPath(externalLink.Part))
+		if err != nil {
+			return nil, err
+		}
+		for _, relationship := range relationships.Relationships {
+			if relationship.TargetMode == "External" {
+				externalLink.Target = relationship.Target
+				externalLink.TargetRelationshipID = relationship.ID
+			}
+		}
+		externalLinks = append(externalLinks, externalLink)
+	}
+
+	return externalLinks, nil
+}
+
+// RemoveExternalReferences drops the entries of the removed external links from the workbook,
+// the remaining links are numbered again in the order they are left
+func RemoveExternalReferences(p *ooxml.Package, removed []ExternalLink) {
+	workbook, _ := p.Part(workbookPath)
+	content := externalReferencePattern.ReplaceAllStringFunc(string(workbook), func(externalReference string) string {
+		relationshipID := ooxml.Attribute(externalReference, "r:id")
+		for _, externalLink := range removed {
+			if externalLink.RelationshipID == relationshipID {
+				return ""
+			}
+		}
+		return externalReference
+	})
+	content = regexp.MustCompile(`<externalReferences\s*/>|<externalReferences>\s*</externalReferences>`).ReplaceAllString(content, "")
+	p.SetPart(workbookPath, []byte(content))
+}
@@ -0,0 +1,127 @@
+package link
+
+import (
+	"regexp"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Constants for the link types
+const (
+	HYPERLINK     string = "hyperlink"
+	EXTERNAL_LINK string = "externalLink"
+	CONNECTION    string = "connection"
+)
+
+// Links are read textually from the package, the elements never nest
+var (
+	HyperlinkPattern  = regexp.MustCompile(`<hyperlink\b[^>]*/>|<hyperlink\b[^>]*>\s*</hyperlink>`)
+	ConnectionPattern = regexp.MustCompile(`(?s)<connection\b[^>]*/>|<connection\b[^>]*>.*?</connection>`)
+	// ConnectionTargetAttributes hold the address of a connection: the database connection
+	// string, the web query url, the text file and the office data connection file
+	ConnectionTargetAttributes = []string{"connection", "url", "sourceFile", "odcFile"}
+)
+
+const ConnectionsPath = "xl/connections.xml"
+
+// GetLinks returns the hyperlinks of every sheet, the external workbook links and the data connections
+func GetLinks(p *ooxml.Package) ([]types.Link, error) {
+	links, err := GetHyperlinks(p)
+	if err != nil {
+		return nil, err
+	}
+
+	externalLinks, err := GetExternalLinks(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, externalLink := range externalLinks {
+		links = append(links, types.Link{Type: EXTERNAL_LINK, Target: externalLink.Target})
+	}
+
+	connections, _ := p.Part(ConnectionsPath)
+	for _, connection := range ConnectionPattern.FindAllString(string(connections), -1) {
+		links = append(links, types.Link{
+			Type:   CONNECTION,
+			Name:   ooxml.Attribute(connection, "name"),
+			Target: ConnectionTarget(connection),
+		})
+	}
+
+	return links, nil
+}
+
+// GetFileLinks returns the links of an opened excelize file, see GetLinks
+func GetFileLinks(f *excelize.File) ([]types.Link, error) {
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return GetLinks(p)
+}
+
+// GetHyperlinks returns the hyperlinks of every sheet
+func GetHyperlinks(p *ooxml.Package) ([]types.Link, error) {
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var links []types.Link
+	for _, sheetPart := range p.Parts() {
+		if _, isSheet := sheetNames[sheetPart]; !isSheet {
+			continue
+		}
+		data, _ := p.Part(sheetPart)
+		for _, hyperlink := range HyperlinkPattern.FindAllString(string(data), -1) {
+			target, _, err := HyperlinkTarget(p, sheetPart, hyperlink)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, types.Link{
+				Type:      HYPERLINK,
+				SheetName: sheetNames[sheetPart],
+				Cell:      ooxml.Attribute(hyperlink, "ref"),
+				Target:    target,
+			})
+		}
+	}
+	return links, nil
+}
+
+// HyperlinkTarget returns the address of a hyperlink element along with its relationship.
+// Links within the workbook have no relationship, their target is their location, eg: Sheet2!A1
+func HyperlinkTarget(p *ooxml.Package, sheetPart, hyperlink string) (target string, relationshipID string, err error) {
+	relationshipID = ooxml.Attribute(hyperlink, "r:id")
+	if relationshipID == "" {
+		return ooxml.Attribute(hyperlink, "location"), "", nil
+	}
+
+	relationships, err := p.Relationships(ooxml.RelsPath(sheetPart))
+	if err != nil {
+		return "", "", err
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == relationshipID {
+			return relationship.Target, relationshipID, nil
+		}
+	}
+	return "", relationshipID, nil
+}
+
+// ConnectionTarget returns the address of a data connection element
+func ConnectionTarget(connection string) string {
+	for _, attribute := range ConnectionTargetAttributes {
+		if target := ooxml.Attribute(connection, attribute); target != "" {
+			return target
+		}
+	}
+	return ""
+}
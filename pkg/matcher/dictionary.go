@@ -0,0 +1,189 @@
+package matcher
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match is the position of a term inside a text, as byte offsets of the original text
+type Match struct {
+	Start int
+	End   int
+	Term  string
+}
+
+// trieNode is a state of the Aho-Corasick automaton
+type trieNode struct {
+	next map[byte]int
+	fail int
+	// Indexes of the terms that end at this state, including the ones reached through fail links
+	outputs []int
+}
+
+// Dictionary finds many terms in a text in a single pass with the Aho-Corasick algorithm,
+// so term lists with thousands of names stay fast
+type Dictionary struct {
+	terms           []string
+	patternLengths  []int
+	nodes           []trieNode
+	caseInsensitive bool
+	wholeWord       bool
+}
+
+// NewDictionary builds the automaton for the terms, blank terms are ignored
+func NewDictionary(terms []string, caseInsensitive bool, wholeWord bool) *Dictionary {
+	d := &Dictionary{
+		nodes:           []trieNode{{next: map[byte]int{}}},
+		caseInsensitive: caseInsensitive,
+		wholeWord:       wholeWord,
+	}
+
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		pattern := term
+		if caseInsensitive {
+			pattern, _ = fold(term)
+		}
+		d.insert(pattern, len(d.terms))
+		d.terms = append(d.terms, term)
+		d.patternLengths = append(d.patternLengths, len(pattern))
+	}
+	d.buildFailLinks()
+
+	return d
+}
+
+// Len returns the number of terms in the dictionary
+func (d *Dictionary) Len() int {
+	return len(d.terms)
+}
+
+// insert adds a pattern to the trie
+func (d *Dictionary) insert(pattern string, termIndex int) {
+	state := 0
+	for i := 0; i < len(pattern); i++ {
+		next, exists := d.nodes[state].next[pattern[i]]
+		if !exists {
+			d.nodes = append(d.nodes, trieNode{next: map[byte]int{}})
+			next = len(d.nodes) - 1
+			d.nodes[state].next[pattern[i]] = next
+		}
+		state = next
+	}
+	d.nodes[state].outputs = append(d.nodes[state].outputs, termIndex)
+}
+
+// buildFailLinks links every state to the longest proper suffix that is also in the trie
+func (d *Dictionary) buildFailLinks() {
+	queue := []int{}
+	for _, child := range d.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for b, child := range d.nodes[state].next {
+			queue = append(queue, child)
+
+			fail := d.nodes[state].fail
+			for fail != 0 {
+				if _, exists := d.nodes[fail].next[b]; exists {
+					break
+				}
+				fail = d.nodes[fail].fail
+			}
+			if next, exists := d.nodes[fail].next[b]; exists && next != child {
+				d.nodes[child].fail = next
+			}
+			d.nodes[child].outputs = append(d.nodes[child].outputs, d.nodes[d.nodes[child].fail].outputs...)
+		}
+	}
+}
+
+// FindAll returns every occurrence of the terms in the text
+func (d *Dictionary) FindAll(text string) []Match {
+	var matches []Match
+	if len(d.terms) == 0 {
+		return matches
+	}
+
+	searched := text
+	var offsets []int
+	if d.caseInsensitive {
+		searched, offsets = fold(text)
+	}
+
+	state := 0
+	for i := 0; i < len(searched); i++ {
+		b := searched[i]
+		for state != 0 {
+			if _, exists := d.nodes[state].next[b]; exists {
+				break
+			}
+			state = d.nodes[state].fail
+		}
+		if next, exists := d.nodes[state].next[b]; exists {
+			state = next
+		}
+
+		for _, termIndex := range d.nodes[state].outputs {
+			start, end := i+1-d.patternLengths[termIndex], i+1
+			if d.wholeWord && !isWholeWord(searched, start, end) {
+				continue
+			}
+			if offsets != nil {
+				start, end = offsets[start], offsets[end]
+			}
+			matches = append(matches, Match{Start: start, End: end, Term: d.terms[termIndex]})
+		}
+	}
+
+	return matches
+}
+
+// Contains reports whether the text contains any of the terms
+func (d *Dictionary) Contains(text string) bool {
+	return len(d.FindAll(text)) > 0
+}
+
+// isWholeWord reports whether the match is not surrounded by letters, digits or underscores
+func isWholeWord(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fold lowercases the text rune by rune and maps each byte offset of the folded text,
+// plus the end offset, back to the original text since lowercasing can change byte lengths
+func fold(text string) (string, []int) {
+	var builder strings.Builder
+	offsets := make([]int, 0, len(text)+1)
+	for i, r := range text {
+		lower := unicode.ToLower(r)
+		for range utf8.RuneLen(lower) {
+			offsets = append(offsets, i)
+		}
+		builder.WriteRune(lower)
+	}
+	offsets = append(offsets, len(text))
+	return builder.String(), offsets
+}
@@ -0,0 +1,38 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestDictionary(t *testing.T) {
+	terms := []string{"Ann", "Annabel Lee", "he", "she", "hers", "  "}
+
+	t.Run("finds overlapping terms", func(t *testing.T) {
+		d := NewDictionary(terms, false, false)
+		assert.Equal(t, 5, d.Len())
+		assert.Equal(t, []Match{
+			{Start: 1, End: 4, Term: "she"},
+			{Start: 2, End: 4, Term: "he"},
+			{Start: 2, End: 6, Term: "hers"},
+		}, d.FindAll("ushers"))
+		assert.Equal(t, false, d.Contains("ANNABEL"))
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		d := NewDictionary(terms, true, false)
+		assert.Equal(t, []Match{
+			{Start: 6, End: 9, Term: "Ann"},
+			{Start: 6, End: 17, Term: "Annabel Lee"},
+		}, d.FindAll("Çà: ANNABEL LEE"))
+	})
+
+	t.Run("whole word", func(t *testing.T) {
+		d := NewDictionary(terms, true, true)
+		assert.Equal(t, false, d.Contains("Annabel"))
+		assert.Equal(t, false, d.Contains("ushers"))
+		assert.Equal(t, true, d.Contains("Paid to ann, 2024"))
+		assert.Equal(t, true, d.Contains("Annabel Lee"))
+	})
+}
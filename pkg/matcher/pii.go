@@ -0,0 +1,151 @@
+package matcher
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Constants for the categories of PII
+const (
+	EMAIL       string = "email"
+	PHONE       string = "phone"
+	SSN         string = "ssn"
+	CREDIT_CARD string = "creditCard"
+	IBAN        string = "iban"
+	IP_ADDRESS  string = "ipAddress"
+)
+
+// Detector finds one category of PII. Validate, when set, drops the matches failing a checksum
+type Detector struct {
+	Category   string
+	Pattern    *regexp.Regexp
+	Confidence float64
+	Validate   func(match string) bool
+}
+
+// Detectors are ordered from the most to the least reliable, a match overlapping the match of a
+// previous detector should be dropped, eg: a card number is not also a phone number
+var Detectors = []Detector{
+	{
+		Category:   CREDIT_CARD,
+		Pattern:    regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{1,7}\b`),
+		Confidence: 0.95,
+		Validate:   validLuhn,
+	},
+	{
+		Category:   IBAN,
+		Pattern:    regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+		Confidence: 0.95,
+		Validate:   validIban,
+	},
+	{
+		Category:   EMAIL,
+		Pattern:    regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+		Confidence: 0.9,
+	},
+	{
+		Category:   SSN,
+		Pattern:    regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		Confidence: 0.85,
+		Validate:   validSsn,
+	},
+	{
+		Category:   PHONE,
+		Pattern:    regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)|\b\d{3})[ .-]?\d{3}[ .-]\d{4}\b`),
+		Confidence: 0.7,
+	},
+	{
+		Category:   IP_ADDRESS,
+		Pattern:    regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`),
+		Confidence: 0.6,
+		Validate:   func(match string) bool { return net.ParseIP(match) != nil },
+	},
+}
+
+// validLuhn checks the digits of a card number with the Luhn algorithm
+func validLuhn(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// validIban checks an IBAN with the mod 97 algorithm, the letters count as 10 to 35
+func validIban(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if unicode.IsLetter(r) {
+			numeric.WriteString(big.NewInt(int64(r - 'A' + 10)).String())
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	number, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(number, big.NewInt(97)).Int64() == 1
+}
+
+// validSsn leaves out the area, group and serial numbers never issued
+func validSsn(match string) bool {
+	area, group, serial := match[0:3], match[4:6], match[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func onlyDigits(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// SelectDetectors returns the detectors of the categories, compared without case, or every
+// detector when no category is given
+func SelectDetectors(categories []string) ([]Detector, error) {
+	for _, category := range categories {
+		if !slices.ContainsFunc(Detectors, func(d Detector) bool { return strings.EqualFold(d.Category, category) }) {
+			return nil, fmt.Errorf("'%s' is not a detector", category)
+		}
+	}
+	var selected []Detector
+	for _, d := range Detectors {
+		if len(categories) == 0 || slices.ContainsFunc(categories, func(category string) bool {
+			return strings.EqualFold(d.Category, category)
+		}) {
+			selected = append(selected, d)
+		}
+	}
+	return selected, nil
+}
+
+// FindAll returns the start and end of the values the detector finds in a text
+func (d Detector) FindAll(text string) [][]int {
+	var locations [][]int
+	for _, location := range d.Pattern.FindAllStringIndex(text, -1) {
+		if d.Validate == nil || d.Validate(text[location[0]:location[1]]) {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,23 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestDetectors(t *testing.T) {
+	assert.Equal(t, true, validLuhn("4111111111111111"))
+	assert.Equal(t, false, validLuhn("4111111111111112"))
+	assert.Equal(t, true, validIban("GB82 WEST 1234 5698 7654 32"))
+	assert.Equal(t, false, validIban("GB82 WEST 1234 5698 7654 33"))
+	assert.Equal(t, false, validSsn("666-12-3456"))
+
+	detectors, err := SelectDetectors([]string{"SSN"})
+	if err != nil {
+		t.Fatalf("failed to select the detectors: %v", err)
+	}
+	assert.Equal(t, [][]int{{4, 15}}, detectors[0].FindAll("SSN 123-45-6789, 666-12-3456"))
+	_, err = SelectDetectors([]string{"passport"})
+	assert.Equal(t, "'passport' is not a detector", err.Error())
+}
@@ -0,0 +1,55 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+)
+
+// MergeLocations sorts the start and end offsets of the matches found in a text and merges the
+// overlapping ones, empty matches are dropped
+func MergeLocations(locations [][]int) [][]int {
+	sorted := make([][]int, 0, len(locations))
+	for _, location := range locations {
+		if location[0] < location[1] {
+			sorted = append(sorted, location)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+
+	var merged [][]int
+	for _, location := range sorted {
+		if last := len(merged) - 1; last >= 0 && location[0] < merged[last][1] {
+			merged[last][1] = max(merged[last][1], location[1])
+			continue
+		}
+		merged = append(merged, []int{location[0], location[1]})
+	}
+	return merged
+}
+
+// RedactRuns redacts a text split in runs, eg: the runs of a rich text. The locations are the
+// merged offsets of the matches in the joined text, so a value split across runs is found. A
+// match spanning several runs is replaced in the run where it starts
+func RedactRuns(runs []string, locations [][]int) []string {
+	redactedRuns := make([]string, len(runs))
+	offset := 0
+	for i, text := range runs {
+		var redacted strings.Builder
+		position := 0
+		for _, location := range locations {
+			start, end := max(location[0]-offset, 0), min(location[1]-offset, len(text))
+			if start >= end {
+				continue
+			}
+			redacted.WriteString(text[position:start])
+			if location[0] >= offset {
+				redacted.WriteString("**redacted**")
+			}
+			position = end
+		}
+		redacted.WriteString(text[position:])
+		redactedRuns[i] = redacted.String()
+		offset += len(text)
+	}
+	return redactedRuns
+}
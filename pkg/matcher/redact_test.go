@@ -0,0 +1,16 @@
+package matcher
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRedactRuns(t *testing.T) {
+	locations := MergeLocations([][]int{{12, 16}, {7, 11}, {9, 14}, {3, 3}})
+	assert.Equal(t, [][]int{{7, 16}}, locations)
+
+	// "Signed John Doe" split in runs, the match starts in the second run
+	assert.Equal(t, []string{"Signed ", "**redacted**", ""}, RedactRuns([]string{"Signed ", "John D", "oe"}, [][]int{{7, 15}}))
+	assert.Equal(t, []string{"a **redacted** b"}, RedactRuns([]string{"a xyz b"}, [][]int{{2, 5}}))
+}
@@ -0,0 +1,23 @@
+package ooxml
+
+import (
+	"html"
+	"regexp"
+)
+
+// Attribute returns the unescaped value of an attribute of an element, eg: ref of <location ref="A3:C9"/>
+func Attribute(element string, name string) string {
+	match := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `="([^"]*)"`).FindStringSubmatch(element)
+	if match == nil {
+		return ""
+	}
+	return html.UnescapeString(match[1])
+}
+
+// SetAttribute replaces the value of an attribute of an element
+func SetAttribute(element string, name string, value string) string {
+	pattern := regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `=)"[^"]*"`)
+	return pattern.ReplaceAllStringFunc(element, func(attribute string) string {
+		return pattern.FindStringSubmatch(attribute)[1] + `"` + html.EscapeString(value) + `"`
+	})
+}
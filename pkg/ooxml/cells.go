@@ -0,0 +1,28 @@
+package ooxml
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Cells and shared strings are read textually, neither of them nests
+var (
+	CellPattern             = regexp.MustCompile(`(?s)<c\b[^>]*?(?:/>|>.*?</c>)`)
+	CellValuePattern        = regexp.MustCompile(`(?s)<v>(.*?)</v>`)
+	SharedStringItemPattern = regexp.MustCompile(`(?s)<si>.*?</si>|<si\s*/>`)
+)
+
+// SharedStringIndex returns the shared string a cell uses, if any
+func SharedStringIndex(cell string) (int, bool) {
+	startTag := cell[:strings.Index(cell, ">")+1]
+	if Attribute(startTag, "t") != "s" {
+		return 0, false
+	}
+	match := CellValuePattern.FindStringSubmatch(cell)
+	if match == nil {
+		return 0, false
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(match[1]))
+	return index, err == nil
+}
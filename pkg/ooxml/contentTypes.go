@@ -0,0 +1,95 @@
+package ooxml
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+const contentTypesPath = "[Content_Types].xml"
+
+type ContentTypes struct {
+	XMLName   xml.Name          `xml:"http://schemas.openxmlformats.org/package/2006/content-types Types"`
+	Defaults  []ContentDefault  `xml:"Default"`
+	Overrides []ContentOverride `xml:"Override"`
+}
+
+type ContentDefault struct {
+	Extension   string `xml:"Extension,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+type ContentOverride struct {
+	PartName    string `xml:"PartName,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+// ContentTypes returns the content types of the package
+func (p *Package) ContentTypes() (*ContentTypes, error) {
+	contentTypes := &ContentTypes{}
+	data, exists := p.parts[contentTypesPath]
+	if !exists {
+		return contentTypes, nil
+	}
+	if err := xml.Unmarshal(data, contentTypes); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", contentTypesPath, err)
+	}
+	return contentTypes, nil
+}
+
+// removeContentTypeOverride drops the content type of a removed part
+func (p *Package) removeContentTypeOverride(name string) error {
+	contentTypes, err := p.ContentTypes()
+	if err != nil {
+		return err
+	}
+
+	kept := contentTypes.Overrides[:0]
+	for _, override := range contentTypes.Overrides {
+		if override.PartName != "/"+name {
+			kept = append(kept, override)
+		}
+	}
+	if len(kept) == len(contentTypes.Overrides) {
+		return nil
+	}
+	contentTypes.Overrides = kept
+
+	return p.setXMLPart(contentTypesPath, contentTypes)
+}
+
+// SetContentTypeOverride changes the content type of a part, eg: the workbook of a macro-enabled file
+func (p *Package) SetContentTypeOverride(name, contentType string) error {
+	contentTypes, err := p.ContentTypes()
+	if err != nil {
+		return err
+	}
+
+	for i, override := range contentTypes.Overrides {
+		if override.PartName == "/"+name {
+			contentTypes.Overrides[i].ContentType = contentType
+		}
+	}
+
+	return p.setXMLPart(contentTypesPath, contentTypes)
+}
+
+// RemoveContentTypeDefaults drops the default content types of the extensions mapped to a content type
+func (p *Package) RemoveContentTypeDefaults(contentType string) error {
+	contentTypes, err := p.ContentTypes()
+	if err != nil {
+		return err
+	}
+
+	kept := contentTypes.Defaults[:0]
+	for _, contentDefault := range contentTypes.Defaults {
+		if contentDefault.ContentType != contentType {
+			kept = append(kept, contentDefault)
+		}
+	}
+	if len(kept) == len(contentTypes.Defaults) {
+		return nil
+	}
+	contentTypes.Defaults = kept
+
+	return p.setXMLPart(contentTypesPath, contentTypes)
+}
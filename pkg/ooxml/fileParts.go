@@ -0,0 +1,80 @@
+package ooxml
+
+import (
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const workbookRelsPath = "xl/_rels/workbook.xml.rels"
+
+// ReadFilePart returns the raw contents of a part of an opened excelize file
+func ReadFilePart(f *excelize.File, name string) []byte {
+	content, _ := f.Pkg.Load(name)
+	if data, ok := content.([]byte); ok {
+		return data
+	}
+	return nil
+}
+
+// FlushSheet writes the edits of a loaded sheet to its part, so ReadFilePart returns them. excelize
+// serializes a loaded sheet when its rows are streamed, which unlike saving the workbook keeps the
+// sheet loaded so later edits still apply
+func FlushSheet(f *excelize.File, sheetName string) error {
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// SheetPath returns the part of a sheet, eg: xl/worksheets/sheet1.xml. Sheets added
+// since the file was opened have no part yet and resolve to an empty path
+func SheetPath(f *excelize.File, sheetName string) (string, error) {
+	// Listing the sheets loads the workbook
+	f.GetSheetList()
+	if f.WorkBook == nil {
+		return "", nil
+	}
+
+	var relationshipID string
+	for _, sheet := range f.WorkBook.Sheets.Sheet {
+		if sheet.Name == sheetName {
+			relationshipID = sheet.ID
+		}
+	}
+
+	relationships, err := ParseRelationships(workbookRelsPath, ReadFilePart(f, workbookRelsPath))
+	if err != nil {
+		return "", err
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == relationshipID {
+			return ResolveTarget(workbookRelsPath, relationship.Target), nil
+		}
+	}
+
+	return "", nil
+}
+
+// RelatedParts returns the parts a part of an opened excelize file points to through
+// relationships whose type ends with the suffix, eg: /threadedComment
+func RelatedParts(f *excelize.File, name string, typeSuffix string) ([]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	relsName := RelsPath(name)
+	relationships, err := ParseRelationships(relsName, ReadFilePart(f, relsName))
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	for _, relationship := range relationships.Relationships {
+		if relationship.TargetMode != "External" && strings.HasSuffix(relationship.Type, typeSuffix) {
+			parts = append(parts, ResolveTarget(relsName, relationship.Target))
+		}
+	}
+	return parts, nil
+}
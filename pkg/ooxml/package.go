@@ -0,0 +1,132 @@
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Package is an xlsx file opened as a zip of parts, for the edits excelize cannot make such
+// as removing whole parts. The parts keep their original order when written back
+type Package struct {
+	names []string
+	parts map[string][]byte
+}
+
+// ReadPackage opens the parts of an xlsx file
+func ReadPackage(fileContents []byte) (*Package, error) {
+	reader, err := zip.NewReader(bytes.NewReader(fileContents), int64(len(fileContents)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the package: %w", err)
+	}
+
+	p := &Package{parts: map[string][]byte{}}
+	for _, zipFile := range reader.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zipFile.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open part %s: %w", zipFile.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read part %s: %w", zipFile.Name, err)
+		}
+		p.SetPart(zipFile.Name, data)
+	}
+
+	return p, nil
+}
+
+// Bytes writes the package back to an xlsx file
+func (p *Package) Bytes() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	for _, name := range p.names {
+		partWriter, err := writer.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = partWriter.Write(p.parts[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Parts returns the names of the parts, eg: xl/workbook.xml
+func (p *Package) Parts() []string {
+	return slices.Clone(p.names)
+}
+
+// Part returns the contents of a part
+func (p *Package) Part(name string) ([]byte, bool) {
+	data, exists := p.parts[name]
+	return data, exists
+}
+
+// SetPart adds or replaces a part
+func (p *Package) SetPart(name string, data []byte) {
+	if _, exists := p.parts[name]; !exists {
+		p.names = append(p.names, name)
+	}
+	p.parts[name] = data
+}
+
+// RemovePart removes a part along with its relationships, its content type and every
+// relationship targeting it, so the package stays valid
+func (p *Package) RemovePart(name string) error {
+	if _, exists := p.parts[name]; !exists {
+		return nil
+	}
+	delete(p.parts, name)
+	delete(p.parts, RelsPath(name))
+	p.names = slices.DeleteFunc(p.names, func(partName string) bool {
+		_, exists := p.parts[partName]
+		return !exists
+	})
+
+	err := p.removeContentTypeOverride(name)
+	if err != nil {
+		return err
+	}
+
+	// Dropping the relationships of the remaining parts that target the removed part
+	for _, partName := range p.names {
+		if !strings.HasSuffix(partName, ".rels") {
+			continue
+		}
+		err = p.removeRelationships(partName, func(relationship Relationship) bool {
+			return relationship.TargetMode != "External" && ResolveTarget(partName, relationship.Target) == name
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemovePartsWithPrefix removes every part whose name starts with the prefix, eg: customXml/
+func (p *Package) RemovePartsWithPrefix(prefix string) (removed []string, err error) {
+	for _, name := range p.Parts() {
+		if !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		if err = p.RemovePart(name); err != nil {
+			return nil, err
+		}
+		removed = append(removed, name)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,90 @@
+package ooxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PartReference is a relationship of a source part pointing to another part
+type PartReference struct {
+	Source string
+	ID     string
+}
+
+// SourcePart returns the part a relationships part belongs to, the reverse of RelsPath
+func SourcePart(relsName string) string {
+	dir, file := path.Split(relsName)
+	// The source part of xl/_rels/workbook.xml.rels lives in xl/
+	sourceDir := path.Dir(path.Clean(dir))
+	if sourceDir == "." {
+		sourceDir = ""
+	}
+	return path.Join(sourceDir, strings.TrimSuffix(file, ".rels"))
+}
+
+// RelatedParts returns the parts a part points to through relationships whose type ends
+// with the suffix, eg: /chart
+func (p *Package) RelatedParts(name string, typeSuffix string) ([]string, error) {
+	relsName := RelsPath(name)
+	relationships, err := p.Relationships(relsName)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	for _, relationship := range relationships.Relationships {
+		if relationship.TargetMode != "External" && strings.HasSuffix(relationship.Type, typeSuffix) {
+			parts = append(parts, ResolveTarget(relsName, relationship.Target))
+		}
+	}
+	return parts, nil
+}
+
+// ReferencesTo returns the relationships of every part pointing to a part
+func (p *Package) ReferencesTo(name string) ([]PartReference, error) {
+	var references []PartReference
+	for _, relsName := range p.names {
+		if !strings.HasSuffix(relsName, ".rels") {
+			continue
+		}
+		relationships, err := p.Relationships(relsName)
+		if err != nil {
+			return nil, err
+		}
+		for _, relationship := range relationships.Relationships {
+			if relationship.TargetMode != "External" && ResolveTarget(relsName, relationship.Target) == name {
+				references = append(references, PartReference{Source: SourcePart(relsName), ID: relationship.ID})
+			}
+		}
+	}
+	return references, nil
+}
+
+// SheetNames maps the sheet parts of the workbook to their names, eg: xl/worksheets/sheet1.xml to Sheet1
+func (p *Package) SheetNames() (map[string]string, error) {
+	workbook := struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}{}
+	if err := xml.Unmarshal(p.parts["xl/workbook.xml"], &workbook); err != nil {
+		return nil, fmt.Errorf("failed to read xl/workbook.xml: %w", err)
+	}
+	relationships, err := p.Relationships(workbookRelsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetNames := map[string]string{}
+	for _, sheet := range workbook.Sheets {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID == sheet.ID {
+				sheetNames[ResolveTarget(workbookRelsPath, relationship.Target)] = sheet.Name
+			}
+		}
+	}
+	return sheetNames, nil
+}
@@ -0,0 +1,73 @@
+package ooxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestResolveTarget(t *testing.T) {
+	testCases := []struct {
+		relsName string
+		target   string
+		expected string
+	}{
+		{"_rels/.rels", "docProps/custom.xml", "docProps/custom.xml"},
+		{"xl/_rels/workbook.xml.rels", "../customXml/item1.xml", "customXml/item1.xml"},
+		{"xl/_rels/workbook.xml.rels", "worksheets/sheet1.xml", "xl/worksheets/sheet1.xml"},
+		{"xl/worksheets/_rels/sheet1.xml.rels", "/xl/comments1.xml", "xl/comments1.xml"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ResolveTarget(tc.relsName, tc.target))
+		})
+	}
+}
+
+func TestSourcePart(t *testing.T) {
+	assert.Equal(t, "xl/workbook.xml", SourcePart("xl/_rels/workbook.xml.rels"))
+	assert.Equal(t, "xl/drawings/drawing1.xml", SourcePart("xl/drawings/_rels/drawing1.xml.rels"))
+	assert.Equal(t, "", SourcePart("_rels/.rels"))
+}
+
+func TestRemovePart(t *testing.T) {
+	p := &Package{parts: map[string][]byte{}}
+	p.SetPart(contentTypesPath, []byte(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="workbook"/><Override PartName="/xl/comments1.xml" ContentType="comments"/></Types>`))
+	p.SetPart("xl/workbook.xml", []byte(`<workbook/>`))
+	p.SetPart("xl/comments1.xml", []byte(`<comments/>`))
+	p.SetPart("xl/_rels/comments1.xml.rels", []byte(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"/>`))
+	p.SetPart("xl/_rels/workbook.xml.rels", []byte(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="comments" Target="comments1.xml"/><Relationship Id="rId2" Type="hyperlink" Target="comments1.xml" TargetMode="External"/></Relationships>`))
+
+	err := p.RemovePart("xl/comments1.xml")
+	if err != nil {
+		t.Fatalf("failed to remove the part: %v", err)
+	}
+
+	assert.Equal(t, []string{contentTypesPath, "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}, p.Parts())
+	relationships, _ := p.Relationships("xl/_rels/workbook.xml.rels")
+	assert.Equal(t, 1, len(relationships.Relationships))
+	assert.Equal(t, "rId2", relationships.Relationships[0].ID)
+	contentTypes, _ := p.ContentTypes()
+	assert.Equal(t, 1, len(contentTypes.Overrides))
+	assert.Equal(t, 1, len(contentTypes.Defaults))
+}
+
+func TestFlushSheet(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellFormula("Sheet1", "A1", "1+2")
+	if err := FlushSheet(f, "Sheet1"); err != nil {
+		t.Fatalf("failed to flush the sheet: %v", err)
+	}
+	assert.Equal(t, true, strings.Contains(string(ReadFilePart(f, "xl/worksheets/sheet1.xml")), "<f>1+2</f>"))
+
+	// The sheet stays loaded, the edits after the flush still apply
+	f.SetCellValue("Sheet1", "B3", "Jane Doe")
+	value, _ := f.GetCellValue("Sheet1", "B3")
+	assert.Equal(t, "Jane Doe", value)
+	formula, _ := f.GetCellFormula("Sheet1", "A1")
+	assert.Equal(t, "1+2", formula)
+}
@@ -0,0 +1,107 @@
+package ooxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type Relationships struct {
+	XMLName       xml.Name       `xml:"http://schemas.openxmlformats.org/package/2006/relationships Relationships"`
+	Relationships []Relationship `xml:"Relationship"`
+}
+
+type Relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr,omitempty"`
+}
+
+// RelsPath returns the relationships part of a part, eg: xl/_rels/workbook.xml.rels
+func RelsPath(name string) string {
+	dir, file := path.Split(name)
+	return dir + "_rels/" + file + ".rels"
+}
+
+// ResolveTarget returns the part name a relationship target points to. Targets are relative
+// to the folder of the source part unless they start with a slash
+func ResolveTarget(relsName, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	// The source part of xl/_rels/workbook.xml.rels lives in xl/
+	sourceDir := path.Dir(path.Dir(relsName))
+	return strings.TrimPrefix(path.Join(sourceDir, target), "/")
+}
+
+// ParseRelationships reads the contents of a relationships part
+func ParseRelationships(relsName string, data []byte) (*Relationships, error) {
+	relationships := &Relationships{}
+	if len(data) == 0 {
+		return relationships, nil
+	}
+	if err := xml.Unmarshal(data, relationships); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relsName, err)
+	}
+	return relationships, nil
+}
+
+// Relationships returns the relationships of a relationships part
+func (p *Package) Relationships(relsName string) (*Relationships, error) {
+	return ParseRelationships(relsName, p.parts[relsName])
+}
+
+// removeRelationships drops the relationships matching the predicate from a relationships part
+func (p *Package) removeRelationships(relsName string, remove func(Relationship) bool) error {
+	relationships, err := p.Relationships(relsName)
+	if err != nil {
+		return err
+	}
+
+	kept := relationships.Relationships[:0]
+	for _, relationship := range relationships.Relationships {
+		if !remove(relationship) {
+			kept = append(kept, relationship)
+		}
+	}
+	if len(kept) == len(relationships.Relationships) {
+		return nil
+	}
+	relationships.Relationships = kept
+
+	return p.setXMLPart(relsName, relationships)
+}
+
+// setXMLPart marshals a value into a part with the xml header
+func (p *Package) setXMLPart(name string, v any) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	p.SetPart(name, append([]byte(xml.Header), data...))
+	return nil
+}
+
+// RemoveRelationship drops a relationship from a relationships part
+func (p *Package) RemoveRelationship(relsName, id string) error {
+	return p.removeRelationships(relsName, func(relationship Relationship) bool {
+		return relationship.ID == id
+	})
+}
+
+// SetRelationshipTarget points a relationship to a new target
+func (p *Package) SetRelationshipTarget(relsName, id, target string) error {
+	relationships, err := p.Relationships(relsName)
+	if err != nil {
+		return err
+	}
+	for i := range relationships.Relationships {
+		if relationships.Relationships[i].ID == id {
+			relationships.Relationships[i].Target = target
+			return p.setXMLPart(relsName, relationships)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned for a profile or a version that does not exist
+var ErrNotFound = errors.New("rule profile not found")
+
+// Store keeps the rule profiles in a local directory, one JSON file per profile holding every
+// version. Files are replaced atomically so a crash never leaves a partial profile behind
+type Store struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// MakeStore creates a store in the directory, which is created on the first write
+func MakeStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Create stores the first version of a new profile
+func (s *Store) Create(request types.RequestBodyRuleProfile) (*types.RuleProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile := newVersion(uuid.NewString(), 1, request)
+	if err := s.write(profile.ID, []types.RuleProfile{profile}); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Update stores a new version of a profile, the previous versions are kept
+func (s *Store) Update(id string, request types.RequestBodyRuleProfile) (*types.RuleProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	profile := newVersion(id, versions[len(versions)-1].Version+1, request)
+	if err = s.write(id, append(versions, profile)); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Get returns a version of a profile, the latest one when version is 0
+func (s *Store) Get(id string, version int) (*types.RuleProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return &versions[len(versions)-1], nil
+	}
+	for _, profile := range versions {
+		if profile.Version == version {
+			return &profile, nil
+		}
+	}
+	return nil, fmt.Errorf("version %d: %w", version, ErrNotFound)
+}
+
+// Versions returns every version of a profile, the oldest first
+func (s *Store) Versions(id string) ([]types.RuleProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(id)
+}
+
+// List returns the latest version of every profile, sorted by name
+func (s *Store) List() ([]types.RuleProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []types.RuleProfile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []types.RuleProfile{}
+	for _, entry := range entries {
+		id, isProfile := strings.CutSuffix(entry.Name(), ".json")
+		if !isProfile || entry.IsDir() {
+			continue
+		}
+		versions, err := s.read(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, versions[len(versions)-1])
+	}
+	sort.SliceStable(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// Delete removes a profile along with every version
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func newVersion(id string, version int, request types.RequestBodyRuleProfile) types.RuleProfile {
+	return types.RuleProfile{
+		ID:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		Version:     version,
+		Rules:       request.Rules,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// path returns the file of a profile. Identifiers are UUIDs, which keeps them out of other directories
+func (s *Store) path(id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.dir, strings.ToLower(id)+".json"), nil
+}
+
+func (s *Store) read(id string) ([]types.RuleProfile, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []types.RuleProfile
+	if err = json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to read rule profile %s: %w", id, err)
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
+}
+
+// write replaces the file of a profile through a temporary file
+func (s *Store) write(id string, versions []types.RuleProfile) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(s.dir, ".profile-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err = temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,66 @@
+package profile
+
+import (
+	"errors"
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func newRequest(name, sheetName string) types.RequestBodyRuleProfile {
+	return types.RequestBodyRuleProfile{
+		Name:  name,
+		Rules: []types.Rule{{PageCondition: types.PageCondition{SheetName: sheetName}}},
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := MakeStore(t.TempDir())
+
+	created, err := store.Create(newRequest("Payroll", "Sheet1"))
+	if err != nil {
+		t.Fatalf("failed to create the profile: %v", err)
+	}
+	assert.Equal(t, 1, created.Version)
+
+	updated, err := store.Update(created.ID, newRequest("Payroll", "Salaries"))
+	if err != nil {
+		t.Fatalf("failed to update the profile: %v", err)
+	}
+	assert.Equal(t, 2, updated.Version)
+
+	t.Run("get the latest or a given version", func(t *testing.T) {
+		latest, _ := store.Get(created.ID, 0)
+		assert.Equal(t, "Salaries", latest.Rules[0].PageCondition.SheetName)
+		first, _ := store.Get(created.ID, 1)
+		assert.Equal(t, "Sheet1", first.Rules[0].PageCondition.SheetName)
+
+		_, err := store.Get(created.ID, 3)
+		assert.Equal(t, true, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("list the latest versions by name", func(t *testing.T) {
+		store.Create(newRequest("HR", "Sheet1"))
+		profiles, err := store.List()
+		if err != nil {
+			t.Fatalf("failed to list the profiles: %v", err)
+		}
+		assert.Equal(t, 2, len(profiles))
+		assert.Equal(t, "HR", profiles[0].Name)
+		assert.Equal(t, 2, profiles[1].Version)
+	})
+
+	t.Run("delete every version", func(t *testing.T) {
+		assert.Equal(t, nil, store.Delete(created.ID))
+		_, err := store.Versions(created.ID)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Equal(t, ErrNotFound, store.Delete(created.ID))
+	})
+
+	t.Run("reject identifiers outside the store", func(t *testing.T) {
+		_, err := store.Get("../secrets", 0)
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
@@ -0,0 +1,229 @@
+package rulefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"xlsx-processor/pkg/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxIncludeDepth stops includes nested too deep to be anything but a mistake
+const maxIncludeDepth = 16
+
+// ErrInvalid is returned for the rule files that cannot be read as rules, as opposed to the files
+// that cannot be found or downloaded
+var ErrInvalid = errors.New("invalid rule file")
+
+// variablePattern matches ${name}, $${name} escapes it
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ReadFunc returns the content of a rule file, names are slash separated paths
+type ReadFunc func(name string) ([]byte, error)
+
+// Loader reads rule files written in YAML or JSON. A file is either a list of rules or a document:
+//
+//	# Comments are allowed
+//	variables:
+//	  client_name: Acme # default, the variables given to the loader take precedence
+//	include:
+//	  - shared/pii.yaml # relative to this file, its rules come first
+//	rules:
+//	  - pageCondition:
+//	      sheetName: ${client_name} Payroll
+//	    actions: []
+type Loader struct {
+	// Read returns the included files, includes are refused when it is nil
+	Read ReadFunc
+	// Variables are substituted in every string of the rules
+	Variables map[string]string
+}
+
+// document is a rule file, after its variables are substituted
+type document struct {
+	Variables map[string]string `json:"variables"`
+	Include   []string          `json:"include"`
+	Rules     []types.Rule      `json:"rules"`
+}
+
+// MakeLoader creates a loader reading the included files with read
+func MakeLoader(read ReadFunc, variables map[string]string) *Loader {
+	return &Loader{
+		Read:      read,
+		Variables: variables,
+	}
+}
+
+// Load reads a rule file and the files it includes
+func (l *Loader) Load(name string) ([]types.Rule, error) {
+	if l.Read == nil {
+		return nil, fmt.Errorf("cannot read rule file '%s'", name)
+	}
+	data, err := l.Read(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file '%s': %w", name, err)
+	}
+	return l.load(name, data, l.Variables, nil)
+}
+
+// Parse reads the content of a rule file, name locates the files it includes
+func (l *Loader) Parse(name string, data []byte) ([]types.Rule, error) {
+	return l.load(name, data, l.Variables, nil)
+}
+
+// load parses a file with the variables of the files including it, which take precedence over its own
+func (l *Loader) load(name string, data []byte, inherited map[string]string, stack []string) ([]types.Rule, error) {
+	if slices.Contains(stack, name) {
+		return nil, fmt.Errorf("%w '%s', it includes itself: %s", ErrInvalid, name, strings.Join(append(stack, name), " > "))
+	}
+	if len(stack) >= maxIncludeDepth {
+		return nil, fmt.Errorf("%w '%s', it is included more than %d levels deep", ErrInvalid, name, maxIncludeDepth)
+	}
+	stack = append(stack, name)
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrInvalid, name, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	content := root.Content[0]
+
+	// A list of rules is a document without variables nor includes
+	if content.Kind == yaml.SequenceNode {
+		content = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "rules"}, content,
+		}}
+	}
+	variables, err := fileVariables(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s', failed to read the variables: %w", ErrInvalid, name, err)
+	}
+	for key, value := range inherited {
+		variables[key] = value
+	}
+	if err = substitute(content, variables); err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrInvalid, name, err)
+	}
+
+	doc, err := decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrInvalid, name, err)
+	}
+
+	var rules []types.Rule
+	for _, include := range doc.Include {
+		if l.Read == nil {
+			return nil, fmt.Errorf("%w '%s', it cannot include '%s' without a location to read it from", ErrInvalid, name, include)
+		}
+		includeName := path.Join(path.Dir(name), include)
+		data, err := l.Read(includeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rule file '%s' included by '%s': %w", includeName, name, err)
+		}
+		includedRules, err := l.load(includeName, data, variables, stack)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, includedRules...)
+	}
+	return append(rules, doc.Rules...), nil
+}
+
+// fileVariables returns the default variables declared by a file, they are not substituted
+func fileVariables(content *yaml.Node) (map[string]string, error) {
+	variables := map[string]string{}
+	for i := 0; i+1 < len(content.Content); i += 2 {
+		if content.Content[i].Value == "variables" {
+			if err := content.Content[i+1].Decode(&variables); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return variables, nil
+}
+
+// substitute replaces the variables in the string values, so a value cannot change the structure
+// of the file. The keys and the declared variables are left as they are
+func substitute(node *yaml.Node, variables map[string]string) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var undefined []string
+		value := variablePattern.ReplaceAllStringFunc(node.Value, func(variable string) string {
+			if strings.HasPrefix(variable, "$$") {
+				return variable[1:]
+			}
+			name := variablePattern.FindStringSubmatch(variable)[1]
+			value, defined := variables[name]
+			if !defined {
+				undefined = append(undefined, name)
+			}
+			return value
+		})
+		if len(undefined) > 0 {
+			return fmt.Errorf("undefined variable '%s' at line %d", undefined[0], node.Line)
+		}
+		if value != node.Value {
+			// A substituted value stays a string, eg: a variable holding 123 in a value
+			node.Value, node.Tag, node.Style = value, "!!str", yaml.DoubleQuotedStyle
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "variables" {
+				continue
+			}
+			if err := substitute(node.Content[i+1], variables); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := substitute(child, variables); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decode converts the YAML to the rules through JSON, so the rules are read with the same field
+// names as the request bodies. Unknown fields are refused to catch typos
+func decode(content *yaml.Node) (*document, error) {
+	var value any
+	if err := content.Decode(&value); err != nil {
+		return nil, err
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+	var doc document
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// DirReader reads the rule files of a local directory, the files outside of it are refused
+func DirReader(dir string) ReadFunc {
+	return func(name string) ([]byte, error) {
+		cleaned := path.Clean(name)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return nil, fmt.Errorf("'%s' is outside of the rules directory", name)
+		}
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(cleaned)))
+	}
+}
@@ -0,0 +1,114 @@
+package rulefile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// mapReader reads the rule files from memory
+func mapReader(files map[string]string) ReadFunc {
+	return func(name string) ([]byte, error) {
+		content, found := files[name]
+		if !found {
+			return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+		}
+		return []byte(content), nil
+	}
+}
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"clients/acme.yaml": `
+# Rules of the Acme workbooks
+variables:
+  client_name: Acme
+  employee_id: "0000"
+include:
+  - ../shared/pii.yaml
+rules:
+  - pageCondition:
+      sheetName: ${client_name} Payroll # the sheet of the client
+    actions:
+      - operation: VALUE
+        value: ${employee_id}
+        actionType: REDACT
+      - operation: VALUE
+        value: $${literal}
+        actionType: REDACT
+`,
+		"shared/pii.yaml": `
+- pageCondition:
+    sheetName: ${client_name} Contacts
+  actions:
+    - {operation: COLUMN, value: C, actionType: REDACT}
+`,
+	}
+
+	t.Run("include and substitute", func(t *testing.T) {
+		loader := MakeLoader(mapReader(files), map[string]string{"employee_id": "123"})
+		rules, err := loader.Load("clients/acme.yaml")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Acme Contacts"},
+				Actions:       []types.Action{{Operation: "COLUMN", Value: "C", ActionType: "REDACT"}},
+			},
+			{
+				PageCondition: types.PageCondition{SheetName: "Acme Payroll"},
+				Actions: []types.Action{
+					{Operation: "VALUE", Value: "123", ActionType: "REDACT"},
+					{Operation: "VALUE", Value: "${literal}", ActionType: "REDACT"},
+				},
+			},
+		}, rules)
+	})
+
+	t.Run("read json", func(t *testing.T) {
+		rules, err := MakeLoader(nil, nil).Parse("rules.json", []byte(`[{"pageCondition": {"sheetName": "Sheet1"}, "actions": []}]`))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "Sheet1", rules[0].PageCondition.SheetName)
+	})
+
+	t.Run("report the problems of the file", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			message string
+		}{
+			{"undefined variable", "- pageCondition: {sheetName: '${missing}'}", "invalid rule file 'rules.yaml': undefined variable 'missing' at line 1"},
+			{"unknown field", "- pageCondition: {sheet: Sheet1}", `invalid rule file 'rules.yaml': json: unknown field "sheet"`},
+			{"include without a reader", "include: [shared.yaml]", "invalid rule file 'rules.yaml', it cannot include 'shared.yaml' without a location to read it from"},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := MakeLoader(nil, nil).Parse("rules.yaml", []byte(test.content))
+				assert.Equal(t, test.message, err.Error())
+				assert.Equal(t, true, errors.Is(err, ErrInvalid))
+			})
+		}
+	})
+
+	t.Run("refuse cycles", func(t *testing.T) {
+		loader := MakeLoader(mapReader(map[string]string{
+			"a.yaml": "include: [b.yaml]",
+			"b.yaml": "include: [a.yaml]",
+		}), nil)
+		_, err := loader.Load("a.yaml")
+		assert.Equal(t, "invalid rule file 'a.yaml', it includes itself: a.yaml > b.yaml > a.yaml", err.Error())
+	})
+
+	t.Run("keep the files in the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Equal(t, nil, os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte("include: [../secret.yaml]"), 0o644))
+
+		_, err := MakeLoader(DirReader(dir), nil).Load("rules.yaml")
+		assert.Equal(t, "failed to read rule file '../secret.yaml' included by 'rules.yaml': '../secret.yaml' is outside of the rules directory", err.Error())
+	})
+}
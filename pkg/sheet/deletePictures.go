@@ -0,0 +1,37 @@
+package sheet
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// DeletePictures deletes the pictures anchored in the cells matching the predicate and
+// returns how many were deleted
+func DeletePictures(f *excelize.File, sheetName string, isDeleted func(col, row int) bool) (int, error) {
+	pictureCells, err := f.GetPictureCells(sheetName)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, cellName := range pictureCells {
+		col, row, err := excelize.CellNameToCoordinates(cellName)
+		if err != nil {
+			return deleted, err
+		}
+		if !isDeleted(col, row) {
+			continue
+		}
+
+		pictures, err := f.GetPictures(sheetName, cellName)
+		if err != nil {
+			return deleted, err
+		}
+		err = f.DeletePicture(sheetName, cellName)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += len(pictures)
+	}
+
+	return deleted, nil
+}
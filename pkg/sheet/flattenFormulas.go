@@ -0,0 +1,112 @@
+package sheet
+
+import (
+	"html"
+	"strconv"
+
+	"xlsx-processor/pkg/ooxml"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// flattenedCell is a formula cell along with the value that replaces its formula
+type flattenedCell struct {
+	cellName string
+	value    any
+}
+
+// FlattenOptions selects the formulas FlattenFormulasWhere flattens and the ones it calculates again
+type FlattenOptions struct {
+	// IsFlattened selects the formulas to flatten, every formula is flattened when it is nil
+	IsFlattened func(formula string) bool
+	// IsStale selects the formulas whose cached value is out of date, eg: their inputs changed
+	// during the run, they are calculated again
+	IsStale func(cellName, formula string) bool
+}
+
+// Replacing the formulas of all the cells in the given sheet with their values, the formulas that
+// fail to calculate are kept
+func FlattenFormulas(f *excelize.File, sheetName string) (err error) {
+	_, err = FlattenFormulasWhere(f, sheetName, FlattenOptions{})
+	return err
+}
+
+// FlattenFormulasWhere replaces the formulas selected by the options with their values. A formula
+// without a cached value or with a stale one is calculated, the ones failing to calculate keep their
+// formula and are returned rather than lose their value
+func FlattenFormulasWhere(f *excelize.File, sheetName string, options FlattenOptions) (failed []string, err error) {
+	elements, err := formulaCellElements(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Computing every value before writing any of them, removing the formula of a
+	// shared formula's master cell also removes it from the cells sharing it
+	var flattenedCells []flattenedCell
+	for _, element := range elements {
+		cellName := ooxml.Attribute(startTag(element), "r")
+		formula, err := f.GetCellFormula(sheetName, cellName)
+		if err != nil {
+			return nil, err
+		}
+		if formula == "" || (options.IsFlattened != nil && !options.IsFlattened(formula)) {
+			continue
+		}
+
+		// The cached value is what Excel last showed, the value is calculated when there is
+		// none, eg: formulas written by excelize, or when its inputs changed since
+		match := ooxml.CellValuePattern.FindStringSubmatch(element)
+		var value any
+		if match != nil && (options.IsStale == nil || !options.IsStale(cellName, formula)) {
+			value, err = cachedValue(f, sheetName, cellName, ooxml.Attribute(startTag(element), "t"), html.UnescapeString(match[1]))
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			calculated, err := f.CalcCellValue(sheetName, cellName, excelize.Options{RawCellValue: true})
+			if err != nil {
+				failed = append(failed, cellName)
+				continue
+			}
+			value = typedValue(calculated)
+		}
+		flattenedCells = append(flattenedCells, flattenedCell{cellName: cellName, value: value})
+	}
+
+	// Setting a value also removes the formula, numbers and booleans keep their type so the
+	// number format of the cell still applies
+	for _, flattened := range flattenedCells {
+		if err = f.SetCellValue(sheetName, flattened.cellName, flattened.value); err != nil {
+			return nil, err
+		}
+	}
+
+	return failed, nil
+}
+
+// cachedValue reads the cached value of a formula cell according to the type of the cell
+func cachedValue(f *excelize.File, sheetName, cellName, cellType, value string) (any, error) {
+	switch cellType {
+	case "b":
+		return value == "1", nil
+	case "str", "e":
+		return value, nil
+	case "s":
+		return f.GetCellValue(sheetName, cellName, excelize.Options{RawCellValue: true})
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, nil
+	}
+	return value, nil
+}
+
+// typedValue keeps numbers and booleans of a calculated value as such
+func typedValue(value string) any {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	if value == "TRUE" || value == "FALSE" {
+		return value == "TRUE"
+	}
+	return value
+}
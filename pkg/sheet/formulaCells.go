@@ -0,0 +1,61 @@
+package sheet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"xlsx-processor/pkg/ooxml"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// formulaTagPattern matches the formula of a cell, including the cells sharing the formula
+// of another cell, eg: <f t="shared" si="0"/>
+var formulaTagPattern = regexp.MustCompile(`<f[\s/>]`)
+
+// FormulaCells returns the cells of a sheet holding a formula, whatever their cached value
+func FormulaCells(f *excelize.File, sheetName string) ([]string, error) {
+	elements, err := formulaCellElements(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	var formulaCells []string
+	for _, element := range elements {
+		formulaCells = append(formulaCells, ooxml.Attribute(startTag(element), "r"))
+	}
+	return formulaCells, nil
+}
+
+// formulaCellElements returns the elements of the cells of a sheet holding a formula
+func formulaCellElements(f *excelize.File, sheetName string) ([]string, error) {
+	// The part is read as it is, the edits of the sheet are written to it first
+	if err := ooxml.FlushSheet(f, sheetName); err != nil {
+		return nil, err
+	}
+	sheetPath, err := ooxml.SheetPath(f, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	if sheetPath == "" {
+		// excelize names the parts of the sheets it adds after their id
+		for sheetID, name := range f.GetSheetMap() {
+			if name == sheetName {
+				sheetPath = fmt.Sprintf("xl/worksheets/sheet%d.xml", sheetID)
+			}
+		}
+	}
+
+	var elements []string
+	for _, element := range ooxml.CellPattern.FindAllString(string(ooxml.ReadFilePart(f, sheetPath)), -1) {
+		if ooxml.Attribute(startTag(element), "r") != "" && formulaTagPattern.MatchString(element) {
+			elements = append(elements, element)
+		}
+	}
+	return elements, nil
+}
+
+// startTag returns the start tag of a cell element
+func startTag(element string) string {
+	return element[:strings.Index(element, ">")+1]
+}
@@ -0,0 +1,73 @@
+package sheet
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheet visibility states, as stored in the workbook
+const (
+	VISIBLE     string = "visible"
+	HIDDEN      string = "hidden"
+	VERY_HIDDEN string = "veryHidden"
+)
+
+// GetVisibility returns the visibility state of a sheet: visible, hidden or veryHidden.
+// Very hidden sheets can only be revealed through VBA, excelize reports them as hidden
+func GetVisibility(f *excelize.File, sheetName string) (string, error) {
+	// Listing the sheets loads the workbook
+	f.GetSheetList()
+	if f.WorkBook == nil {
+		return "", fmt.Errorf("failed to read the workbook")
+	}
+
+	for _, sheet := range f.WorkBook.Sheets.Sheet {
+		if sheet.Name != sheetName {
+			continue
+		}
+		if sheet.State == "" {
+			return VISIBLE, nil
+		}
+		return sheet.State, nil
+	}
+
+	return "", fmt.Errorf("sheet %s does not exist", sheetName)
+}
+
+// GetHiddenRowsAndColumns returns the hidden rows and columns within the data of a sheet
+func GetHiddenRowsAndColumns(f *excelize.File, sheetName string) (hiddenRows []int, hiddenCols []string, err error) {
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rowNum := 1; rowNum <= len(rows); rowNum++ {
+		visible, err := f.GetRowVisible(sheetName, rowNum)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !visible {
+			hiddenRows = append(hiddenRows, rowNum)
+		}
+	}
+
+	cols, err := f.GetCols(sheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for colNum := 1; colNum <= len(cols); colNum++ {
+		colName, err := excelize.ColumnNumberToName(colNum)
+		if err != nil {
+			return nil, nil, err
+		}
+		visible, err := f.GetColVisible(sheetName, colName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !visible {
+			hiddenCols = append(hiddenCols, colName)
+		}
+	}
+
+	return hiddenRows, hiddenCols, nil
+}
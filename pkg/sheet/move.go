@@ -0,0 +1,61 @@
+package sheet
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Move places a sheet at a position of the workbook, starting from 0. excelize cannot reorder
+// the sheets, so the workbook is edited directly: the sheet scoped defined names follow their
+// sheet and the active sheet stays the same
+func Move(f *excelize.File, sheetName string, position int) error {
+	// Listing the sheets loads the workbook
+	sheetList := f.GetSheetList()
+	if f.WorkBook == nil {
+		return fmt.Errorf("failed to read the workbook")
+	}
+	from := slices.Index(sheetList, sheetName)
+	if from == -1 {
+		return fmt.Errorf("sheet %s does not exist", sheetName)
+	}
+	if position < 0 || position >= len(sheetList) {
+		return fmt.Errorf("'%d' is not a valid position, the workbook has %d sheets", position+1, len(sheetList))
+	}
+	if from == position {
+		return nil
+	}
+	activeSheet := f.GetSheetName(f.GetActiveSheetIndex())
+
+	// The previous index of the sheet at each position
+	order := make([]int, 0, len(sheetList))
+	for index := range sheetList {
+		if index != from {
+			order = append(order, index)
+		}
+	}
+	order = slices.Insert(order, position, from)
+
+	sheets := f.WorkBook.Sheets.Sheet
+	reordered := slices.Clone(sheets)
+	newIndexes := make([]int, len(sheets))
+	for index, previous := range order {
+		reordered[index] = sheets[previous]
+		newIndexes[previous] = index
+	}
+	f.WorkBook.Sheets.Sheet = reordered
+
+	if f.WorkBook.DefinedNames != nil {
+		for i, definedName := range f.WorkBook.DefinedNames.DefinedName {
+			if definedName.LocalSheetID == nil || *definedName.LocalSheetID >= len(newIndexes) {
+				continue
+			}
+			localSheetID := newIndexes[*definedName.LocalSheetID]
+			f.WorkBook.DefinedNames.DefinedName[i].LocalSheetID = &localSheetID
+		}
+	}
+
+	f.SetActiveSheet(slices.Index(f.GetSheetList(), activeSheet))
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/comment"
+	"xlsx-processor/pkg/link"
 	"xlsx-processor/pkg/types"
 
 	"github.com/xuri/excelize/v2"
@@ -79,15 +81,35 @@ func collectSheetMinimals(f *excelize.File, sheetNames []string) ([]types.SheetM
 			return nil, fmt.Errorf("failed to process sheet tab color: %w", err)
 		}
 
+		visibility, err := GetVisibility(f, sheet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sheet visibility: %w", err)
+		}
+
 		sheetMinimals[i] = types.SheetMinimal{
 			SheetName:     sheet,
 			SheetTabColor: hexCode,
+			Visibility:    visibility,
 		}
 	}
 
 	return sheetMinimals, nil
 }
 
+// getMerges lists the merged areas of a sheet as ranges
+func getMerges(f *excelize.File, sheetName string) ([]string, error) {
+	mergeCells, err := f.GetMergeCells(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var merges []string
+	for _, mergeCell := range mergeCells {
+		merges = append(merges, mergeCell.GetStartAxis()+":"+mergeCell.GetEndAxis())
+	}
+	return merges, nil
+}
+
 // Converting an xlsx sheet to a csv object
 func ParseSheetToCsv(f *excelize.File, sheetName *string) (*types.Sheet, error) {
 	sheetNames := f.GetSheetList()
@@ -142,9 +164,28 @@ func ParseSheetToCsv(f *excelize.File, sheetName *string) (*types.Sheet, error)
 		styledRows[rowIndex] = styledRow
 	}
 
+	merges, err := getMerges(f, currentSheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merged cells: %w", err)
+	}
+
+	hiddenRows, hiddenCols, err := GetHiddenRowsAndColumns(f, currentSheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hidden rows and columns: %w", err)
+	}
+
+	comments, err := comment.GetComments(f, currentSheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
 	sheet := &types.Sheet{
-		SheetName: currentSheet,
-		Cells:     styledRows,
+		SheetName:     currentSheet,
+		Cells:         styledRows,
+		Merges:        merges,
+		HiddenRows:    hiddenRows,
+		HiddenColumns: hiddenCols,
+		Comments:      comments,
 	}
 
 	return sheet, nil
@@ -198,9 +239,28 @@ func ParseSheetsToCsvAndAttributes(f *excelize.File) ([]*types.Sheet, *types.Att
 			styledRows[rowIndex] = styledRow
 		}
 
+		merges, err := getMerges(f, sheetName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get merged cells: %w", err)
+		}
+
+		hiddenRows, hiddenCols, err := GetHiddenRowsAndColumns(f, sheetName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get hidden rows and columns: %w", err)
+		}
+
+		comments, err := comment.GetComments(f, sheetName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+
 		sheet := &types.Sheet{
-			SheetName: sheetName,
-			Cells:     styledRows,
+			SheetName:     sheetName,
+			Cells:         styledRows,
+			Merges:        merges,
+			HiddenRows:    hiddenRows,
+			HiddenColumns: hiddenCols,
+			Comments:      comments,
 		}
 
 		sheets[i] = sheet
@@ -211,10 +271,16 @@ func ParseSheetsToCsvAndAttributes(f *excelize.File) ([]*types.Sheet, *types.Att
 		return nil, nil, fmt.Errorf("failed to collect sheet minimals: %w", err)
 	}
 
+	links, err := link.GetFileLinks(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get links: %w", err)
+	}
+
 	attributes := &types.Attributes{
 		SheetMinimals: sheetMinimals,
 		TextColors:    textColors,
 		BgColors:      bgColors,
+		Links:         links,
 	}
 
 	return sheets, attributes, nil
@@ -3,6 +3,8 @@ package sheet
 import (
 	"testing"
 
+	"xlsx-processor/pkg/types"
+
 	"github.com/go-playground/assert/v2"
 	"github.com/xuri/excelize/v2"
 )
@@ -100,6 +102,25 @@ func TestParseSheetsToCsvAndAttributes(t *testing.T) {
 	})
 }
 
+func TestParseLinks(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	file.SetCellValue("Sheet1", "A1", "Intranet")
+	file.SetCellHyperLink("Sheet1", "A1", "https://intranet.example.com/hr", "External")
+	file.SetCellValue("Sheet1", "A2", "Totals")
+	file.SetCellHyperLink("Sheet1", "A2", "Sheet1!C10", "Location")
+
+	_, attributes, err := ParseSheetsToCsvAndAttributes(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	assert.Equal(t, []types.Link{
+		{Type: "hyperlink", SheetName: "Sheet1", Cell: "A1", Target: "https://intranet.example.com/hr"},
+		{Type: "hyperlink", SheetName: "Sheet1", Cell: "A2", Target: "Sheet1!C10"},
+	}, attributes.Links)
+}
+
 func EqualNotSorted(t *testing.T, expected, actual []string) {
 	assert.Equal(t, len(expected), len(actual))
 	for _, exp := range expected {
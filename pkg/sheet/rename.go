@@ -0,0 +1,103 @@
+package sheet
+
+import (
+	"html"
+	"regexp"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/link"
+	"xlsx-processor/pkg/ooxml"
+)
+
+const workbookPath = "xl/workbook.xml"
+
+// Sheet names are edited textually in every part referring to them, so the renames apply at once
+// and a mapping may swap two names
+var (
+	sheetElementPattern    = regexp.MustCompile(`<sheet\b[^>]*>`)
+	definedNamePattern     = regexp.MustCompile(`(?s)(<definedName\b[^>]*>)(.*?)(</definedName>)`)
+	formulaElementPattern  = regexp.MustCompile(`(?s)(<(?:\w+:)?(?:f|formula|formula1|formula2)\b[^>/]*>)(.*?)(</(?:\w+:)?(?:f|formula|formula1|formula2)>)`)
+	titlesOfPartsPattern   = regexp.MustCompile(`(?s)<TitlesOfParts>.*?</TitlesOfParts>`)
+	titleOfPartPattern     = regexp.MustCompile(`(<vt:lpstr>)(.*?)(</vt:lpstr>)`)
+	chartPartPattern       = regexp.MustCompile(`^xl/charts/chart\d+\.xml$`)
+	pivotCachePartPattern  = regexp.MustCompile(`^xl/pivotCache/pivotCacheDefinition\d+\.xml$`)
+	worksheetSourcePattern = regexp.MustCompile(`<worksheetSource\b[^>]*>`)
+)
+
+// Rename renames the sheets of a package along with the formulas, defined names, data
+// validations, conditional formats, hyperlinks, chart series and pivot sources referring to them.
+// The other attributes of the defined names, such as hidden, are left as they are
+func Rename(p *ooxml.Package, renames map[string]string) error {
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return err
+	}
+	renameFormulas := func(content string) string {
+		content = replaceElementText(formulaElementPattern, content, renames)
+		return replaceElementText(definedNamePattern, content, renames)
+	}
+
+	for _, name := range p.Parts() {
+		data, _ := p.Part(name)
+		content := string(data)
+		_, isSheet := sheetNames[name]
+		switch {
+		case name == workbookPath:
+			content = sheetElementPattern.ReplaceAllStringFunc(content, func(element string) string {
+				return renameAttribute(element, "name", renames)
+			})
+			content = renameFormulas(content)
+		case isSheet:
+			content = renameFormulas(content)
+			content = link.HyperlinkPattern.ReplaceAllStringFunc(content, func(hyperlink string) string {
+				location := ooxml.Attribute(hyperlink, "location")
+				if location == "" {
+					return hyperlink
+				}
+				return ooxml.SetAttribute(hyperlink, "location", formula.RenameSheets(location, renames))
+			})
+		case chartPartPattern.MatchString(name):
+			content = renameFormulas(content)
+		case pivotCachePartPattern.MatchString(name):
+			content = worksheetSourcePattern.ReplaceAllStringFunc(content, func(element string) string {
+				return renameAttribute(element, "sheet", renames)
+			})
+		case name == "docProps/app.xml":
+			// The names of the sheets are listed among the titles of the parts
+			content = titlesOfPartsPattern.ReplaceAllStringFunc(content, func(titles string) string {
+				return titleOfPartPattern.ReplaceAllStringFunc(titles, func(title string) string {
+					matches := titleOfPartPattern.FindStringSubmatch(title)
+					if newName, found := renames[html.UnescapeString(matches[2])]; found {
+						return matches[1] + html.EscapeString(newName) + matches[3]
+					}
+					return title
+				})
+			})
+		default:
+			continue
+		}
+		p.SetPart(name, []byte(content))
+	}
+	return nil
+}
+
+// replaceElementText renames the sheets in the formula held by an element matched by the pattern
+func replaceElementText(pattern *regexp.Regexp, content string, renames map[string]string) string {
+	return pattern.ReplaceAllStringFunc(content, func(element string) string {
+		matches := pattern.FindStringSubmatch(element)
+		text := html.UnescapeString(matches[2])
+		renamed := formula.RenameSheets(text, renames)
+		if renamed == text {
+			return element
+		}
+		return matches[1] + html.EscapeString(renamed) + matches[3]
+	})
+}
+
+// renameAttribute renames the sheet named by an attribute of an element
+func renameAttribute(element, name string, renames map[string]string) string {
+	if newName, found := renames[ooxml.Attribute(element, name)]; found {
+		return ooxml.SetAttribute(element, name, newName)
+	}
+	return element
+}
@@ -0,0 +1,73 @@
+package sheet
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// rewrittenCell is a formula cell along with its rewritten formula
+type rewrittenCell struct {
+	cellName string
+	formula  string
+}
+
+// RewriteFormulas replaces the formulas of a sheet with their rewritten form, the formulas the
+// rewrite leaves unchanged are kept as they are
+func RewriteFormulas(f *excelize.File, sheetName string, rewrite func(formula string) string) error {
+	cols, err := f.GetCols(sheetName)
+	if err != nil {
+		return err
+	}
+
+	// Reading every formula before writing any of them, the cells sharing the formula of
+	// a rewritten master cell get their own formula
+	var rewrittenCells []rewrittenCell
+	for colIndex, col := range cols {
+		for rowIndex := range col {
+			cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+			if err != nil {
+				return err
+			}
+			formula, err := f.GetCellFormula(sheetName, cellName)
+			if err != nil {
+				return err
+			}
+			if formula == "" {
+				continue
+			}
+			if rewritten := rewrite(formula); rewritten != formula {
+				rewrittenCells = append(rewrittenCells, rewrittenCell{cellName: cellName, formula: rewritten})
+			}
+		}
+	}
+
+	for _, rewritten := range rewrittenCells {
+		if err = f.SetCellFormula(sheetName, rewritten.cellName, rewritten.formula); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RewriteDefinedNames replaces what the defined names of the workbook refer to, a defined
+// name is deleted when the rewrite does not keep it
+func RewriteDefinedNames(f *excelize.File, rewrite func(refersTo string) (rewritten string, keep bool)) error {
+	for _, definedName := range f.GetDefinedName() {
+		rewritten, keep := rewrite(definedName.RefersTo)
+		if keep && rewritten == definedName.RefersTo {
+			continue
+		}
+
+		err := f.DeleteDefinedName(&excelize.DefinedName{Name: definedName.Name, Scope: definedName.Scope})
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+		definedName.RefersTo = rewritten
+		if err = f.SetDefinedName(&definedName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
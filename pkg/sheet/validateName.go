@@ -0,0 +1,21 @@
+package sheet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// invalidNamePattern matches the characters Excel does not allow in a sheet name
+var invalidNamePattern = regexp.MustCompile(`[:\\/?*\[\]]`)
+
+// ValidateName checks a new sheet name against the rules of Excel
+func ValidateName(name string) error {
+	length := utf8.RuneCountInString(name)
+	if length == 0 || length > 31 || invalidNamePattern.MatchString(name) ||
+		strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") || strings.EqualFold(name, "History") {
+		return fmt.Errorf("'%s' is not a valid sheet name", name)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package types
+
+type RedactedCell struct {
+	SheetName   string `json:"sheetName"`
+	Cell        string `json:"cell"`
+	RuleIndex   *int   `json:"ruleIndex,omitempty"`
+	ActionIndex *int   `json:"actionIndex,omitempty"`
+	// Excluded is set when the row or column of the cell was removed after the redaction
+	Excluded bool `json:"excluded,omitempty"`
+	// Hash is the salted hash of the original value, so the manifest alone can verify the output
+	Hash string `json:"hash,omitempty"`
+	// The original value is kept for the later passes but never serialized
+	Value string `json:"-"`
+}
+
+type SkippedRule struct {
+	RuleIndex int    `json:"ruleIndex"`
+	Reason    string `json:"reason"`
+}
+
+// Exclusion is a row, a column or, when both are empty, a whole sheet removed from the workbook.
+// Rows and columns are numbered as in the original workbook
+type Exclusion struct {
+	SheetName string `json:"sheetName"`
+	Row       int    `json:"row,omitempty"`
+	Column    int    `json:"column,omitempty"`
+}
+
+type Manifest struct {
+	// Profile is the rule profile version applied, if any
+	Profile *AppliedProfile `json:"profile,omitempty"`
+	// Salt is the hex encoded random salt of the hashes of the original values
+	Salt          string         `json:"salt,omitempty"`
+	RedactedCells []RedactedCell `json:"redactedCells"`
+	// Formulas that depended on redacted cells and were cleared along with their cached results
+	ScrubbedFormulas []RedactedCell `json:"scrubbedFormulas"`
+	// ComputedCells are the cells the compute actions changed, they are not redactions
+	ComputedCells []RedactedCell `json:"computedCells,omitempty"`
+	// UnflattenedFormulas are the formulas the flatten mode kept because they failed to calculate
+	UnflattenedFormulas []RedactedCell `json:"unflattenedFormulas,omitempty"`
+	// SkippedRules are the rules that did not apply, along with the reason
+	SkippedRules []SkippedRule `json:"skippedRules,omitempty"`
+	// Exclusions are kept for the later passes but never serialized
+	Exclusions []Exclusion `json:"-"`
+	// RemovedPictures counts the pictures removed along with excluded rows and columns,
+	// it is reported by the sanitize step
+	RemovedPictures int `json:"-"`
+}
@@ -0,0 +1,26 @@
+package types
+
+// RuleProfile is a named rule set stored on the server. Updating a profile adds a version, the
+// previous versions are kept so past transforms can be traced
+type RuleProfile struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Version     int    `json:"version"`
+	Rules       []Rule `json:"rules"`
+	// CreatedAt is the RFC 3339 time the version was created
+	CreatedAt string `json:"createdAt"`
+}
+
+type RequestBodyRuleProfile struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description,omitempty"`
+	Rules       []Rule `json:"rules" validate:"required"`
+}
+
+// AppliedProfile is the rule profile version a transform applied
+type AppliedProfile struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
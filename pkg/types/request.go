@@ -14,6 +14,37 @@ type PaginationResult struct {
 type RequestBodyTransform struct {
 	Input       Input    `json:"input" validate:"required"`
 	Output      Output   `json:"output" validate:"required"`
-	Rules       []Rule   `json:"rules" validate:"required"`
+	Rules       []Rule   `json:"rules" validate:"required_without_all=RuleProfileId RuleFile"`
 	Webhook     *Webhook `json:"webhook,omitempty"`
+	Sanitize    *Sanitize `json:"sanitize,omitempty"`
+	// RuleProfileId applies the rules of a stored profile before the inline rules
+	RuleProfileId string `json:"ruleProfileId,omitempty"`
+	// RuleProfileVersion selects a version of the profile, the latest one by default
+	RuleProfileVersion int `json:"ruleProfileVersion,omitempty"`
+	// RuleFile applies the rules of a YAML or JSON rule file after the profile rules
+	RuleFile *RuleFile `json:"ruleFile,omitempty"`
+}
+
+type RequestBodyVerify struct {
+	Input Input `json:"input" validate:"required"`
+	// Values are the sensitive values searched in every part of the workbook
+	Values []string `json:"values,omitempty"`
+	// CaseInsensitive matches the values without case
+	CaseInsensitive bool `json:"caseInsensitive,omitempty"`
+	// Manifest is the redaction manifest of the transform, its cells must still be redacted
+	Manifest *Manifest `json:"manifest,omitempty"`
+}
+
+type RequestBodyValidateRules struct {
+	Rules []Rule `json:"rules" validate:"required"`
+	// Input is the workbook the rules are meant for, the sheets are checked when it is given
+	Input *Input `json:"input,omitempty"`
+}
+
+// RulesValidation lists the problems of the rules. Errors would stop the transform, warnings
+// point to the rules and actions it would skip
+type RulesValidation struct {
+	Valid    bool             `json:"valid"`
+	Errors   []TransformError `json:"errors"`
+	Warnings []TransformError `json:"warnings"`
 }
@@ -0,0 +1,10 @@
+package types
+
+// RuleFile is a YAML or JSON rule file, stored or given inline. Its includes are read next to
+// the stored file, an inline file cannot include others
+type RuleFile struct {
+	Source  *Input `json:"source,omitempty"`
+	Content string `json:"content,omitempty"`
+	// Variables are substituted in the rules, eg: ${client_name}, they override the file defaults
+	Variables map[string]string `json:"variables,omitempty"`
+}
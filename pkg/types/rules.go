@@ -11,12 +11,68 @@ type Action struct {
 	Operation  string `json:"operation"`
 	Value      string `json:"value"`
 	ActionType string `json:"actionType"`
+	// Dictionary configures the DICTIONARY operation
+	Dictionary *DictionaryOptions `json:"dictionary,omitempty"`
+	// Label configures the LABEL_ADJACENT operation
+	Label *LabelOptions `json:"label,omitempty"`
+	// Comment configures the COMMENT operation
+	Comment *CommentOptions `json:"comment,omitempty"`
+	// Compute configures the COMPUTE action type, the value is the expression
+	Compute *ComputeOptions `json:"compute,omitempty"`
+}
+
+type ComputeOptions struct {
+	// Target is the range, the column or the row of the computed cells, according to the operation
+	Target string `json:"target"`
+	// HeaderRow holds the column names of row["Name"] and col("Name"), 1 by default, -1 when the
+	// sheet has no header row. The header row is never computed
+	HeaderRow int `json:"headerRow,omitempty"`
+}
+
+type CommentOptions struct {
+	// Regex matches the text to redact inside the comments as a regular expression instead of literally
+	Regex bool `json:"regex,omitempty"`
+}
+
+type LabelOptions struct {
+	// Regex matches the labels with the value as a regular expression instead of literally
+	Regex bool `json:"regex,omitempty"`
+	// CaseInsensitive matches the labels without case, they match the case by default
+	CaseInsensitive bool `json:"caseInsensitive,omitempty"`
+	// Direction of the redacted cells from the label: "right" (default), "below", "left" or "above"
+	Direction string `json:"direction,omitempty"`
+	// Offset is the distance of the first redacted cell from the label, 1 by default
+	Offset int `json:"offset,omitempty"`
+	// Count is the number of cells to redact, 1 by default
+	Count int `json:"count,omitempty"`
+}
+
+type DictionaryOptions struct {
+	// Terms listed inline in addition to the ones in the value
+	Terms []string `json:"terms,omitempty"`
+	// Source is a term list kept in a storage backend, one term per line (.txt, .csv) or
+	// the first column of the first sheet below its header (.xlsx)
+	Source          *Input `json:"source,omitempty"`
+	CaseInsensitive bool   `json:"caseInsensitive,omitempty"`
+	WholeWord       bool   `json:"wholeWord,omitempty"`
 }
 
 type PageCondition struct {
 	SheetName           string `json:"sheetName"`
 	IncludeFormulas     bool   `json:"includeFormulas"`
 	NonEmptyValueRedact bool   `json:"nonEmptyValueRedact"`
+	// Mode is either "blocklist" (default) or "allowlist". In allowlist mode the
+	// cells targeted by the actions are kept and everything else is redacted or excluded
+	Mode string `json:"mode,omitempty"`
+	// RedactAcrossSheets redacts every other occurrence of the values this rule
+	// redacted, on every sheet of the workbook
+	RedactAcrossSheets bool `json:"redactAcrossSheets,omitempty"`
+	// FormulaMode is "keep", "clear" or "flatten", it takes precedence over IncludeFormulas
+	FormulaMode string `json:"formulaMode,omitempty"`
+	// MergePolicy is "expand" (default) or "unmerge". Expand redacts the value of a merged
+	// area and excludes every row or column the area spans, unmerge splits the area and
+	// fills every cell with its value first
+	MergePolicy string `json:"mergePolicy,omitempty"`
 }
 
 /*
@@ -45,6 +101,33 @@ value: "C"
 exclude row -
 operation: "row"
 value: "4"
+
+redact by dictionary, every cell containing a term -
+operation: "dictionary"
+value: "Roster!B" (column B of the Roster sheet) or "Jane Doe\nJohn Smith" (inline terms)
+dictionary: { caseInsensitive: true, wholeWord: true }
+
+redact the cell to the right of a label -
+operation: "labelAdjacent"
+value: "SSN:"
+label: { direction: "right", offset: 1, count: 1 }
+
+allowlist (pageCondition.mode: "allowlist") -
+keep only columns A and C, redact every other populated cell
+actions: [
+	{ actionType: "redact", operation: "column", value: "A" },
+	{ actionType: "redact", operation: "column", value: "C" }
+]
+
+redact text inside comments -
+operation: "comment"
+value: "SSN"
+actionType: "redact"
+
+remove the comments of redacted cells -
+operation: "comment"
+value: "redacted"
+actionType: "exclude"
 */
 
 /*
@@ -68,4 +151,27 @@ configs: [
 type Rule struct {
 	PageCondition PageCondition `json:"pageCondition"`
 	Actions       []Action      `json:"actions"`
+	// Priority orders the rules, the highest first. Rules of the same priority keep their order
+	Priority int `json:"priority,omitempty"`
+	// StopOnMatch skips the later rules of the same sheet once this rule applies
+	StopOnMatch bool `json:"stopOnMatch,omitempty"`
+	// Conditions must all hold for the rule to apply, they are checked on the workbook as received
+	Conditions []RuleCondition `json:"conditions,omitempty"`
+}
+
+type RuleCondition struct {
+	// Type is "header" (a header of the sheet), "cell" (the value of a cell) or "fileName"
+	Type string `json:"type"`
+	// SheetName is the sheet of the header and cell conditions, the sheet of the rule by default
+	SheetName string `json:"sheetName,omitempty"`
+	// Cell is the cell of the cell condition, eg: A1
+	Cell string `json:"cell,omitempty"`
+	// HeaderRow is the row of the headers, 1 by default
+	HeaderRow int    `json:"headerRow,omitempty"`
+	Value     string `json:"value"`
+	// Match is "equals" (default), "contains", "regex" or "glob", the file name is matched as a
+	// glob by default, eg: acme-*.xlsx
+	Match string `json:"match,omitempty"`
+	// Negate applies the rule when the condition does not hold
+	Negate bool `json:"negate,omitempty"`
 }
\ No newline at end of file
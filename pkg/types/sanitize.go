@@ -0,0 +1,169 @@
+package types
+
+// Sanitize configures the cleanup of the workbook, applied after the rules and before storing the file
+type Sanitize struct {
+	// ClearDocProps clears the document properties: author, last modified by, title, subject,
+	// description, keywords, category, company and manager. Created and modified dates are kept
+	ClearDocProps bool `json:"clearDocProps,omitempty"`
+	// DocProps overwrites document properties, applied after clearing
+	DocProps *DocProps `json:"docProps,omitempty"`
+	// RemoveCustomProps removes the custom document properties
+	RemoveCustomProps bool `json:"removeCustomProps,omitempty"`
+	// RemoveCustomXml removes the custom XML parts, eg: SharePoint metadata
+	RemoveCustomXml bool `json:"removeCustomXml,omitempty"`
+	// HiddenContent handles the hidden and very hidden sheets, rows and columns: "remove" deletes
+	// them, "unhide" reveals them and "report" only lists them in the sanitize report
+	HiddenContent string `json:"hiddenContent,omitempty"`
+	// Pictures is "remove" to remove every picture, or "redacted" to remove the pictures
+	// covering redacted cells and those anchored in the rows and columns excluded by the rules
+	Pictures string `json:"pictures,omitempty"`
+	// RemoveShapes removes the shapes, text boxes and groups of the drawings
+	RemoveShapes bool `json:"removeShapes,omitempty"`
+	// ShapeText redacts text inside the shapes and text boxes that are kept
+	ShapeText *TextRedaction `json:"shapeText,omitempty"`
+	// ChartCaches handles the cached values of the charts plotting redacted or excluded cells:
+	// "clear" drops the cached values, "refresh" rebuilds them from the transformed cells and
+	// "remove" deletes the charts
+	ChartCaches string `json:"chartCaches,omitempty"`
+	// PivotCaches handles the pivot tables whose source holds redacted or excluded cells:
+	// "refresh" drops the cached records so the pivot is rebuilt when opened, "static" keeps
+	// the pivot values as plain cells and "remove" deletes the pivot tables and their values
+	PivotCaches string `json:"pivotCaches,omitempty"`
+	// Hyperlinks removes or rewrites the hyperlinks of the cells
+	Hyperlinks *LinkPolicy `json:"hyperlinks,omitempty"`
+	// ExternalLinks removes or rewrites the links to other workbooks, the formulas using a
+	// removed link keep their last value
+	ExternalLinks *LinkPolicy `json:"externalLinks,omitempty"`
+	// Connections removes or rewrites the data connections along with their query tables
+	Connections *LinkPolicy `json:"connections,omitempty"`
+	// RemoveMacros removes the VBA project of a macro-enabled workbook, the output is a plain .xlsx
+	RemoveMacros bool `json:"removeMacros,omitempty"`
+	// HeadersFooters redacts the text of the page headers and footers, including codes such as
+	// &Z which prints the file path
+	HeadersFooters *TextRedaction `json:"headersFooters,omitempty"`
+	// PrintTitles redacts the text of the rows and columns repeated on every printed page
+	PrintTitles *TextRedaction `json:"printTitles,omitempty"`
+	// SheetNames renames the sheets, the formulas and defined names referring to them follow
+	SheetNames *SheetNames `json:"sheetNames,omitempty"`
+	// VerifyRedaction fails the job when the original value of a redacted cell remains in its
+	// traces: the cell, its shared string, the cached results of the formulas referring to it, its
+	// comments and the defined names
+	VerifyRedaction bool `json:"verifyRedaction,omitempty"`
+}
+
+// SheetNames renames the sheets by name or with a pattern
+type SheetNames struct {
+	// Mapping gives the new name of some sheets
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// Pattern names the other sheets selected by Match, {n} is the position of the sheet
+	// starting at 1. It defaults to "Sheet{n}" when Match is set
+	Pattern string `json:"pattern,omitempty"`
+	// Match selects the sheets renamed with the pattern, every sheet when not set
+	Match *TextRedaction `json:"match,omitempty"`
+}
+
+// LinkPolicy removes or rewrites a category of links
+type LinkPolicy struct {
+	// Action is "remove" or "rewrite"
+	Action string `json:"action"`
+	// Pattern is a regular expression selecting the targets, every target is selected when empty
+	Pattern string `json:"pattern,omitempty"`
+	// Replacement rewrites the matches of the pattern, or the whole target without a pattern.
+	// It may refer to the groups of the pattern, eg: $1
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// TextRedaction redacts parts of a text
+type TextRedaction struct {
+	// Values are redacted wherever they appear in the text
+	Values []string `json:"values,omitempty"`
+	// Patterns are regular expressions, every match is redacted
+	Patterns []string `json:"patterns,omitempty"`
+	// Detectors are the PII detectors of the scan whose findings are redacted, eg: "email"
+	Detectors []string `json:"detectors,omitempty"`
+}
+
+// DocProps are the document properties that can be overwritten, empty fields are left as they are
+type DocProps struct {
+	Creator        string `json:"creator,omitempty"`
+	LastModifiedBy string `json:"lastModifiedBy,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Keywords       string `json:"keywords,omitempty"`
+	Category       string `json:"category,omitempty"`
+	Company        string `json:"company,omitempty"`
+	Manager        string `json:"manager,omitempty"`
+}
+
+// SanitizeReport lists what the sanitize step found in the workbook
+type SanitizeReport struct {
+	HiddenSheets         []HiddenSheet          `json:"hiddenSheets,omitempty"`
+	HiddenRowsAndColumns []HiddenRowsAndColumns `json:"hiddenRowsAndColumns,omitempty"`
+	RemovedPictures      int                    `json:"removedPictures,omitempty"`
+	RemovedShapes        int                    `json:"removedShapes,omitempty"`
+	// RedactedShapes counts the shapes and text boxes whose text was redacted
+	RedactedShapes int             `json:"redactedShapes,omitempty"`
+	ScrubbedCaches []ScrubbedCache `json:"scrubbedCaches,omitempty"`
+	Links          []SanitizedLink `json:"links,omitempty"`
+	// Macros is set when the input workbook is macro-enabled or holds a VBA project
+	Macros *Macros `json:"macros,omitempty"`
+	// RedactedHeadersFooters counts the headers and footers whose text was redacted
+	RedactedHeadersFooters int `json:"redactedHeadersFooters,omitempty"`
+	// RedactedPrintTitles counts the cells of the print titles whose text was redacted
+	RedactedPrintTitles int            `json:"redactedPrintTitles,omitempty"`
+	RenamedSheets       []RenamedSheet `json:"renamedSheets,omitempty"`
+	// PurgedSharedStrings counts the shared strings no cell used anymore
+	PurgedSharedStrings int `json:"purgedSharedStrings,omitempty"`
+	// ClearedCachedValues counts the formulas whose cached value was a redacted original
+	ClearedCachedValues int `json:"clearedCachedValues,omitempty"`
+}
+
+type RenamedSheet struct {
+	SheetName string `json:"sheetName"`
+	NewName   string `json:"newName"`
+}
+
+type HiddenSheet struct {
+	SheetName string `json:"sheetName"`
+	// Visibility is "hidden" or "veryHidden"
+	Visibility string `json:"visibility"`
+}
+
+type HiddenRowsAndColumns struct {
+	SheetName string   `json:"sheetName"`
+	Rows      []int    `json:"rows,omitempty"`
+	Columns   []string `json:"columns,omitempty"`
+}
+
+// ScrubbedCache is a chart or a pivot table whose cache duplicated redacted or excluded cells
+type ScrubbedCache struct {
+	// Type is "chart" or "pivotTable"
+	Type      string `json:"type"`
+	SheetName string `json:"sheetName,omitempty"`
+	// Name is the pivot table name, or the chart part for charts
+	Name string `json:"name"`
+	// Action is the applied policy, eg: "clear"
+	Action string `json:"action"`
+	// Range is the area of a pivot table whose values were left as plain cells by "static"
+	Range string `json:"range,omitempty"`
+}
+
+// SanitizedLink is a link removed or rewritten by the sanitize step
+type SanitizedLink struct {
+	Link
+	// Action is "remove" or "rewrite"
+	Action          string `json:"action"`
+	RewrittenTarget string `json:"rewrittenTarget,omitempty"`
+}
+
+// Macros describes the macros found in the input workbook
+type Macros struct {
+	// MacroEnabled is set for the .xlsm, .xltm and .xlam workbooks
+	MacroEnabled bool `json:"macroEnabled"`
+	VbaProject   bool `json:"vbaProject"`
+	// Signed is set when the VBA project is digitally signed
+	Signed bool `json:"signed,omitempty"`
+	// Removed is set when the VBA project was removed and the workbook converted to a plain .xlsx
+	Removed bool `json:"removed,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+type RequestBodyScan struct {
+	Input Input `json:"input" validate:"required"`
+	// Detectors lists the PII detectors to run: "email", "phone", "ssn", "creditCard", "iban" and
+	// "ipAddress". Every detector runs when empty
+	Detectors []string `json:"detectors,omitempty"`
+	// Dictionary finds the terms of a list, as the DICTIONARY operation does
+	Dictionary *DictionaryOptions `json:"dictionary,omitempty"`
+	// Patterns are regular expressions, every match is a finding
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// ScanFinding is a sensitive value found in a cell, a comment or a header or footer
+type ScanFinding struct {
+	SheetName string `json:"sheetName"`
+	// Cell is the cell holding the value or the comment, empty for headers and footers
+	Cell string `json:"cell,omitempty"`
+	// Location is "cell", "comment" or the header or footer, eg: "oddHeader"
+	Location string `json:"location"`
+	// Category is the detector that found the value, "dictionary" or "regex"
+	Category string `json:"category"`
+	// Confidence is between 0 and 1, the values checked with a checksum score higher
+	Confidence float64 `json:"confidence"`
+	// Preview shows the value with most of its characters masked
+	Preview string `json:"preview"`
+	// PatternIndex is the index of the pattern of a regex finding
+	PatternIndex *int `json:"patternIndex,omitempty"`
+}
+
+type ScanResult struct {
+	Findings []ScanFinding `json:"findings"`
+	// Counts gives the number of findings of each category
+	Counts map[string]int `json:"counts"`
+	// SuggestedRules would redact the findings of the cells and comments
+	SuggestedRules []Rule `json:"suggestedRules"`
+	// SuggestedSanitize would redact the findings of the headers and footers
+	SuggestedSanitize *Sanitize `json:"suggestedSanitize,omitempty"`
+}
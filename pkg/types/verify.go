@@ -0,0 +1,34 @@
+package types
+
+// VerifyResult is the verdict on a transformed workbook
+type VerifyResult struct {
+	// Clean is set when values were scanned and no sensitive value and no unredacted cell was found
+	Clean bool `json:"clean"`
+	// Unchecked is set when no value was scanned, eg: with a manifest alone, the verdict is then
+	// never clean
+	Unchecked bool        `json:"unchecked,omitempty"`
+	Hits      []VerifyHit `json:"hits"`
+	// FileHash is the hex encoded SHA-256 of the verified workbook
+	FileHash      string `json:"fileHash"`
+	CheckedValues int    `json:"checkedValues"`
+	CheckedCells  int    `json:"checkedCells"`
+	// SkippedCells counts the cells of the manifest on sheets missing from the workbook
+	SkippedCells int `json:"skippedCells,omitempty"`
+	// VerifiedAt is an RFC 3339 timestamp
+	VerifiedAt string `json:"verifiedAt"`
+	// Signature is the hex encoded HMAC-SHA256 of the verdict, set when a signing key is configured
+	Signature string `json:"signature,omitempty"`
+}
+
+// VerifyHit is a sensitive value found in the workbook, or a cell of the manifest that is no longer redacted
+type VerifyHit struct {
+	// Kind is "cell", "sharedString", "comment", "drawing", "chartCache", "pivotCache", "docProps",
+	// "definedName", "sheet", "workbook", "part", "unredactedCell" or "unscrubbedFormula"
+	Kind      string `json:"kind"`
+	Part      string `json:"part,omitempty"`
+	SheetName string `json:"sheetName,omitempty"`
+	// Location is the cell, the index of the shared string, the defined name or the element holding the value
+	Location string `json:"location,omitempty"`
+	// ValueIndex is the index of the value found in the request, the value itself is not returned
+	ValueIndex *int `json:"valueIndex,omitempty"`
+}
@@ -10,15 +10,49 @@ type StyledCell struct {
 type Sheet struct {
 	SheetName string         `json:"sheetName"`
 	Cells     [][]StyledCell `json:"cells"`
+	// Merges lists the merged areas, eg: "A1:C1". The value of an area is in its top left cell
+	Merges []string `json:"merges,omitempty"`
+	// HiddenRows and HiddenColumns list the hidden rows and columns, their cells are still exported
+	HiddenRows    []int    `json:"hiddenRows,omitempty"`
+	HiddenColumns []string `json:"hiddenColumns,omitempty"`
+	Comments      []Comment `json:"comments,omitempty"`
 }
 
 type SheetMinimal struct {
 	SheetName string `json:"sheetName"`
 	SheetTabColor string `json:"sheetTabColor,omitempty"`
+	// Visibility is "visible", "hidden" or "veryHidden"
+	Visibility string `json:"visibility"`
 }
 
 type Attributes struct {
 	SheetMinimals []SheetMinimal `json:"sheetMinimals"`
 	TextColors   []string        `json:"textColors"`
 	BgColors     []string        `json:"bgColors"`
+	// Links are the hyperlinks, external workbook links and data connections of the workbook
+	Links []Link `json:"links,omitempty"`
+}
+
+// Comment is a note or a threaded comment attached to a cell
+type Comment struct {
+	Cell   string `json:"cell"`
+	Author string `json:"author"`
+	Text   string `json:"text"`
+	// Threaded is set for the modern comment threads, IsReply for the replies within a thread
+	Threaded bool `json:"threaded,omitempty"`
+	IsReply  bool `json:"isReply,omitempty"`
+}
+
+// Link is a hyperlink, an external workbook link or a data connection
+type Link struct {
+	// Type is "hyperlink", "externalLink" or "connection"
+	Type      string `json:"type"`
+	SheetName string `json:"sheetName,omitempty"`
+	// Cell is the cell or the area of a hyperlink
+	Cell string `json:"cell,omitempty"`
+	// Name is the name of a data connection
+	Name string `json:"name,omitempty"`
+	// Target is the address of a hyperlink, the path of an external workbook or the
+	// connection string of a data connection
+	Target string `json:"target"`
 }
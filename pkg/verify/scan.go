@@ -0,0 +1,323 @@
+package verify
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"xlsx-processor/pkg/matcher"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+)
+
+// Constants for the kinds of hits
+const (
+	CELL               string = "cell"
+	SHARED_STRING      string = "sharedString"
+	COMMENT            string = "comment"
+	DRAWING            string = "drawing"
+	CHART_CACHE        string = "chartCache"
+	PIVOT_CACHE        string = "pivotCache"
+	DOC_PROPS          string = "docProps"
+	DEFINED_NAME       string = "definedName"
+	SHEET              string = "sheet"
+	WORKBOOK           string = "workbook"
+	PART               string = "part"
+	UNREDACTED_CELL    string = "unredactedCell"
+	UNSCRUBBED_FORMULA string = "unscrubbedFormula"
+)
+
+const (
+	workbookPath      = "xl/workbook.xml"
+	sharedStringsPath = "xl/sharedStrings.xml"
+	// MinimumContainedLength is the length under which a value must be the whole text to be
+	// found, shorter values cannot be told apart from numbers and markup, eg: 1
+	MinimumContainedLength = 3
+)
+
+// The parts are read textually, the elements scanned as a whole do not nest
+var (
+	xmlPartPattern        = regexp.MustCompile(`\.(?:xml|rels|vml)$`)
+	inlineStringPattern   = regexp.MustCompile(`(?s)<is>.*?</is>`)
+	commentPattern        = regexp.MustCompile(`(?s)<(?:comment|threadedComment)\b[^>]*?(?:/>|>.*?</(?:comment|threadedComment)>)`)
+	definedNamePattern    = regexp.MustCompile(`(?s)<definedName\b[^>]*>.*?</definedName>`)
+	startTagPattern       = regexp.MustCompile(`<([\w:]+)\b[^>]*>`)
+	elementTextPattern    = regexp.MustCompile(`<([\w:]+)\b[^>]*>([^<]+)<`)
+	textNodePattern       = regexp.MustCompile(`>([^<]+)<`)
+	attributeValuePattern = regexp.MustCompile(`\s[\w:]+="([^"]*)"`)
+	commentPartPattern    = regexp.MustCompile(`^xl/(?:comments\d*|threadedComments/threadedComment\d+)\.xml$`)
+	drawingPartPattern    = regexp.MustCompile(`^xl/drawings/(?:drawing\d+\.xml|vmlDrawing\d+\.vml)$`)
+	chartPartPattern      = regexp.MustCompile(`^xl/charts/chart\d+\.xml$`)
+	pivotCachePartPattern = regexp.MustCompile(`^xl/pivotCache/pivotCache(?:Definition|Records)\d+\.xml$`)
+)
+
+// Scanner finds sensitive values in the parts of a workbook package. The hits point to the
+// index of the value rather than the value, so they can be logged and returned safely
+type Scanner struct {
+	dictionary      *matcher.Dictionary
+	caseInsensitive bool
+	// indexes maps the normalized values to their indexes in the list given to MakeScanner
+	indexes map[string]int
+}
+
+// MakeScanner builds a scanner for the values, blank values are ignored
+func MakeScanner(values []string, caseInsensitive bool) *Scanner {
+	s := &Scanner{
+		dictionary:      matcher.NewDictionary(values, caseInsensitive, false),
+		caseInsensitive: caseInsensitive,
+		indexes:         map[string]int{},
+	}
+	for index, value := range values {
+		normalized := s.normalize(value)
+		if _, found := s.indexes[normalized]; normalized != "" && !found {
+			s.indexes[normalized] = index
+		}
+	}
+	return s
+}
+
+// Len returns the number of values the scanner looks for
+func (s *Scanner) Len() int {
+	return len(s.indexes)
+}
+
+func (s *Scanner) normalize(text string) string {
+	text = strings.TrimSpace(text)
+	if s.caseInsensitive {
+		return strings.ToLower(text)
+	}
+	return text
+}
+
+// findText returns the indexes of the values found in a text. Values shorter than
+// MinimumContainedLength are only found when they are the whole text
+func (s *Scanner) findText(text string) []int {
+	found := map[int]bool{}
+	for _, match := range s.dictionary.FindAll(text) {
+		if utf8.RuneCountInString(match.Term) < MinimumContainedLength && s.normalize(text) != s.normalize(match.Term) {
+			continue
+		}
+		found[s.indexes[s.normalize(match.Term)]] = true
+	}
+	return sortedIndexes(found)
+}
+
+// findAttributes returns the indexes of the values an attribute of an element equals, eg: the
+// shared items of a pivot cache such as <s v="Jane"/>
+func (s *Scanner) findAttributes(element string) []int {
+	found := map[int]bool{}
+	for _, match := range attributeValuePattern.FindAllStringSubmatch(element, -1) {
+		value := s.normalize(html.UnescapeString(match[1]))
+		if index, isValue := s.indexes[value]; isValue && utf8.RuneCountInString(value) >= MinimumContainedLength {
+			found[index] = true
+		}
+	}
+	return sortedIndexes(found)
+}
+
+// findBlock returns the indexes of the values found in the text or the attributes of a block
+// of markup, the text of its runs is joined first, eg: a rich text shared string
+func (s *Scanner) findBlock(block string) []int {
+	found := map[int]bool{}
+	for _, index := range s.findText(textOf(block)) {
+		found[index] = true
+	}
+	for _, element := range startTagPattern.FindAllString(block, -1) {
+		for _, index := range s.findAttributes(element) {
+			found[index] = true
+		}
+	}
+	return sortedIndexes(found)
+}
+
+// findCell returns the indexes of the values found in the formula, the value or the inline string
+// of a cell
+func (s *Scanner) findCell(cell string) []int {
+	found := map[int]bool{}
+	for _, match := range elementTextPattern.FindAllStringSubmatch(cell, -1) {
+		for _, index := range s.findText(html.UnescapeString(match[2])) {
+			found[index] = true
+		}
+	}
+	if match := inlineStringPattern.FindString(cell); match != "" {
+		for _, index := range s.findBlock(match) {
+			found[index] = true
+		}
+	}
+	return sortedIndexes(found)
+}
+
+// Scan returns the hits of the values in every XML part of the package
+func (s *Scanner) Scan(p *ooxml.Package) ([]types.VerifyHit, error) {
+	if s.Len() == 0 {
+		return nil, nil
+	}
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return nil, err
+	}
+	partSheets, err := sheetsOfParts(p, sheetNames)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hits{seen: map[string]bool{}}
+	sharedStringHits := s.scanSharedStrings(p)
+	usedSharedStrings := map[int]bool{}
+
+	for _, name := range p.Parts() {
+		if !xmlPartPattern.MatchString(name) || name == sharedStringsPath {
+			continue
+		}
+		data, _ := p.Part(name)
+		content := string(data)
+		sheetName, isSheet := sheetNames[name]
+		switch {
+		case isSheet:
+			remaining := ooxml.CellPattern.ReplaceAllStringFunc(content, func(cell string) string {
+				location := ooxml.Attribute(cell[:strings.Index(cell, ">")+1], "r")
+				var indexes []int
+				if index, isShared := ooxml.SharedStringIndex(cell); isShared {
+					usedSharedStrings[index] = true
+					indexes = sharedStringHits[index]
+				} else {
+					indexes = s.findCell(cell)
+				}
+				h.add(CELL, name, sheetName, location, indexes)
+				return ""
+			})
+			s.scanElements(h, SHEET, name, sheetName, remaining)
+		case commentPartPattern.MatchString(name):
+			// The authors are listed apart from the comments
+			remaining := commentPattern.ReplaceAllStringFunc(content, func(comment string) string {
+				location := ooxml.Attribute(comment[:strings.Index(comment, ">")+1], "ref")
+				h.add(COMMENT, name, partSheets[name], location, s.findBlock(comment))
+				return ""
+			})
+			s.scanElements(h, COMMENT, name, partSheets[name], remaining)
+		case name == workbookPath:
+			remaining := definedNamePattern.ReplaceAllStringFunc(content, func(definedName string) string {
+				location := ooxml.Attribute(definedName[:strings.Index(definedName, ">")+1], "name")
+				h.add(DEFINED_NAME, name, "", location, s.findBlock(definedName))
+				return ""
+			})
+			s.scanElements(h, WORKBOOK, name, "", remaining)
+		case drawingPartPattern.MatchString(name):
+			s.scanElements(h, DRAWING, name, partSheets[name], content)
+		case chartPartPattern.MatchString(name):
+			s.scanElements(h, CHART_CACHE, name, partSheets[name], content)
+		case pivotCachePartPattern.MatchString(name):
+			s.scanElements(h, PIVOT_CACHE, name, "", content)
+		case strings.HasPrefix(name, "docProps/"):
+			s.scanElements(h, DOC_PROPS, name, "", content)
+		default:
+			s.scanElements(h, PART, name, partSheets[name], content)
+		}
+	}
+
+	// The shared strings no cell uses are reported on their own
+	for index, indexes := range sharedStringHits {
+		if !usedSharedStrings[index] {
+			h.add(SHARED_STRING, sharedStringsPath, "", strconv.Itoa(index), indexes)
+		}
+	}
+
+	return h.list, nil
+}
+
+// scanSharedStrings returns the indexes of the values found in each shared string
+func (s *Scanner) scanSharedStrings(p *ooxml.Package) map[int][]int {
+	data, found := p.Part(sharedStringsPath)
+	if !found {
+		return nil
+	}
+	sharedStringHits := map[int][]int{}
+	for index, item := range ooxml.SharedStringItemPattern.FindAllString(string(data), -1) {
+		if indexes := s.findBlock(item); len(indexes) > 0 {
+			sharedStringHits[index] = indexes
+		}
+	}
+	return sharedStringHits
+}
+
+// scanElements reports the values found in the text or the attributes of the elements of a part,
+// the location is the name of the element, eg: dc:creator
+func (s *Scanner) scanElements(h *hits, kind, part, sheetName, content string) {
+	for _, match := range elementTextPattern.FindAllStringSubmatch(content, -1) {
+		h.add(kind, part, sheetName, match[1], s.findText(html.UnescapeString(match[2])))
+	}
+	for _, match := range startTagPattern.FindAllStringSubmatch(content, -1) {
+		h.add(kind, part, sheetName, match[1], s.findAttributes(match[0]))
+	}
+}
+
+// hits collects the hits once per kind, part, location and value
+type hits struct {
+	list []types.VerifyHit
+	seen map[string]bool
+}
+
+func (h *hits) add(kind, part, sheetName, location string, indexes []int) {
+	for _, index := range indexes {
+		key := strings.Join([]string{kind, part, location, strconv.Itoa(index)}, "\x00")
+		if h.seen[key] {
+			continue
+		}
+		h.seen[key] = true
+		valueIndex := index
+		h.list = append(h.list, types.VerifyHit{
+			Kind:       kind,
+			Part:       part,
+			SheetName:  sheetName,
+			Location:   location,
+			ValueIndex: &valueIndex,
+		})
+	}
+}
+
+// sheetsOfParts maps the parts of the sheets, eg: their comments, drawings and the charts of the
+// drawings, to the names of the sheets
+func sheetsOfParts(p *ooxml.Package, sheetNames map[string]string) (map[string]string, error) {
+	partSheets := map[string]string{}
+	for sheetPart, sheetName := range sheetNames {
+		related, err := p.RelatedParts(sheetPart, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range related {
+			partSheets[part] = sheetName
+			if !drawingPartPattern.MatchString(part) {
+				continue
+			}
+			charts, err := p.RelatedParts(part, "/chart")
+			if err != nil {
+				return nil, err
+			}
+			for _, chart := range charts {
+				partSheets[chart] = sheetName
+			}
+		}
+	}
+	return partSheets, nil
+}
+
+// textOf joins the text nodes of a block of markup
+func textOf(block string) string {
+	var builder strings.Builder
+	for _, match := range textNodePattern.FindAllStringSubmatch(block, -1) {
+		builder.WriteString(html.UnescapeString(match[1]))
+	}
+	return builder.String()
+}
+
+func sortedIndexes(found map[int]bool) []int {
+	indexes := make([]int, 0, len(found))
+	for index := range found {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,233 @@
+package verify
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// String literals of formulas, the quotes inside them are doubled
+var stringLiteralPattern = regexp.MustCompile(`"((?:[^"]|"")*)"`)
+
+// TraceMatcher tells whether a text holds the original of a redacted cell, contained is set when
+// the original may be a part of the text rather than the whole of it
+type TraceMatcher func(redactedCell types.RedactedCell, text string, contained bool) bool
+
+// ValueMatcher compares the texts to the original values recorded in the manifest. Originals
+// shorter than MinimumContainedLength must be the whole text
+func ValueMatcher(redactedCell types.RedactedCell, text string, contained bool) bool {
+	original := strings.TrimSpace(redactedCell.Value)
+	if original == "" || original == "**redacted**" {
+		return false
+	}
+	if contained && utf8.RuneCountInString(original) >= MinimumContainedLength {
+		return strings.Contains(text, original)
+	}
+	return strings.TrimSpace(text) == original
+}
+
+// CheckTraces looks for the originals of the redacted cells in their own traces: the cell itself,
+// the shared strings no cell uses anymore, the cached results of the formulas referring to the
+// cell, the comments on the cell and the string literals of the defined names. Other cells holding
+// the same value are not traces, eg: a copy of the sheet kept on purpose. The formulas are read
+// from f, which must hold the same sheets as the package
+func CheckTraces(f *excelize.File, p *ooxml.Package, redactedCells []types.RedactedCell, matches TraceMatcher) ([]types.VerifyHit, error) {
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return nil, err
+	}
+	partSheets, err := sheetsOfParts(p, sheetNames)
+	if err != nil {
+		return nil, err
+	}
+	t := &traces{
+		matches:     matches,
+		sheetParts:  map[string]string{},
+		sheetCells:  map[string]map[string]string{},
+		seen:        map[string]bool{},
+		sharedTexts: sharedStringTexts(p),
+	}
+
+	usedSharedStrings := map[int]bool{}
+	for sheetPart, sheetName := range sheetNames {
+		t.sheetParts[sheetName] = sheetPart
+		t.sheetCells[sheetName] = map[string]string{}
+		data, _ := p.Part(sheetPart)
+		for _, cell := range ooxml.CellPattern.FindAllString(string(data), -1) {
+			t.sheetCells[sheetName][ooxml.Attribute(cell[:strings.Index(cell, ">")+1], "r")] = cell
+			if index, isShared := ooxml.SharedStringIndex(cell); isShared {
+				usedSharedStrings[index] = true
+			}
+		}
+	}
+
+	// The cells of removed rows, columns and sheets only leave their shared strings and names
+	var present []types.RedactedCell
+	for _, redactedCell := range redactedCells {
+		if _, found := t.sheetCells[redactedCell.SheetName]; found && !redactedCell.Excluded {
+			present = append(present, redactedCell)
+		}
+	}
+
+	for _, redactedCell := range present {
+		sheetPart := t.sheetParts[redactedCell.SheetName]
+		for _, text := range t.cellTexts(t.sheetCells[redactedCell.SheetName][redactedCell.Cell]) {
+			if matches(redactedCell, text, true) {
+				t.add(CELL, sheetPart, redactedCell.SheetName, redactedCell.Cell)
+			}
+		}
+	}
+
+	for _, name := range p.Parts() {
+		if !commentPartPattern.MatchString(name) {
+			continue
+		}
+		data, _ := p.Part(name)
+		for _, comment := range commentPattern.FindAllString(string(data), -1) {
+			location := ooxml.Attribute(comment[:strings.Index(comment, ">")+1], "ref")
+			for _, redactedCell := range present {
+				if redactedCell.SheetName == partSheets[name] && redactedCell.Cell == location && matches(redactedCell, textOf(comment), true) {
+					t.add(COMMENT, name, partSheets[name], location)
+				}
+			}
+		}
+	}
+
+	if err = t.checkFormulaCaches(f, p, sheetNames, present); err != nil {
+		return nil, err
+	}
+
+	for index, text := range t.sharedTexts {
+		if usedSharedStrings[index] {
+			continue
+		}
+		for _, redactedCell := range redactedCells {
+			if matches(redactedCell, text, false) {
+				t.add(SHARED_STRING, sharedStringsPath, "", strconv.Itoa(index))
+			}
+		}
+	}
+
+	workbook, _ := p.Part(workbookPath)
+	for _, definedName := range definedNamePattern.FindAllString(string(workbook), -1) {
+		location := ooxml.Attribute(definedName[:strings.Index(definedName, ">")+1], "name")
+		for _, literal := range stringLiteralPattern.FindAllStringSubmatch(textOf(definedName), -1) {
+			for _, redactedCell := range redactedCells {
+				if matches(redactedCell, strings.ReplaceAll(literal[1], `""`, `"`), false) {
+					t.add(DEFINED_NAME, workbookPath, "", location)
+				}
+			}
+		}
+	}
+
+	return t.hits, nil
+}
+
+// traces collects the hits of CheckTraces once per kind, part and location
+type traces struct {
+	matches     TraceMatcher
+	sheetParts  map[string]string
+	sheetCells  map[string]map[string]string
+	sharedTexts []string
+	hits        []types.VerifyHit
+	seen        map[string]bool
+}
+
+func (t *traces) add(kind, part, sheetName, location string) {
+	key := strings.Join([]string{kind, part, location}, "\x00")
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+	t.hits = append(t.hits, types.VerifyHit{Kind: kind, Part: part, SheetName: sheetName, Location: location})
+}
+
+// cellTexts returns the texts a cell shows or keeps: its shared string, its inline string, its
+// formula and its value
+func (t *traces) cellTexts(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	var texts []string
+	if index, isShared := ooxml.SharedStringIndex(cell); isShared {
+		if index < len(t.sharedTexts) {
+			texts = append(texts, t.sharedTexts[index])
+		}
+		return texts
+	}
+	for _, match := range elementTextPattern.FindAllStringSubmatch(cell, -1) {
+		texts = append(texts, html.UnescapeString(match[2]))
+	}
+	if match := inlineStringPattern.FindString(cell); match != "" {
+		texts = append(texts, textOf(match))
+	}
+	return texts
+}
+
+// checkFormulaCaches reports the formulas referring to a redacted cell whose cached result is
+// still its original
+func (t *traces) checkFormulaCaches(f *excelize.File, p *ooxml.Package, sheetNames map[string]string, present []types.RedactedCell) error {
+	for _, sheetPart := range p.Parts() {
+		sheetName, isSheet := sheetNames[sheetPart]
+		if !isSheet {
+			continue
+		}
+		data, _ := p.Part(sheetPart)
+		for _, cell := range ooxml.CellPattern.FindAllString(string(data), -1) {
+			if !strings.Contains(cell, "<f>") && !strings.Contains(cell, "<f ") {
+				continue
+			}
+			match := ooxml.CellValuePattern.FindStringSubmatch(cell)
+			if match == nil {
+				continue
+			}
+			cachedValue := html.UnescapeString(match[1])
+			location := ooxml.Attribute(cell[:strings.Index(cell, ">")+1], "r")
+
+			var references []formula.Reference
+			for _, redactedCell := range present {
+				if !t.matches(redactedCell, cachedValue, false) {
+					continue
+				}
+				if references == nil {
+					cellFormula, err := f.GetCellFormula(sheetName, location)
+					if err != nil {
+						return err
+					}
+					references = formula.GetReferences(f, sheetName, cellFormula)
+				}
+				col, row, err := excelize.CellNameToCoordinates(redactedCell.Cell)
+				if err != nil {
+					return err
+				}
+				for _, reference := range references {
+					if reference.Contains(redactedCell.SheetName, col, row) {
+						t.add(CELL, sheetPart, sheetName, location)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sharedStringTexts returns the text of each shared string, the runs of rich text joined
+func sharedStringTexts(p *ooxml.Package) []string {
+	data, found := p.Part(sharedStringsPath)
+	if !found {
+		return nil
+	}
+	var texts []string
+	for _, item := range ooxml.SharedStringItemPattern.FindAllString(string(data), -1) {
+		texts = append(texts, textOf(item))
+	}
+	return texts
+}
@@ -0,0 +1,180 @@
+package verify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Workbook scans a workbook for the values and checks the cells of the manifest are still
+// redacted. The hashes of a sealed manifest are checked against the traces of their cells and
+// count as checked values. The verdict is clean when values were checked and nothing was found,
+// without values or hashes the result is unchecked
+func Workbook(fileContents []byte, values []string, caseInsensitive bool, manifest *types.Manifest) (*types.VerifyResult, error) {
+	hash := sha256.Sum256(fileContents)
+	result := &types.VerifyResult{
+		Hits:       []types.VerifyHit{},
+		FileHash:   hex.EncodeToString(hash[:]),
+		VerifiedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	scanner := MakeScanner(values, caseInsensitive)
+	result.CheckedValues = scanner.Len()
+	if scanner.Len() > 0 {
+		p, err := ooxml.ReadPackage(fileContents)
+		if err != nil {
+			return nil, err
+		}
+		hits, err := scanner.Scan(p)
+		if err != nil {
+			return nil, err
+		}
+		result.Hits = append(result.Hits, hits...)
+	}
+
+	if manifest != nil {
+		f, err := file.InitFileFromBytes(fileContents)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		hits, checked, skipped, err := CheckManifest(f, manifest)
+		if err != nil {
+			return nil, err
+		}
+		result.Hits = append(result.Hits, hits...)
+		result.CheckedCells = checked
+		result.SkippedCells = skipped
+
+		hashes := map[string]bool{}
+		for _, redactedCell := range slices.Concat(manifest.RedactedCells, manifest.ScrubbedFormulas) {
+			if redactedCell.Hash != "" {
+				hashes[redactedCell.Hash] = true
+			}
+		}
+		if manifest.Salt != "" && len(hashes) > 0 {
+			p, err := ooxml.ReadPackage(fileContents)
+			if err != nil {
+				return nil, err
+			}
+			hits, err := CheckTraces(f, p, slices.Concat(manifest.RedactedCells, manifest.ScrubbedFormulas), HashMatcher(manifest.Salt))
+			if err != nil {
+				return nil, err
+			}
+			result.Hits = append(result.Hits, hits...)
+			result.CheckedValues += len(hashes)
+		}
+	}
+
+	result.Unchecked = result.CheckedValues == 0
+	result.Clean = len(result.Hits) == 0 && !result.Unchecked
+	return result, nil
+}
+
+// CheckManifest returns the redacted cells of the manifest that no longer hold the redaction and
+// the scrubbed formulas that came back. The cells excluded from the output are not checked, the
+// cells of missing sheets, eg: renamed by the sanitize step, are counted as skipped
+func CheckManifest(f *excelize.File, manifest *types.Manifest) (hits []types.VerifyHit, checked int, skipped int, err error) {
+	sheets := map[string]bool{}
+	for _, sheetName := range f.GetSheetList() {
+		sheets[sheetName] = true
+	}
+
+	for _, redactedCell := range manifest.RedactedCells {
+		if redactedCell.Excluded {
+			continue
+		}
+		if !sheets[redactedCell.SheetName] {
+			skipped++
+			continue
+		}
+		checked++
+		cellValue, err := f.GetCellValue(redactedCell.SheetName, redactedCell.Cell)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if cellValue != "" && cellValue != "**redacted**" {
+			hits = append(hits, types.VerifyHit{
+				Kind:      UNREDACTED_CELL,
+				SheetName: redactedCell.SheetName,
+				Location:  redactedCell.Cell,
+			})
+		}
+	}
+
+	for _, scrubbedFormula := range manifest.ScrubbedFormulas {
+		if scrubbedFormula.Excluded {
+			continue
+		}
+		if !sheets[scrubbedFormula.SheetName] {
+			skipped++
+			continue
+		}
+		checked++
+		cellFormula, err := f.GetCellFormula(scrubbedFormula.SheetName, scrubbedFormula.Cell)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if cellFormula != "" {
+			hits = append(hits, types.VerifyHit{
+				Kind:      UNSCRUBBED_FORMULA,
+				SheetName: scrubbedFormula.SheetName,
+				Location:  scrubbedFormula.Cell,
+			})
+		}
+	}
+
+	return hits, checked, skipped, nil
+}
+
+// HashValue returns the hex encoded SHA-256 of the salt and the value, the value is trimmed as
+// the traces are compared
+func HashValue(salt, value string) string {
+	hash := sha256.Sum256([]byte(salt + "\x00" + strings.TrimSpace(value)))
+	return hex.EncodeToString(hash[:])
+}
+
+// SealManifest draws a salt and hashes the original values of the redacted cells and the scrubbed
+// formulas, the values themselves are never serialized
+func SealManifest(manifest *types.Manifest) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to draw the manifest salt: %w", err)
+	}
+	manifest.Salt = hex.EncodeToString(salt)
+	for _, redactedCells := range [][]types.RedactedCell{manifest.RedactedCells, manifest.ScrubbedFormulas} {
+		for i := range redactedCells {
+			if value := strings.TrimSpace(redactedCells[i].Value); value != "" && value != "**redacted**" {
+				redactedCells[i].Hash = HashValue(manifest.Salt, value)
+			}
+		}
+	}
+	return nil
+}
+
+// HashMatcher compares the traces to the hashes of a sealed manifest, the hashes only tell whole
+// texts apart
+func HashMatcher(salt string) TraceMatcher {
+	return func(redactedCell types.RedactedCell, text string, contained bool) bool {
+		return redactedCell.Hash != "" && HashValue(salt, text) == redactedCell.Hash
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the verdict, the file hash, the counts and the time
+// of the verification
+func Sign(result *types.VerifyResult, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%t|%s|%d|%d|%s", result.Clean, result.FileHash, result.CheckedValues, result.CheckedCells, result.VerifiedAt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,196 @@
+package verify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newWorkbook writes a workbook holding a name in a cell, a comment, a defined name and the title
+func newWorkbook(t *testing.T) []byte {
+	f := excelize.NewFile()
+	f.SetSheetCol("Sheet1", "A1", &[]any{"Name", "Jane Doe", "**redacted**"})
+	f.SetCellValue("Sheet1", "B1", 12)
+	f.AddComment("Sheet1", excelize.Comment{Cell: "B2", Author: "Auditor", Text: "Checked with jane doe"})
+	f.SetDefinedName(&excelize.DefinedName{Name: "Contact", RefersTo: `"Jane Doe"`})
+	f.SetDocProps(&excelize.DocProperties{Title: "Payroll of Jane Doe"})
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// hitKinds returns the kind and the location of the hits
+func hitKinds(hits []types.VerifyHit) map[string]string {
+	kinds := map[string]string{}
+	for _, hit := range hits {
+		kinds[hit.Kind] = hit.Location
+	}
+	return kinds
+}
+
+func TestWorkbook(t *testing.T) {
+	fileContents := newWorkbook(t)
+
+	t.Run("find the values in every part", func(t *testing.T) {
+		result, err := Workbook(fileContents, []string{"Nobody", "Jane Doe"}, false, nil)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, false, result.Clean)
+		assert.Equal(t, 2, result.CheckedValues)
+		assert.Equal(t, map[string]string{
+			CELL:         "A2",
+			DEFINED_NAME: "Contact",
+			DOC_PROPS:    "dc:title",
+		}, hitKinds(result.Hits))
+		for _, hit := range result.Hits {
+			assert.Equal(t, 1, *hit.ValueIndex)
+			if hit.Kind == CELL {
+				assert.Equal(t, "Sheet1", hit.SheetName)
+			}
+		}
+	})
+
+	t.Run("match without case", func(t *testing.T) {
+		result, err := Workbook(fileContents, []string{"JANE DOE"}, true, nil)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, "B2", hitKinds(result.Hits)[COMMENT])
+	})
+
+	t.Run("short values must be the whole text", func(t *testing.T) {
+		result, err := Workbook(fileContents, []string{"12", "Na"}, false, nil)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, map[string]string{CELL: "B1"}, hitKinds(result.Hits))
+	})
+
+	t.Run("check the cells of the manifest", func(t *testing.T) {
+		manifest := &types.Manifest{
+			RedactedCells: []types.RedactedCell{
+				{SheetName: "Sheet1", Cell: "A2"},
+				{SheetName: "Sheet1", Cell: "A3"},
+				{SheetName: "Sheet1", Cell: "A4", Excluded: true},
+				{SheetName: "Removed", Cell: "A1"},
+			},
+		}
+		result, err := Workbook(fileContents, nil, false, manifest)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, false, result.Clean)
+		assert.Equal(t, 2, result.CheckedCells)
+		assert.Equal(t, 1, result.SkippedCells)
+		assert.Equal(t, []types.VerifyHit{{Kind: UNREDACTED_CELL, SheetName: "Sheet1", Location: "A2"}}, result.Hits)
+	})
+
+	t.Run("a manifest alone is unchecked", func(t *testing.T) {
+		manifest := &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "A3"}}}
+		result, err := Workbook(fileContents, nil, false, manifest)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, 0, len(result.Hits))
+		assert.Equal(t, true, result.Unchecked)
+		assert.Equal(t, false, result.Clean)
+	})
+
+	t.Run("check the hashes of a sealed manifest read back from JSON", func(t *testing.T) {
+		sealed := &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "A3", Value: "Jane Doe"}}}
+		if err := SealManifest(sealed); err != nil {
+			t.Fatalf("failed to seal the manifest: %v", err)
+		}
+		data, _ := json.Marshal(sealed)
+		assert.Equal(t, false, strings.Contains(string(data), "Jane"))
+		var manifest types.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to read the manifest: %v", err)
+		}
+
+		result, err := Workbook(fileContents, nil, false, &manifest)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		// The defined name is a trace of every redacted cell, the cell A2 holding the same value is not
+		assert.Equal(t, false, result.Unchecked)
+		assert.Equal(t, 1, result.CheckedValues)
+		assert.Equal(t, map[string]string{DEFINED_NAME: "Contact"}, hitKinds(result.Hits))
+	})
+
+	t.Run("a sealed manifest alone can be clean", func(t *testing.T) {
+		manifest := &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "A3", Value: "John Roe"}}}
+		if err := SealManifest(manifest); err != nil {
+			t.Fatalf("failed to seal the manifest: %v", err)
+		}
+		result, err := Workbook(fileContents, nil, false, manifest)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, true, result.Clean)
+		assert.Equal(t, 1, result.CheckedValues)
+	})
+
+	t.Run("clean and signed verdict", func(t *testing.T) {
+		result, err := Workbook(fileContents, []string{"John Roe"}, false, nil)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		assert.Equal(t, true, result.Clean)
+		assert.Equal(t, 64, len(result.FileHash))
+		assert.Equal(t, Sign(result, []byte("key")), Sign(result, []byte("key")))
+		assert.NotEqual(t, Sign(result, []byte("key")), Sign(result, []byte("other key")))
+	})
+}
+
+func TestCheckTraces(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "A1", "Jane Doe")
+	f.SetCellValue("Sheet1", "A1", "**redacted**")
+	f.SetCellFormula("Sheet1", "B1", "A1")
+	f.SetCellValue("Sheet1", "C1", "Paid to Jane Doe")
+	f.NewSheet("Raw")
+	f.SetCellValue("Raw", "A1", "Jane Doe Smith")
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+	sheet, _ := p.Part("xl/worksheets/sheet1.xml")
+	p.SetPart("xl/worksheets/sheet1.xml", []byte(strings.Replace(string(sheet),
+		`<f>A1</f></c>`, `<f>A1</f><v>Jane Doe</v></c>`, 1)))
+
+	redactedCells := []types.RedactedCell{{SheetName: "Sheet1", Cell: "A1", Value: "Jane Doe"}}
+	hits, err := CheckTraces(f, p, redactedCells, ValueMatcher)
+	if err != nil {
+		t.Fatalf("failed to check the traces: %v", err)
+	}
+
+	// The other cells holding the original are not traces of the redacted cell
+	assert.Equal(t, []types.VerifyHit{
+		{Kind: CELL, Part: "xl/worksheets/sheet1.xml", SheetName: "Sheet1", Location: "B1"},
+		{Kind: SHARED_STRING, Part: "xl/sharedStrings.xml", Location: "0"},
+	}, hits)
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"fmt"
+
+	"xlsx-processor/pkg/rulefile"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/storage"
+)
+
+// storageReader reads the rule files stored next to the source, in the same bucket and with the
+// same credentials
+func storageReader(source types.Input) rulefile.ReadFunc {
+	return func(name string) ([]byte, error) {
+		input := source
+		input.Reference.Prefix = name
+		return storage.GetFileBytes(input.StorageType, input)
+	}
+}
+
+// loadRuleFile reads the rules of a stored or inline rule file, if any
+func loadRuleFile(ruleFile *types.RuleFile) ([]types.Rule, error) {
+	if ruleFile == nil {
+		return nil, nil
+	}
+	switch {
+	case ruleFile.Source != nil && ruleFile.Content != "":
+		return nil, fmt.Errorf("%w: give either its source or its content", rulefile.ErrInvalid)
+	case ruleFile.Source != nil:
+		loader := rulefile.MakeLoader(storageReader(*ruleFile.Source), ruleFile.Variables)
+		return loader.Load(ruleFile.Source.Reference.Prefix)
+	case ruleFile.Content != "":
+		loader := rulefile.MakeLoader(nil, ruleFile.Variables)
+		return loader.Parse("content", []byte(ruleFile.Content))
+	}
+	return nil, fmt.Errorf("%w: its source or its content is required", rulefile.ErrInvalid)
+}
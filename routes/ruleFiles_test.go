@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"errors"
+	"testing"
+
+	"xlsx-processor/pkg/rulefile"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestLoadRuleFile(t *testing.T) {
+	inlineRules := []types.Rule{{
+		PageCondition: types.PageCondition{SheetName: "Sheet2"},
+		Actions:       []types.Action{{Operation: "COLUMN", Value: "C", ActionType: "EXCLUDE"}},
+	}}
+
+	t.Run("put the rule file before the inline rules", func(t *testing.T) {
+		rules, _, err := resolveRules(types.RequestBodyTransform{
+			Rules: inlineRules,
+			RuleFile: &types.RuleFile{
+				Content:   "- {pageCondition: {sheetName: '${client_name} Payroll'}, actions: []}",
+				Variables: map[string]string{"client_name": "Acme"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to resolve the rules: %v", err)
+		}
+		assert.Equal(t, 2, len(rules))
+		assert.Equal(t, "Acme Payroll", rules[0].PageCondition.SheetName)
+		assert.Equal(t, "Sheet2", rules[1].PageCondition.SheetName)
+	})
+
+	t.Run("report an invalid rule file", func(t *testing.T) {
+		_, err := loadRuleFile(&types.RuleFile{Content: "- {pageCondition: {sheetName: '${client_name}'}}"})
+		assert.Equal(t, true, errors.Is(err, rulefile.ErrInvalid))
+
+		_, err = loadRuleFile(&types.RuleFile{})
+		assert.Equal(t, true, errors.Is(err, rulefile.ErrInvalid))
+	})
+
+	t.Run("rules or a rule file are required", func(t *testing.T) {
+		c, _ := setupGinContextTransform(types.RequestBodyTransform{
+			Input:    createMockInputTransform("input-bucket", "input/test.json"),
+			Output:   createMockOutputTransform("output-bucket", "output/transformed.json"),
+			RuleFile: &types.RuleFile{Content: "[]"},
+		})
+		var parsedRequest types.RequestBodyTransform
+		assert.Equal(t, nil, bindAndValidate(c, &parsedRequest))
+	})
+}
@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"xlsx-processor/pkg/profile"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kelseyhightower/envconfig"
+)
+
+/*
+	Validate the env variables
+*/
+type RuleProfilesEnv struct {
+	RuleProfilesDir string `envconfig:"RULE_PROFILES_DIR" default:"data/rule-profiles"`
+}
+
+var ruleProfilesEnv RuleProfilesEnv
+
+var ruleProfiles *profile.Store
+
+func init() {
+	if err := envconfig.Process("", &ruleProfilesEnv); err != nil {
+		panic(err)
+	}
+	ruleProfiles = profile.MakeStore(ruleProfilesEnv.RuleProfilesDir)
+}
+
+// sendProfileError responds 404 for the profiles and versions that do not exist
+func sendProfileError(c *gin.Context, err error) {
+	if errors.Is(err, profile.ErrNotFound) {
+		sendError(c, http.StatusNotFound, err, nil)
+		return
+	}
+	sendError(c, http.StatusInternalServerError, err, nil)
+}
+
+// bindRuleProfile reads a profile from the request and validates its rules, it responds on failure
+func bindRuleProfile(c *gin.Context) (*types.RequestBodyRuleProfile, bool) {
+	var requestData types.RequestBodyRuleProfile
+	err := bindAndValidate(c, &requestData)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, err, nil)
+		return nil, false
+	}
+	validation := transform.ValidateRules(requestData.Rules, nil)
+	if !validation.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "the rules are invalid", "validation": validation})
+		return nil, false
+	}
+	return &requestData, true
+}
+
+// CreateRuleProfile stores the first version of a profile
+func CreateRuleProfile(c *gin.Context) {
+	requestData, ok := bindRuleProfile(c)
+	if !ok {
+		return
+	}
+	ruleProfile, err := ruleProfiles.Create(*requestData)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, nil)
+		return
+	}
+	c.JSON(http.StatusCreated, ruleProfile)
+}
+
+// UpdateRuleProfile stores a new version of a profile
+func UpdateRuleProfile(c *gin.Context) {
+	requestData, ok := bindRuleProfile(c)
+	if !ok {
+		return
+	}
+	ruleProfile, err := ruleProfiles.Update(c.Param("id"), *requestData)
+	if err != nil {
+		sendProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ruleProfile)
+}
+
+// ListRuleProfiles returns the latest version of every profile
+func ListRuleProfiles(c *gin.Context) {
+	profiles, err := ruleProfiles.List()
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetRuleProfile returns a profile, the version query parameter selects a version
+func GetRuleProfile(c *gin.Context) {
+	version := 0
+	if query := c.Query("version"); query != "" {
+		var err error
+		version, err = strconv.Atoi(query)
+		if err != nil || version < 1 {
+			sendError(c, http.StatusBadRequest, fmt.Errorf("'%s' is not a valid version", query), nil)
+			return
+		}
+	}
+	ruleProfile, err := ruleProfiles.Get(c.Param("id"), version)
+	if err != nil {
+		sendProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ruleProfile)
+}
+
+// GetRuleProfileVersions returns every version of a profile
+func GetRuleProfileVersions(c *gin.Context) {
+	versions, err := ruleProfiles.Versions(c.Param("id"))
+	if err != nil {
+		sendProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// DeleteRuleProfile removes a profile along with its versions
+func DeleteRuleProfile(c *gin.Context) {
+	if err := ruleProfiles.Delete(c.Param("id")); err != nil {
+		sendProfileError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// resolveRules puts the rules of the requested profile first, then the rules of the rule file and
+// the inline rules, rule indexes in errors and in the manifest refer to the combined rules
+func resolveRules(requestData types.RequestBodyTransform) ([]types.Rule, *types.AppliedProfile, error) {
+	var profileRules []types.Rule
+	var appliedProfile *types.AppliedProfile
+	if requestData.RuleProfileId != "" {
+		ruleProfile, err := ruleProfiles.Get(requestData.RuleProfileId, requestData.RuleProfileVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		profileRules = ruleProfile.Rules
+		appliedProfile = &types.AppliedProfile{ID: ruleProfile.ID, Name: ruleProfile.Name, Version: ruleProfile.Version}
+	}
+	fileRules, err := loadRuleFile(requestData.RuleFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slices.Concat(profileRules, fileRules, requestData.Rules), appliedProfile, nil
+}
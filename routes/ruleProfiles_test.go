@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"xlsx-processor/pkg/profile"
+	"xlsx-processor/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+// newProfilesRouter serves the rule profile routes from a temporary store
+func newProfilesRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ruleProfiles = profile.MakeStore(t.TempDir())
+
+	router := gin.New()
+	router.POST("/rule-profiles", CreateRuleProfile)
+	router.GET("/rule-profiles", ListRuleProfiles)
+	router.GET("/rule-profiles/:id", GetRuleProfile)
+	router.GET("/rule-profiles/:id/versions", GetRuleProfileVersions)
+	router.PUT("/rule-profiles/:id", UpdateRuleProfile)
+	router.DELETE("/rule-profiles/:id", DeleteRuleProfile)
+	return router
+}
+
+func serve(router *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	jsonBytes, _ := json.Marshal(body)
+	request := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBytes))
+	request.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, request)
+	return w
+}
+
+func TestRuleProfiles(t *testing.T) {
+	router := newProfilesRouter(t)
+	rules := []types.Rule{
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet1"},
+			Actions:       []types.Action{{Operation: "VALUE", Value: "sensitive", ActionType: "REDACT"}},
+		},
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet2"},
+			Actions:       []types.Action{{Operation: "COLUMN", Value: "C", ActionType: "EXCLUDE"}},
+		},
+	}
+
+	w := serve(router, "POST", "/rule-profiles", types.RequestBodyRuleProfile{Name: "Payroll", Rules: rules})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created types.RuleProfile
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = serve(router, "PUT", "/rule-profiles/"+created.ID, types.RequestBodyRuleProfile{Name: "Payroll", Rules: rules[:1]})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("get a version", func(t *testing.T) {
+		w := serve(router, "GET", "/rule-profiles/"+created.ID+"?version=1", nil)
+		var ruleProfile types.RuleProfile
+		json.Unmarshal(w.Body.Bytes(), &ruleProfile)
+		assert.Equal(t, len(rules), len(ruleProfile.Rules))
+
+		assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/rule-profiles/"+created.ID+"?version=3", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serve(router, "GET", "/rule-profiles/"+created.ID+"?version=x", nil).Code)
+	})
+
+	t.Run("reject invalid rules", func(t *testing.T) {
+		invalidRules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1"},
+			Actions:       []types.Action{{Operation: "RANGES", Value: "A1:B2", ActionType: "REDACT"}},
+		}}
+		w := serve(router, "POST", "/rule-profiles", types.RequestBodyRuleProfile{Name: "Broken", Rules: invalidRules})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("combine the profile with the inline rules", func(t *testing.T) {
+		combined, appliedProfile, err := resolveRules(types.RequestBodyTransform{
+			Rules:              rules,
+			RuleProfileId:      created.ID,
+			RuleProfileVersion: 2,
+		})
+		if err != nil {
+			t.Fatalf("failed to resolve the rules: %v", err)
+		}
+		assert.Equal(t, 1+len(rules), len(combined))
+		assert.Equal(t, &types.AppliedProfile{ID: created.ID, Name: "Payroll", Version: 2}, appliedProfile)
+	})
+
+	t.Run("rules or a profile are required", func(t *testing.T) {
+		c, _ := setupGinContextTransform(types.RequestBodyTransform{
+			Input:         createMockInputTransform("input-bucket", "input/test.json"),
+			Output:        createMockOutputTransform("output-bucket", "output/transformed.json"),
+			RuleProfileId: created.ID,
+		})
+		var parsedRequest types.RequestBodyTransform
+		assert.Equal(t, nil, bindAndValidate(c, &parsedRequest))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(router, "DELETE", "/rule-profiles/"+created.ID, nil).Code)
+		assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/rule-profiles/"+created.ID+"/versions", nil).Code)
+	})
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"net/http"
+
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/scan"
+	"xlsx-processor/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+Scan reports the sensitive values of a workbook and drafts the rules redacting them, the file is
+left as it is
+*/
+func Scan(c *gin.Context) {
+	/*
+		Request Body
+	*/
+	var requestData types.RequestBodyScan
+	err := bindAndValidate(c, &requestData)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	input := requestData.Input
+
+	/*
+		Downloading the file from the input storage type
+	*/
+	f, err := storage.GetFile(input.StorageType, input)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, nil)
+		return
+	}
+	defer f.Close()
+
+	/*
+		Scanning the workbook
+	*/
+	scanner := scan.MakeScanner(f, &requestData)
+	result, err := scanner.Execute()
+	if err != nil {
+		sendError(c, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,9 +1,15 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
+	"path"
 
+	"xlsx-processor/pkg/profile"
+	"xlsx-processor/pkg/rulefile"
 	"xlsx-processor/pkg/types"
+	"xlsx-processor/pkg/verify"
+	"xlsx-processor/sanitize"
 	"xlsx-processor/storage"
 	"xlsx-processor/transform"
 
@@ -21,11 +27,23 @@ func Transform(c *gin.Context) {
 		return
 	}
 
-	rules := requestData.Rules
 	input := requestData.Input
 	output := requestData.Output
 	webhook := requestData.Webhook
 
+	/*
+		Combining the rules of the profile and the rule file with the inline rules
+	*/
+	rules, appliedProfile, err := resolveRules(requestData)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, profile.ErrNotFound) || errors.Is(err, rulefile.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		sendError(c, status, err, webhook)
+		return
+	}
+
 	/*
 		Downloading the file from the input storage type
 	*/
@@ -39,11 +57,33 @@ func Transform(c *gin.Context) {
 		Executing the rules
 	*/
 	rulesExecutor := transform.MakeRulesExecutor(f, rules)
+	rulesExecutor.Manifest.Profile = appliedProfile
+	rulesExecutor.FileName = path.Base(input.Reference.Prefix)
+	rulesExecutor.RemoveExcludedPictures = sanitize.RemovesExcludedPictures(requestData.Sanitize)
 	transformErr := rulesExecutor.Execute()
 	if transformErr != nil {
 		sendTransformError(c, http.StatusInternalServerError, transformErr, webhook)
 		return
 	}
+	// Renaming a sheet reopens the workbook
+	f = rulesExecutor.File
+
+	/*
+		Sanitizing the workbook
+	*/
+	sanitizer := sanitize.MakeSanitizer(f, requestData.Sanitize)
+	sanitizer.Manifest = rulesExecutor.Manifest
+	err = sanitizer.Execute()
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, webhook)
+		return
+	}
+	f = sanitizer.File
+	// The hashes of the originals let /verify check the output from the manifest alone
+	if err = verify.SealManifest(rulesExecutor.Manifest); err != nil {
+		sendError(c, http.StatusInternalServerError, err, webhook)
+		return
+	}
 
 	/*
 		Storing the file in the output storage type
@@ -53,6 +93,6 @@ func Transform(c *gin.Context) {
 		sendError(c, http.StatusInternalServerError, err, webhook)
 		return
 	}
-	c.JSON(http.StatusAccepted, gin.H{"message": "File transformed successfully"})
+	c.JSON(http.StatusAccepted, gin.H{"message": "File transformed successfully", "manifest": rulesExecutor.Manifest, "sanitizeReport": sanitizer.Report})
 	return
 }
\ No newline at end of file
@@ -2,9 +2,13 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"strings"
+	"xlsx-processor/pkg/profile"
+	"xlsx-processor/pkg/rulefile"
 	"xlsx-processor/pkg/sheet"
 	"xlsx-processor/pkg/types"
 	"xlsx-processor/storage"
@@ -27,11 +31,23 @@ func TransformJson(c *gin.Context) {
 		return
 	}
 
-	rules := requestData.Rules
 	input := requestData.Input
 	output := requestData.Output
 	webhook := requestData.Webhook
 
+	/*
+		Combining the rules of the profile and the rule file with the inline rules
+	*/
+	rules, appliedProfile, err := resolveRules(requestData)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, profile.ErrNotFound) || errors.Is(err, rulefile.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		sendError(c, status, err, webhook)
+		return
+	}
+
 	/*
 		Check if the file is a JSON file
 	*/
@@ -53,11 +69,15 @@ func TransformJson(c *gin.Context) {
 		Executing the rules
 	*/
 	rulesExecutor := transform.MakeRulesExecutor(f, rules)
+	rulesExecutor.Manifest.Profile = appliedProfile
+	rulesExecutor.FileName = path.Base(input.Reference.Prefix)
 	transformErr := rulesExecutor.Execute()
 	if transformErr != nil {
 		sendTransformError(c, http.StatusInternalServerError, transformErr, webhook)
 		return
 	}
+	// Renaming a sheet reopens the workbook
+	f = rulesExecutor.File
 
 	sheetContent, err := sheet.ParseSheetToCsv(f, nil)
 	if err != nil {
@@ -78,6 +98,6 @@ func TransformJson(c *gin.Context) {
 		sendError(c, http.StatusInternalServerError, err, webhook)
 		return
 	}
-	c.JSON(http.StatusAccepted, gin.H{"message": "File transformed successfully"})
+	c.JSON(http.StatusAccepted, gin.H{"message": "File transformed successfully", "manifest": rulesExecutor.Manifest})
 	return
 }
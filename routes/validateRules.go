@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"net/http"
+
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/storage"
+	"xlsx-processor/transform"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+/*
+ValidateRules checks the rules without running them, the sheets are checked against the workbook
+when an input is given
+*/
+func ValidateRules(c *gin.Context) {
+	/*
+		Request Body
+	*/
+	var requestData types.RequestBodyValidateRules
+	err := bindAndValidate(c, &requestData)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	/*
+		Downloading the file from the input storage type
+	*/
+	var f *excelize.File
+	if input := requestData.Input; input != nil {
+		f, err = storage.GetFile(input.StorageType, *input)
+		if err != nil {
+			sendError(c, http.StatusInternalServerError, err, nil)
+			return
+		}
+		defer f.Close()
+	}
+
+	c.JSON(http.StatusOK, transform.ValidateRules(requestData.Rules, f))
+}
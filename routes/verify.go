@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/pkg/verify"
+	"xlsx-processor/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+Verify scans an output workbook for sensitive values and checks the cells of a redaction manifest,
+the verdict is signed when VERIFY_SIGNING_KEY is set
+*/
+func Verify(c *gin.Context) {
+	/*
+		Request Body
+	*/
+	var requestData types.RequestBodyVerify
+	err := bindAndValidate(c, &requestData)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, err, nil)
+		return
+	}
+	if len(requestData.Values) == 0 && requestData.Manifest == nil {
+		sendError(c, http.StatusBadRequest, fmt.Errorf("either values or a manifest is required"), nil)
+		return
+	}
+
+	input := requestData.Input
+
+	/*
+		Downloading the file from the input storage type
+	*/
+	fileBytes, err := storage.GetFileBytes(input.StorageType, input)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, nil)
+		return
+	}
+
+	/*
+		Verifying the workbook
+	*/
+	result, err := verify.Workbook(fileBytes, requestData.Values, requestData.CaseInsensitive, requestData.Manifest)
+	if err != nil {
+		sendError(c, http.StatusInternalServerError, err, nil)
+		return
+	}
+	if signingKey := os.Getenv("VERIFY_SIGNING_KEY"); signingKey != "" {
+		result.Signature = verify.Sign(result, []byte(signingKey))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,122 @@
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Constants for the cache policies, along with REMOVE
+const (
+	CLEAR   string = "CLEAR"
+	REFRESH string = "REFRESH"
+	STATIC  string = "STATIC"
+)
+
+// Constants for the types of scrubbed caches
+const (
+	chartCache      string = "chart"
+	pivotTableCache string = "pivotTable"
+)
+
+// position is a cell numbered as in the original workbook, which is how the chart and pivot
+// caches still refer to it since excelize does not update them when lines are removed
+type position struct {
+	col int
+	row int
+}
+
+// validateCachePolicies checks the cache policies before anything is changed
+func (s *Sanitizer) validateCachePolicies() error {
+	chartPolicy := strings.ToUpper(s.options.ChartCaches)
+	if chartPolicy != "" && chartPolicy != CLEAR && chartPolicy != REFRESH && chartPolicy != REMOVE {
+		return fmt.Errorf("'%s' is not a valid chart caches policy", s.options.ChartCaches)
+	}
+	pivotPolicy := strings.ToUpper(s.options.PivotCaches)
+	if pivotPolicy != "" && pivotPolicy != REFRESH && pivotPolicy != STATIC && pivotPolicy != REMOVE {
+		return fmt.Errorf("'%s' is not a valid pivot caches policy", s.options.PivotCaches)
+	}
+	return nil
+}
+
+// loadRedactedPositions collects the redacted cells of every sheet, both the ones recorded
+// in the manifest and the ones only known by their content
+func (s *Sanitizer) loadRedactedPositions() error {
+	s.redactedPositions = map[string][]position{}
+	if s.Manifest != nil {
+		for _, redactedCell := range s.Manifest.RedactedCells {
+			if redactedCell.Excluded {
+				continue
+			}
+			col, row, err := excelize.CellNameToCoordinates(redactedCell.Cell)
+			if err != nil {
+				return err
+			}
+			s.addRedactedPosition(redactedCell.SheetName, col, row)
+		}
+	}
+
+	for _, sheetName := range s.File.GetSheetList() {
+		rows, err := s.File.GetRows(sheetName, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return err
+		}
+		for rowIndex, row := range rows {
+			for colIndex, value := range row {
+				if value == "**redacted**" {
+					s.addRedactedPosition(sheetName, colIndex+1, rowIndex+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addRedactedPosition records a redacted cell of the transformed sheet in original coordinates
+func (s *Sanitizer) addRedactedPosition(sheetName string, col, row int) {
+	originalCol, originalRow := transform.OriginalCoordinates(s.Manifest, sheetName, col, row)
+	s.redactedPositions[sheetName] = append(s.redactedPositions[sheetName], position{originalCol, originalRow})
+}
+
+// duplicatesRedactedData reports whether one of the areas holds a redacted cell or crosses
+// an excluded row, column or sheet
+func (s *Sanitizer) duplicatesRedactedData(references []formula.Reference) bool {
+	for _, reference := range references {
+		for _, redactedPosition := range s.redactedPositions[reference.SheetName] {
+			if reference.Contains(reference.SheetName, redactedPosition.col, redactedPosition.row) {
+				return true
+			}
+		}
+
+		if s.Manifest == nil {
+			continue
+		}
+		for _, exclusion := range s.Manifest.Exclusions {
+			if exclusion.SheetName != reference.SheetName {
+				continue
+			}
+			wholeSheet := exclusion.Row == 0 && exclusion.Column == 0
+			crossesRow := exclusion.Row >= reference.StartRow && exclusion.Row <= reference.EndRow
+			crossesColumn := exclusion.Column >= reference.StartCol && exclusion.Column <= reference.EndCol
+			if wholeSheet || crossesRow || crossesColumn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addScrubbedCache records a scrubbed chart or pivot table in the report
+func (s *Sanitizer) addScrubbedCache(cacheType, sheetName, name, policy string) {
+	s.Report.ScrubbedCaches = append(s.Report.ScrubbedCaches, types.ScrubbedCache{
+		Type:      cacheType,
+		SheetName: sheetName,
+		Name:      name,
+		Action:    strings.ToLower(policy),
+	})
+}
@@ -0,0 +1,203 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newCachedFile creates a workbook with salaries on A1:B4, a chart plotting them with cached
+// values like Excel saves them and a pivot table summing them on Sheet2
+func newCachedFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]any{"Name", "Salary"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{"Jane", 5000})
+	f.SetSheetRow("Sheet1", "A3", &[]any{"John", 6000})
+	f.SetSheetRow("Sheet1", "A4", &[]any{"Mary", 7000})
+	err := f.AddChart("Sheet1", "D1", &excelize.Chart{
+		Type:   excelize.Col,
+		Series: []excelize.ChartSeries{{Name: "Sheet1!$B$1", Categories: "Sheet1!$A$2:$A$4", Values: "Sheet1!$B$2:$B$4"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to add the chart: %v", err)
+	}
+
+	f.NewSheet("Sheet2")
+	f.SetSheetRow("Sheet2", "A1", &[]any{"Name", "Sum of Salary"})
+	f.SetSheetRow("Sheet2", "A2", &[]any{"Jane", 5000})
+	err = f.AddPivotTable(&excelize.PivotTableOptions{
+		Name:            "Salaries",
+		DataRange:       "Sheet1!A1:B4",
+		PivotTableRange: "Sheet2!A1:B5",
+		Rows:            []excelize.PivotTableField{{Data: "Name"}},
+		Data:            []excelize.PivotTableField{{Data: "Salary", Subtotal: "Sum"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to add the pivot table: %v", err)
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+	// excelize writes the chart in the default namespace
+	chart, _ := p.Part("xl/charts/chart1.xml")
+	content := strings.Replace(string(chart), "<f>Sheet1!$B$2:$B$4</f>",
+		`<f>Sheet1!$B$2:$B$4</f><numCache><formatCode>General</formatCode><ptCount val="3"/><pt idx="0"><v>5000</v></pt><pt idx="1"><v>6000</v></pt><pt idx="2"><v>7000</v></pt></numCache>`, 1)
+	content = strings.Replace(content, "<f>Sheet1!$A$2:$A$4</f>",
+		`<f>Sheet1!$A$2:$A$4</f><strCache><ptCount val="3"/><pt idx="0"><v>Jane</v></pt><pt idx="1"><v>John</v></pt><pt idx="2"><v>Mary</v></pt></strCache>`, 1)
+	p.SetPart("xl/charts/chart1.xml", []byte(content))
+
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	f, err = file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f
+}
+
+// readPart returns a part of the sanitized workbook
+func readPart(t *testing.T, f *excelize.File, name string) string {
+	data, _ := readPackage(t, f).Part(name)
+	return string(data)
+}
+
+func TestCaches(t *testing.T) {
+	// John's salary is redacted
+	redactedManifest := func(f *excelize.File) *types.Manifest {
+		f.SetCellValue("Sheet1", "B3", "**redacted**")
+		return &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "B3"}}}
+	}
+	// John's row is excluded
+	excludedManifest := func(f *excelize.File) *types.Manifest {
+		manifest := &types.Manifest{}
+		f.RemoveRow("Sheet1", 3)
+		transform.ShiftRedactedCells(manifest, "Sheet1", 0, 3)
+		return manifest
+	}
+
+	t.Run("untouched caches are kept", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newCachedFile(t), &types.Sanitize{ChartCaches: "clear", PivotCaches: "static"})
+		sanitizer.Manifest = &types.Manifest{}
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 0, len(sanitizer.Report.ScrubbedCaches))
+		assert.Equal(t, true, strings.Contains(readPart(t, sanitizer.File, "xl/charts/chart1.xml"), "<v>6000</v>"))
+	})
+
+	t.Run("clear the cached values of a series plotting a redacted cell", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{ChartCaches: "clear"})
+		sanitizer.Manifest = redactedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		chart := readPart(t, sanitizer.File, "xl/charts/chart1.xml")
+		assert.Equal(t, false, strings.Contains(chart, "<numCache>"))
+		// The categories do not plot a redacted cell
+		assert.Equal(t, true, strings.Contains(chart, "<v>John</v>"))
+		assert.Equal(t, []types.ScrubbedCache{{Type: "chart", SheetName: "Sheet1", Name: "xl/charts/chart1.xml", Action: "clear"}}, sanitizer.Report.ScrubbedCaches)
+	})
+
+	t.Run("refresh the cached values without an excluded row", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{ChartCaches: "refresh"})
+		sanitizer.Manifest = excludedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		chart := readPart(t, sanitizer.File, "xl/charts/chart1.xml")
+		assert.Equal(t, true, strings.Contains(chart, `<pt idx="0"><v>5000</v></pt><pt idx="2"><v>7000</v></pt>`))
+		assert.Equal(t, true, strings.Contains(chart, `<pt idx="0"><v>Jane</v></pt><pt idx="2"><v>Mary</v></pt>`))
+		assert.Equal(t, false, strings.Contains(chart, "<v>6000</v>"))
+		assert.Equal(t, false, strings.Contains(chart, "<v>John</v>"))
+	})
+
+	t.Run("remove a chart plotting a redacted cell", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{ChartCaches: "remove"})
+		sanitizer.Manifest = redactedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		_, found := p.Part("xl/charts/chart1.xml")
+		assert.Equal(t, false, found)
+		drawing, _ := p.Part("xl/drawings/drawing1.xml")
+		assert.Equal(t, false, strings.Contains(string(drawing), "graphicFrame"))
+	})
+
+	t.Run("refresh a pivot cache on load", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{PivotCaches: "refresh"})
+		sanitizer.Manifest = redactedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		cache := readPart(t, sanitizer.File, "xl/pivotCache/pivotCacheDefinition1.xml")
+		assert.Equal(t, true, strings.Contains(cache, `refreshOnLoad="1"`))
+		assert.Equal(t, []types.ScrubbedCache{{Type: "pivotTable", SheetName: "Sheet2", Name: "Salaries", Action: "refresh"}}, sanitizer.Report.ScrubbedCaches)
+		// The rendered values are rebuilt from the refreshed cache
+		value, _ := sanitizer.File.GetCellValue("Sheet2", "B2")
+		assert.Equal(t, "", value)
+	})
+
+	t.Run("convert a pivot table to static values", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{PivotCaches: "static"})
+		sanitizer.Manifest = excludedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		for _, name := range p.Parts() {
+			assert.Equal(t, false, strings.HasPrefix(name, "xl/pivotTables/") || strings.HasPrefix(name, "xl/pivotCache/"))
+		}
+		workbook, _ := p.Part("xl/workbook.xml")
+		assert.Equal(t, false, strings.Contains(string(workbook), "pivotCache"))
+		value, _ := sanitizer.File.GetCellValue("Sheet2", "B2")
+		assert.Equal(t, "5000", value)
+		assert.Equal(t, []types.ScrubbedCache{{Type: "pivotTable", SheetName: "Sheet2", Name: "Salaries", Action: "static", Range: "A1:B5"}}, sanitizer.Report.ScrubbedCaches)
+	})
+
+	t.Run("remove a pivot table and its values", func(t *testing.T) {
+		f := newCachedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{PivotCaches: "remove"})
+		sanitizer.Manifest = redactedManifest(f)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		_, found := readPackage(t, sanitizer.File).Part("xl/pivotTables/pivotTable1.xml")
+		assert.Equal(t, false, found)
+		value, _ := sanitizer.File.GetCellValue("Sheet2", "B2")
+		assert.Equal(t, "", value)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newCachedFile(t), &types.Sanitize{PivotCaches: "clear"})
+		err := sanitizer.Execute()
+		assert.Equal(t, "'clear' is not a valid pivot caches policy", err.Error())
+	})
+}
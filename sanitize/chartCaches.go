@@ -0,0 +1,185 @@
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/transform"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Charts are edited textually like the drawings, the data references of a series never nest.
+// Excel writes the chart elements with the c: prefix while excelize uses the default namespace
+var (
+	chartPartPattern    = regexp.MustCompile(`^xl/charts/chart\d+\.xml$`)
+	chartDataPattern    = regexp.MustCompile(`(?s)<(\w+:)?(?:numRef|strRef|multiLvlStrRef)>.*?</(?:\w+:)?(?:numRef|strRef|multiLvlStrRef)>`)
+	chartFormulaPattern = regexp.MustCompile(`(?s)<(?:\w+:)?f>(.*?)</(?:\w+:)?f>`)
+	chartCachePattern   = regexp.MustCompile(`(?s)<(?:\w+:)?(?:numCache|strCache|multiLvlStrCache)>.*?</(?:\w+:)?(?:numCache|strCache|multiLvlStrCache)>`)
+	formatCodePattern   = regexp.MustCompile(`(?s)<(?:\w+:)?formatCode>.*?</(?:\w+:)?formatCode>`)
+)
+
+// scrubChartCaches applies the chart caches policy to every chart plotting redacted or excluded cells
+func (s *Sanitizer) scrubChartCaches(p *ooxml.Package) error {
+	policy := strings.ToUpper(s.options.ChartCaches)
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range p.Parts() {
+		if !chartPartPattern.MatchString(name) {
+			continue
+		}
+		data, _ := p.Part(name)
+
+		scrubbed := false
+		content := chartDataPattern.ReplaceAllStringFunc(string(data), func(chartData string) string {
+			match := chartFormulaPattern.FindStringSubmatch(chartData)
+			if match == nil {
+				return chartData
+			}
+			references := formula.GetReferences(s.File, "", html.UnescapeString(match[1]))
+			if !s.duplicatesRedactedData(references) {
+				return chartData
+			}
+
+			scrubbed = true
+			if policy == REFRESH {
+				return s.refreshChartCache(chartData, references)
+			}
+			return chartCachePattern.ReplaceAllString(chartData, "")
+		})
+		if !scrubbed {
+			continue
+		}
+
+		sheetName, err := chartSheetName(p, name, sheetNames)
+		if err != nil {
+			return err
+		}
+		if policy == REMOVE {
+			if err = removeChart(p, name); err != nil {
+				return err
+			}
+		} else {
+			p.SetPart(name, []byte(content))
+		}
+		s.addScrubbedCache(chartCache, sheetName, name, policy)
+	}
+
+	return nil
+}
+
+// refreshChartCache rebuilds the cached values of a series from the transformed cells. Cells
+// that were excluded or hold no value are left out, which charts show as gaps. Areas that
+// cannot be read back point by point have their cache cleared instead
+func (s *Sanitizer) refreshChartCache(chartData string, references []formula.Reference) string {
+	prefix := chartDataPattern.FindStringSubmatch(chartData)[1]
+	isNumeric := strings.HasPrefix(chartData, "<"+prefix+"numRef>")
+	if len(references) != 1 || (!isNumeric && !strings.HasPrefix(chartData, "<"+prefix+"strRef>")) {
+		return chartCachePattern.ReplaceAllString(chartData, "")
+	}
+	reference := references[0]
+	if reference.EndRow == excelize.TotalRows || reference.EndCol == excelize.MaxColumns {
+		return chartCachePattern.ReplaceAllString(chartData, "")
+	}
+
+	var points strings.Builder
+	pointCount := 0
+	for row := reference.StartRow; row <= reference.EndRow; row++ {
+		for col := reference.StartCol; col <= reference.EndCol; col++ {
+			index := pointCount
+			pointCount++
+
+			currentCol, currentRow, excluded := transform.CurrentCoordinates(s.Manifest, reference.SheetName, col, row)
+			if excluded {
+				continue
+			}
+			cellName, err := excelize.CoordinatesToCellName(currentCol, currentRow)
+			if err != nil {
+				continue
+			}
+			value, err := s.File.GetCellValue(reference.SheetName, cellName, excelize.Options{RawCellValue: true})
+			if err != nil || value == "" {
+				continue
+			}
+			if _, err = strconv.ParseFloat(value, 64); isNumeric && err != nil {
+				continue
+			}
+			fmt.Fprintf(&points, `<%[1]spt idx="%[2]d"><%[1]sv>%[3]s</%[1]sv></%[1]spt>`, prefix, index, html.EscapeString(value))
+		}
+	}
+
+	var cache string
+	if isNumeric {
+		formatCode := formatCodePattern.FindString(chartData)
+		if formatCode == "" {
+			formatCode = "<" + prefix + "formatCode>General</" + prefix + "formatCode>"
+		}
+		cache = fmt.Sprintf(`<%[1]snumCache>%[2]s<%[1]sptCount val="%[3]d"/>%[4]s</%[1]snumCache>`, prefix, formatCode, pointCount, points.String())
+	} else {
+		cache = fmt.Sprintf(`<%[1]sstrCache><%[1]sptCount val="%[2]d"/>%[3]s</%[1]sstrCache>`, prefix, pointCount, points.String())
+	}
+
+	if chartCachePattern.MatchString(chartData) {
+		return chartCachePattern.ReplaceAllLiteralString(chartData, cache)
+	}
+	closingTag := strings.LastIndex(chartData, "</")
+	return chartData[:closingTag] + cache + chartData[closingTag:]
+}
+
+// chartSheetName returns the sheet showing a chart through its drawing, empty when it is not found
+func chartSheetName(p *ooxml.Package, chartName string, sheetNames map[string]string) (string, error) {
+	drawings, err := p.ReferencesTo(chartName)
+	if err != nil {
+		return "", err
+	}
+	for _, drawing := range drawings {
+		sheets, err := p.ReferencesTo(drawing.Source)
+		if err != nil {
+			return "", err
+		}
+		for _, sheet := range sheets {
+			if sheetName, found := sheetNames[sheet.Source]; found {
+				return sheetName, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// removeChart removes the frames showing a chart from the drawings, then the chart along
+// with its style and colors parts
+func removeChart(p *ooxml.Package, chartName string) error {
+	drawings, err := p.ReferencesTo(chartName)
+	if err != nil {
+		return err
+	}
+	for _, drawing := range drawings {
+		data, _ := p.Part(drawing.Source)
+		content := anchorPattern.ReplaceAllStringFunc(string(data), func(anchor string) string {
+			if strings.Contains(anchor, "<xdr:graphicFrame") && strings.Contains(anchor, `r:id="`+drawing.ID+`"`) {
+				return ""
+			}
+			return anchor
+		})
+		p.SetPart(drawing.Source, []byte(content))
+	}
+
+	relatedParts, err := p.RelatedParts(chartName, "")
+	if err != nil {
+		return err
+	}
+	for _, relatedPart := range relatedParts {
+		if err = p.RemovePart(relatedPart); err != nil {
+			return err
+		}
+	}
+	return p.RemovePart(chartName)
+}
@@ -0,0 +1,106 @@
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	docPropsCorePath = "docProps/core.xml"
+	docPropsAppPath  = "docProps/app.xml"
+)
+
+// excelize does not read nor write the manager, it is edited textually
+var managerPattern = regexp.MustCompile(`(?s)<Manager\s*/>|<Manager>.*?</Manager>`)
+
+// clearDocProps rewrites the core and app properties with only the non identifying fields.
+// excelize skips empty values when setting properties, so the parts are dropped first
+func (s *Sanitizer) clearDocProps() error {
+	f := s.File
+
+	if _, exists := f.Pkg.Load(docPropsCorePath); exists {
+		docProps, err := f.GetDocProps()
+		if err != nil {
+			return err
+		}
+		f.Pkg.Delete(docPropsCorePath)
+		err = f.SetDocProps(&excelize.DocProperties{
+			Created:  docProps.Created,
+			Modified: docProps.Modified,
+			Language: docProps.Language,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// The manager, the company and the titles of the parts go away with the app properties
+	if _, exists := f.Pkg.Load(docPropsAppPath); exists {
+		appProps, err := f.GetAppProps()
+		if err != nil {
+			return err
+		}
+		f.Pkg.Delete(docPropsAppPath)
+		appProps.Company = ""
+		err = f.SetAppProps(appProps)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// overwriteDocProps sets the document properties given in the options
+func (s *Sanitizer) overwriteDocProps() error {
+	f := s.File
+	docProps := s.options.DocProps
+
+	err := f.SetDocProps(&excelize.DocProperties{
+		Creator:        docProps.Creator,
+		LastModifiedBy: docProps.LastModifiedBy,
+		Title:          docProps.Title,
+		Subject:        docProps.Subject,
+		Description:    docProps.Description,
+		Keywords:       docProps.Keywords,
+		Category:       docProps.Category,
+	})
+	if err != nil {
+		return err
+	}
+
+	if docProps.Company != "" || docProps.Manager != "" {
+		appProps, err := f.GetAppProps()
+		if err != nil {
+			return err
+		}
+		if docProps.Company != "" {
+			appProps.Company = docProps.Company
+		}
+		err = f.SetAppProps(appProps)
+		if err != nil {
+			return err
+		}
+	}
+	if docProps.Manager != "" {
+		setManager(f, docProps.Manager)
+	}
+
+	return nil
+}
+
+// setManager writes the manager to the app properties, replacing the one they hold
+func setManager(f *excelize.File, manager string) {
+	data, _ := f.Pkg.Load(docPropsAppPath)
+	content, _ := data.([]byte)
+	element := "<Manager>" + html.EscapeString(manager) + "</Manager>"
+	if managerPattern.Match(content) {
+		content = managerPattern.ReplaceAllLiteral(content, []byte(element))
+	} else {
+		content = []byte(strings.Replace(string(content), "</Properties>", element+"</Properties>", 1))
+	}
+	f.Pkg.Store(docPropsAppPath, content)
+}
@@ -0,0 +1,213 @@
+package sanitize
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newDrawingFile creates a workbook with pictures on A1, B3 and C5 and a text box on E1
+func newDrawingFile(t *testing.T) *excelize.File {
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("failed to encode the picture: %v", err)
+	}
+
+	f := excelize.NewFile()
+	for rowNum := 1; rowNum <= 5; rowNum++ {
+		f.SetSheetRow("Sheet1", "A"+string(rune('0'+rowNum)), &[]string{"a", "b", "c"})
+	}
+	for _, cellName := range []string{"A1", "B3", "C5"} {
+		err := f.AddPictureFromBytes("Sheet1", cellName, &excelize.Picture{Extension: ".png", File: picture.Bytes(), Format: &excelize.GraphicOptions{}})
+		if err != nil {
+			t.Fatalf("failed to add the picture: %v", err)
+		}
+	}
+	err := f.AddShape("Sheet1", &excelize.Shape{Cell: "E1", Type: "rect", Paragraph: []excelize.RichTextRun{{Text: "Signed by John Doe, SSN 111-22-3333"}}})
+	if err != nil {
+		t.Fatalf("failed to add the shape: %v", err)
+	}
+	return f
+}
+
+// editDrawing rewrites the drawing of a workbook, for the cases excelize cannot write
+func editDrawing(t *testing.T, f *excelize.File, edit func(drawing string) string) *excelize.File {
+	p := readPackage(t, f)
+	drawing, _ := p.Part("xl/drawings/drawing1.xml")
+	p.SetPart("xl/drawings/drawing1.xml", []byte(edit(string(drawing))))
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	edited, err := file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return edited
+}
+
+// newGroupFile groups the picture on A1 with a caption
+func newGroupFile(t *testing.T) *excelize.File {
+	return editDrawing(t, newDrawingFile(t), func(drawing string) string {
+		start := strings.Index(drawing, "<xdr:pic>")
+		end := strings.Index(drawing, "</xdr:pic>") + len("</xdr:pic>")
+		return drawing[:start] + `<xdr:grpSp><xdr:nvGrpSpPr><xdr:cNvPr id="10" name="Signature"/><xdr:cNvGrpSpPr/></xdr:nvGrpSpPr><xdr:grpSpPr/>` +
+			drawing[start:end] + `<xdr:sp><xdr:nvSpPr><xdr:cNvPr id="11" name="Caption"/><xdr:cNvSpPr/></xdr:nvSpPr><xdr:spPr/>` +
+			`<xdr:txBody><a:bodyPr/><a:p><a:r><a:t>Jane Roe</a:t></a:r></a:p></xdr:txBody></xdr:sp></xdr:grpSp>` + drawing[end:]
+	})
+}
+
+// readDrawing returns the drawing of the sanitized workbook
+func readDrawing(t *testing.T, f *excelize.File) string {
+	drawing, _ := readPackage(t, f).Part("xl/drawings/drawing1.xml")
+	return string(drawing)
+}
+
+func TestDrawings(t *testing.T) {
+	t.Run("remove every picture", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newDrawingFile(t), &types.Sanitize{Pictures: "remove"})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 3, sanitizer.Report.RemovedPictures)
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, 0, len(pictureCells))
+		// The text box is kept
+		assert.Equal(t, true, strings.Contains(readDrawing(t, sanitizer.File), "John Doe"))
+	})
+
+	t.Run("remove the pictures of redacted and excluded cells", func(t *testing.T) {
+		f := newDrawingFile(t)
+		rulesExecutor := transform.MakeRulesExecutor(f, []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true},
+			Actions: []types.Action{
+				{ActionType: "REDACT", Operation: "RANGE", Value: "A1:A1"},
+				{ActionType: "EXCLUDE", Operation: "ROW", Value: "3"},
+			},
+		}})
+		rulesExecutor.RemoveExcludedPictures = RemovesExcludedPictures(&types.Sanitize{Pictures: "redacted"})
+		if transformErr := rulesExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+
+		sanitizer := MakeSanitizer(f, &types.Sanitize{Pictures: "redacted"})
+		sanitizer.Manifest = rulesExecutor.Manifest
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 2, sanitizer.Report.RemovedPictures)
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, []string{"C4"}, pictureCells)
+	})
+
+	t.Run("remove the pictures covering a redacted cell", func(t *testing.T) {
+		var picture bytes.Buffer
+		if err := png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 300, 100))); err != nil {
+			t.Fatalf("failed to encode the picture: %v", err)
+		}
+		f := newDrawingFile(t)
+		err := f.AddPictureFromBytes("Sheet1", "G1", &excelize.Picture{Extension: ".png", File: picture.Bytes(), Format: &excelize.GraphicOptions{}})
+		if err != nil {
+			t.Fatalf("failed to add the picture: %v", err)
+		}
+		f.SetCellValue("Sheet1", "H3", "**redacted**")
+
+		sanitizer := MakeSanitizer(f, &types.Sanitize{Pictures: "redacted"})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		// The large picture is anchored on G1 but covers H3
+		assert.Equal(t, 1, sanitizer.Report.RemovedPictures)
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, []string{"A1", "B3", "C5"}, pictureCells)
+	})
+
+	t.Run("remove the pictures of groups", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newGroupFile(t), &types.Sanitize{Pictures: "remove"})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 3, sanitizer.Report.RemovedPictures)
+		p := readPackage(t, sanitizer.File)
+		drawing, _ := p.Part("xl/drawings/drawing1.xml")
+		assert.Equal(t, false, strings.Contains(string(drawing), "<xdr:pic>"))
+		// The caption of the group is kept, the images are no longer in the package
+		assert.Equal(t, true, strings.Contains(string(drawing), "Jane Roe"))
+		for _, name := range p.Parts() {
+			assert.Equal(t, false, strings.HasPrefix(name, "xl/media/"))
+		}
+	})
+
+	t.Run("redact the text of shapes", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newDrawingFile(t), &types.Sanitize{ShapeText: &types.TextRedaction{
+			Values:   []string{"John Doe"},
+			Patterns: []string{`\d{3}-\d{2}-\d{4}`},
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 1, sanitizer.Report.RedactedShapes)
+		drawing := readDrawing(t, sanitizer.File)
+		assert.Equal(t, true, strings.Contains(drawing, "Signed by **redacted**, SSN **redacted**"))
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, 3, len(pictureCells))
+	})
+
+	t.Run("redact the text split in runs and the PII of shapes and groups", func(t *testing.T) {
+		f := editDrawing(t, newGroupFile(t), func(drawing string) string {
+			return strings.Replace(drawing, "<a:t>Signed by John Doe, SSN 111-22-3333</a:t>",
+				`<a:t>Signed by John </a:t></a:r><a:r><a:rPr b="1"/><a:t>Doe, SSN 111-22-3333</a:t>`, 1)
+		})
+		sanitizer := MakeSanitizer(f, &types.Sanitize{ShapeText: &types.TextRedaction{
+			Values:    []string{"John Doe", "Jane Roe"},
+			Detectors: []string{"ssn"},
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 2, sanitizer.Report.RedactedShapes)
+		drawing := readDrawing(t, sanitizer.File)
+		assert.Equal(t, true, strings.Contains(drawing, "<a:t>Signed by **redacted**</a:t>"))
+		assert.Equal(t, true, strings.Contains(drawing, "<a:t>, SSN **redacted**</a:t>"))
+		assert.Equal(t, false, strings.Contains(drawing, "Jane Roe"))
+	})
+
+	t.Run("remove the shapes", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newDrawingFile(t), &types.Sanitize{RemoveShapes: true})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 1, sanitizer.Report.RemovedShapes)
+		assert.Equal(t, false, strings.Contains(readDrawing(t, sanitizer.File), "John Doe"))
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, 3, len(pictureCells))
+	})
+
+	t.Run("remove the groups holding pictures", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newGroupFile(t), &types.Sanitize{RemoveShapes: true})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 2, sanitizer.Report.RemovedShapes)
+		assert.Equal(t, false, strings.Contains(readDrawing(t, sanitizer.File), "Jane Roe"))
+		pictureCells, _ := sanitizer.File.GetPictureCells("Sheet1")
+		assert.Equal(t, []string{"B3", "C5"}, pictureCells)
+	})
+}
@@ -0,0 +1,120 @@
+package sanitize
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+)
+
+// Constants for the hidden content modes
+const (
+	REMOVE string = "REMOVE"
+	UNHIDE string = "UNHIDE"
+	REPORT string = "REPORT"
+)
+
+// handleHiddenContent reports the hidden sheets, rows and columns, then removes or reveals them
+func (s *Sanitizer) handleHiddenContent() error {
+	f := s.File
+	mode := strings.ToUpper(s.options.HiddenContent)
+	if mode != REMOVE && mode != UNHIDE && mode != REPORT {
+		return fmt.Errorf("'%s' is not a valid hidden content mode", s.options.HiddenContent)
+	}
+
+	var removedSheets []string
+	for _, sheetName := range f.GetSheetList() {
+		visibility, err := sheet.GetVisibility(f, sheetName)
+		if err != nil {
+			return err
+		}
+
+		if visibility != sheet.VISIBLE {
+			s.Report.HiddenSheets = append(s.Report.HiddenSheets, types.HiddenSheet{
+				SheetName:  sheetName,
+				Visibility: visibility,
+			})
+			switch mode {
+			case REMOVE:
+				// Nothing on a removed sheet is worth handling further
+				removedSheets = append(removedSheets, sheetName)
+				continue
+			case UNHIDE:
+				if err = f.SetSheetVisible(sheetName, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		err = s.handleHiddenRowsAndColumns(sheetName, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The hidden sheets are removed as the sheet actions remove them, along with the defined
+	// names and formulas referring to them
+	return transform.DeleteSheets(f, s.Manifest, removedSheets)
+}
+
+// handleHiddenRowsAndColumns reports the hidden rows and columns of a sheet, then removes or reveals them
+func (s *Sanitizer) handleHiddenRowsAndColumns(sheetName string, mode string) error {
+	f := s.File
+
+	hiddenRows, hiddenCols, err := sheet.GetHiddenRowsAndColumns(f, sheetName)
+	if err != nil {
+		return err
+	}
+	if len(hiddenRows) == 0 && len(hiddenCols) == 0 {
+		return nil
+	}
+	s.Report.HiddenRowsAndColumns = append(s.Report.HiddenRowsAndColumns, types.HiddenRowsAndColumns{
+		SheetName: sheetName,
+		Rows:      hiddenRows,
+		Columns:   hiddenCols,
+	})
+
+	switch mode {
+	case REMOVE:
+		// Removing from the end so the remaining row and column numbers do not shift
+		for _, rowNum := range slices.Backward(hiddenRows) {
+			if err = s.removeExcludedPictures(sheetName, 0, rowNum); err != nil {
+				return err
+			}
+			if err = f.RemoveRow(sheetName, rowNum); err != nil {
+				return err
+			}
+			if err = transform.ShiftRedactedCells(s.Manifest, sheetName, 0, rowNum); err != nil {
+				return err
+			}
+		}
+		for _, colName := range slices.Backward(hiddenCols) {
+			if err = s.removeExcludedPictures(sheetName, cell.ColumnToNumber(colName), 0); err != nil {
+				return err
+			}
+			if err = f.RemoveCol(sheetName, colName); err != nil {
+				return err
+			}
+			if err = transform.ShiftRedactedCells(s.Manifest, sheetName, cell.ColumnToNumber(colName), 0); err != nil {
+				return err
+			}
+		}
+	case UNHIDE:
+		for _, rowNum := range hiddenRows {
+			if err = f.SetRowVisible(sheetName, rowNum, true); err != nil {
+				return err
+			}
+		}
+		for _, colName := range hiddenCols {
+			if err = f.SetColVisible(sheetName, colName, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package sanitize
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestHiddenContent(t *testing.T) {
+	newHiddenFile := func() *excelize.File {
+		f := excelize.NewFile()
+		f.SetSheetRow("Sheet1", "A1", &[]string{"Name", "Salary", "Team"})
+		f.SetSheetRow("Sheet1", "A2", &[]string{"Jane", "100", "Sales"})
+		f.SetSheetRow("Sheet1", "A3", &[]string{"John", "200", "Support"})
+		f.SetColVisible("Sheet1", "B", false)
+		f.SetRowVisible("Sheet1", 2, false)
+		f.NewSheet("Calc")
+		f.SetCellValue("Calc", "A1", "Salaries")
+		f.SetSheetVisible("Calc", false, true)
+		f.NewSheet("Lookup")
+		f.SetSheetVisible("Lookup", false)
+		return f
+	}
+
+	expectedReport := &types.SanitizeReport{
+		HiddenSheets: []types.HiddenSheet{
+			{SheetName: "Calc", Visibility: sheet.VERY_HIDDEN},
+			{SheetName: "Lookup", Visibility: sheet.HIDDEN},
+		},
+		HiddenRowsAndColumns: []types.HiddenRowsAndColumns{
+			{SheetName: "Sheet1", Rows: []int{2}, Columns: []string{"B"}},
+		},
+	}
+
+	t.Run("report only lists the hidden content", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newHiddenFile(), &types.Sanitize{HiddenContent: "report"})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, expectedReport, sanitizer.Report)
+		assert.Equal(t, []string{"Sheet1", "Calc", "Lookup"}, sanitizer.File.GetSheetList())
+	})
+
+	t.Run("remove deletes the hidden content", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newHiddenFile(), &types.Sanitize{HiddenContent: "remove"})
+		sanitizer.Manifest = &types.Manifest{RedactedCells: []types.RedactedCell{
+			{SheetName: "Sheet1", Cell: "C3"},
+			{SheetName: "Calc", Cell: "A1"},
+		}}
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		// The strings of the removed content are purged from the output
+		removedReport := *expectedReport
+		removedReport.PurgedSharedStrings = 6
+		assert.Equal(t, &removedReport, sanitizer.Report)
+		assert.Equal(t, []string{"Sheet1"}, sanitizer.File.GetSheetList())
+		rows, _ := sanitizer.File.GetRows("Sheet1")
+		assert.Equal(t, [][]string{{"Name", "Team"}, {"John", "Support"}}, rows)
+		assert.Equal(t, "B2", sanitizer.Manifest.RedactedCells[0].Cell)
+		assert.Equal(t, true, sanitizer.Manifest.RedactedCells[1].Excluded)
+	})
+
+	t.Run("remove clears the names and formulas referring to the hidden sheets", func(t *testing.T) {
+		f := newHiddenFile()
+		f.SetCellFormula("Sheet1", "D1", "Calc!A1")
+		f.SetCellFormula("Sheet1", "E1", "D1")
+		f.SetDefinedName(&excelize.DefinedName{Name: "Source", RefersTo: "Calc!$A$1"})
+		sanitizer := MakeSanitizer(f, &types.Sanitize{HiddenContent: "remove"})
+		sanitizer.Manifest = &types.Manifest{}
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		for _, cellName := range []string{"D1", "E1"} {
+			formula, _ := sanitizer.File.GetCellFormula("Sheet1", cellName)
+			assert.Equal(t, "", formula)
+		}
+		assert.Equal(t, 2, len(sanitizer.Manifest.ScrubbedFormulas))
+		assert.Equal(t, 0, len(sanitizer.File.GetDefinedName()))
+	})
+
+	t.Run("unhide reveals the hidden content", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newHiddenFile(), &types.Sanitize{HiddenContent: "unhide"})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		f := sanitizer.File
+		visibility, _ := sheet.GetVisibility(f, "Calc")
+		assert.Equal(t, sheet.VISIBLE, visibility)
+		hiddenRows, hiddenCols, _ := sheet.GetHiddenRowsAndColumns(f, "Sheet1")
+		assert.Equal(t, 0, len(hiddenRows))
+		assert.Equal(t, 0, len(hiddenCols))
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newHiddenFile(), &types.Sanitize{HiddenContent: "hide"})
+		err := sanitizer.Execute()
+		assert.Equal(t, "'hide' is not a valid hidden content mode", err.Error())
+	})
+}
@@ -0,0 +1,320 @@
+package sanitize
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/link"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+)
+
+// Constants for the link actions, along with REMOVE
+const (
+	REWRITE string = "REWRITE"
+)
+
+// externalReferencePattern matches the index of an external workbook in a formula, eg: [1]Sheet1!A1.
+// Structured references such as Table1[2019] are preceded by a name and left out
+var externalReferencePattern = regexp.MustCompile(`(^|[^\w\[\]])\[(\d+)\]`)
+
+// Query tables are edited textually, their attributes name the connection they use
+var (
+	queryTablePartPattern = regexp.MustCompile(`^xl/queryTables/queryTable\d+\.xml$`)
+	tablePartPattern      = regexp.MustCompile(`^xl/tables/table\d+\.xml$`)
+	queryTableAttributes  = regexp.MustCompile(`\s(?:tableType="queryTable"|connectionId="\d+"|queryTableFieldId="\d+")`)
+)
+
+// linkPolicy is a compiled link policy of the options
+type linkPolicy struct {
+	action      string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// compileLinkPolicy checks a link policy of the options, nil when the option is not set
+func compileLinkPolicy(policy *types.LinkPolicy, option string) (*linkPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	action := strings.ToUpper(policy.Action)
+	if action != REMOVE && action != REWRITE {
+		return nil, fmt.Errorf("'%s' is not a valid %s action, expected remove or rewrite", policy.Action, option)
+	}
+	compiled := &linkPolicy{action: action, replacement: policy.Replacement}
+	if policy.Pattern != "" {
+		pattern, err := regexp.Compile(policy.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid regular expression: %w", policy.Pattern, err)
+		}
+		compiled.pattern = pattern
+	}
+	return compiled, nil
+}
+
+// selects reports whether the policy applies to a target
+func (l *linkPolicy) selects(target string) bool {
+	return l.pattern == nil || l.pattern.MatchString(target)
+}
+
+// rewrite returns the new target, the matches of the pattern or the whole target are replaced
+func (l *linkPolicy) rewrite(target string) string {
+	if l.pattern == nil {
+		return l.replacement
+	}
+	return l.pattern.ReplaceAllString(target, l.replacement)
+}
+
+// compileLinkPolicies checks the link policies before anything is changed
+func (s *Sanitizer) compileLinkPolicies() (err error) {
+	if s.hyperlinks, err = compileLinkPolicy(s.options.Hyperlinks, "hyperlinks"); err != nil {
+		return err
+	}
+	if s.externalLinks, err = compileLinkPolicy(s.options.ExternalLinks, "externalLinks"); err != nil {
+		return err
+	}
+	s.connections, err = compileLinkPolicy(s.options.Connections, "connections")
+	return err
+}
+
+// addSanitizedLink records a removed or rewritten link in the report
+func (s *Sanitizer) addSanitizedLink(sanitizedLink types.Link, policy *linkPolicy, rewrittenTarget string) {
+	s.Report.Links = append(s.Report.Links, types.SanitizedLink{
+		Link:            sanitizedLink,
+		Action:          strings.ToLower(policy.action),
+		RewrittenTarget: rewrittenTarget,
+	})
+}
+
+// sanitizeHyperlinks removes or rewrites the hyperlinks of every sheet. The text of the cells is kept
+func (s *Sanitizer) sanitizeHyperlinks(p *ooxml.Package) error {
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, sheetPart := range p.Parts() {
+		sheetName, isSheet := sheetNames[sheetPart]
+		if !isSheet {
+			continue
+		}
+		data, _ := p.Part(sheetPart)
+		relsName := ooxml.RelsPath(sheetPart)
+
+		var linkErr error
+		content := link.HyperlinkPattern.ReplaceAllStringFunc(string(data), func(hyperlink string) string {
+			target, relationshipID, err := link.HyperlinkTarget(p, sheetPart, hyperlink)
+			if err != nil {
+				linkErr = err
+				return hyperlink
+			}
+			if !s.hyperlinks.selects(target) {
+				return hyperlink
+			}
+			sanitizedLink := types.Link{Type: link.HYPERLINK, SheetName: sheetName, Cell: ooxml.Attribute(hyperlink, "ref"), Target: target}
+
+			if s.hyperlinks.action == REMOVE {
+				if relationshipID != "" {
+					linkErr = p.RemoveRelationship(relsName, relationshipID)
+				}
+				s.addSanitizedLink(sanitizedLink, s.hyperlinks, "")
+				return ""
+			}
+
+			rewritten := s.hyperlinks.rewrite(target)
+			s.addSanitizedLink(sanitizedLink, s.hyperlinks, rewritten)
+			if relationshipID != "" {
+				linkErr = p.SetRelationshipTarget(relsName, relationshipID, rewritten)
+				return hyperlink
+			}
+			return ooxml.SetAttribute(hyperlink, "location", rewritten)
+		})
+		if linkErr != nil {
+			return linkErr
+		}
+		content = regexp.MustCompile(`<hyperlinks\s*/>|<hyperlinks>\s*</hyperlinks>`).ReplaceAllString(content, "")
+		p.SetPart(sheetPart, []byte(content))
+	}
+
+	return nil
+}
+
+// prepareExternalLinks selects the external links to remove and replaces the formulas using
+// them with their last value. The formulas using the remaining links are numbered again, so
+// the workbook still opens once the parts are removed
+func (s *Sanitizer) prepareExternalLinks() error {
+	buffer, err := s.File.WriteToBuffer()
+	if err != nil {
+		return err
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	externalLinks, err := link.GetExternalLinks(p)
+	if err != nil {
+		return err
+	}
+
+	removedIndexes := map[int]bool{}
+	renumbered := map[int]int{}
+	for _, externalLink := range externalLinks {
+		if s.externalLinks.selects(externalLink.Target) {
+			s.removedExternalLinks = append(s.removedExternalLinks, externalLink)
+			removedIndexes[externalLink.Index] = true
+			continue
+		}
+		renumbered[externalLink.Index] = externalLink.Index - len(s.removedExternalLinks)
+	}
+	if len(s.removedExternalLinks) == 0 {
+		return nil
+	}
+
+	usesRemovedLink := func(formula string) bool {
+		for _, match := range externalReferencePattern.FindAllStringSubmatch(formula, -1) {
+			index, _ := strconv.Atoi(match[2])
+			if removedIndexes[index] {
+				return true
+			}
+		}
+		return false
+	}
+	renumber := func(formula string) string {
+		return externalReferencePattern.ReplaceAllStringFunc(formula, func(reference string) string {
+			match := externalReferencePattern.FindStringSubmatch(reference)
+			index, _ := strconv.Atoi(match[2])
+			if newIndex, found := renumbered[index]; found {
+				return match[1] + "[" + strconv.Itoa(newIndex) + "]"
+			}
+			return reference
+		})
+	}
+
+	for _, sheetName := range s.File.GetSheetList() {
+		failed, err := sheet.FlattenFormulasWhere(s.File, sheetName, sheet.FlattenOptions{IsFlattened: usesRemovedLink})
+		if err != nil {
+			return err
+		}
+		// Without a cached value the formula cannot be calculated once its link is gone, the
+		// cell shows the error Excel gives a broken reference
+		for _, cellName := range failed {
+			if err = s.File.SetCellValue(sheetName, cellName, "#REF!"); err != nil {
+				return err
+			}
+		}
+		if err = sheet.RewriteFormulas(s.File, sheetName, renumber); err != nil {
+			return err
+		}
+	}
+	return sheet.RewriteDefinedNames(s.File, func(refersTo string) (string, bool) {
+		return renumber(refersTo), !usesRemovedLink(refersTo)
+	})
+}
+
+// sanitizeExternalLinks removes the external links selected beforehand, or rewrites the path
+// of the other workbooks
+func (s *Sanitizer) sanitizeExternalLinks(p *ooxml.Package) error {
+	if s.externalLinks.action == REMOVE {
+		link.RemoveExternalReferences(p, s.removedExternalLinks)
+		for _, externalLink := range s.removedExternalLinks {
+			if err := p.RemovePart(externalLink.Part); err != nil {
+				return err
+			}
+			s.addSanitizedLink(types.Link{Type: link.EXTERNAL_LINK, Target: externalLink.Target}, s.externalLinks, "")
+		}
+		return nil
+	}
+
+	externalLinks, err := link.GetExternalLinks(p)
+	if err != nil {
+		return err
+	}
+	for _, externalLink := range externalLinks {
+		if externalLink.TargetRelationshipID == "" || !s.externalLinks.selects(externalLink.Target) {
+			continue
+		}
+		rewritten := s.externalLinks.rewrite(externalLink.Target)
+		err = p.SetRelationshipTarget(ooxml.RelsPath(externalLink.Part), externalLink.TargetRelationshipID, rewritten)
+		if err != nil {
+			return err
+		}
+		s.addSanitizedLink(types.Link{Type: link.EXTERNAL_LINK, Target: externalLink.Target}, s.externalLinks, rewritten)
+	}
+	return nil
+}
+
+// sanitizeConnections removes the data connections along with the query tables using them, or
+// rewrites their connection strings and paths
+func (s *Sanitizer) sanitizeConnections(p *ooxml.Package) error {
+	data, found := p.Part(link.ConnectionsPath)
+	if !found {
+		return nil
+	}
+
+	var removedIDs []string
+	content := link.ConnectionPattern.ReplaceAllStringFunc(string(data), func(connection string) string {
+		target := link.ConnectionTarget(connection)
+		if !s.connections.selects(target) {
+			return connection
+		}
+		sanitizedLink := types.Link{Type: link.CONNECTION, Name: ooxml.Attribute(connection, "name"), Target: target}
+
+		if s.connections.action == REMOVE {
+			removedIDs = append(removedIDs, ooxml.Attribute(connection, "id"))
+			s.addSanitizedLink(sanitizedLink, s.connections, "")
+			return ""
+		}
+
+		for _, attribute := range link.ConnectionTargetAttributes {
+			if value := ooxml.Attribute(connection, attribute); value != "" && s.connections.selects(value) {
+				connection = ooxml.SetAttribute(connection, attribute, s.connections.rewrite(value))
+			}
+		}
+		s.addSanitizedLink(sanitizedLink, s.connections, link.ConnectionTarget(connection))
+		return connection
+	})
+
+	if len(link.ConnectionPattern.FindAllString(content, -1)) == 0 {
+		if err := p.RemovePart(link.ConnectionsPath); err != nil {
+			return err
+		}
+	} else {
+		p.SetPart(link.ConnectionsPath, []byte(content))
+	}
+
+	return removeQueryTables(p, removedIDs)
+}
+
+// removeQueryTables removes the query tables of the removed connections, the tables that held
+// their results become plain tables
+func removeQueryTables(p *ooxml.Package, connectionIDs []string) error {
+	if len(connectionIDs) == 0 {
+		return nil
+	}
+	usesRemovedConnection := func(element string) bool {
+		connectionID := ooxml.Attribute(element, "connectionId")
+		return connectionID != "" && slices.Contains(connectionIDs, connectionID)
+	}
+
+	for _, name := range p.Parts() {
+		data, _ := p.Part(name)
+		switch {
+		case queryTablePartPattern.MatchString(name):
+			if usesRemovedConnection(regexp.MustCompile(`<queryTable\b[^>]*>`).FindString(string(data))) {
+				if err := p.RemovePart(name); err != nil {
+					return err
+				}
+			}
+		case tablePartPattern.MatchString(name):
+			if usesRemovedConnection(regexp.MustCompile(`<table\b[^>]*>`).FindString(string(data))) {
+				p.SetPart(name, []byte(queryTableAttributes.ReplaceAllString(string(data), "")))
+			}
+		}
+	}
+	return nil
+}
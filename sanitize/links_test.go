@@ -0,0 +1,179 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/link"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+const relationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// newLinkedFile creates a workbook with two hyperlinks, formulas on two external workbooks
+// and a database connection feeding a query table
+func newLinkedFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "HR site")
+	f.SetCellHyperLink("Sheet1", "A1", "https://sharepoint.example.com/sites/hr", "External")
+	f.SetCellValue("Sheet1", "A2", "Totals")
+	f.SetCellHyperLink("Sheet1", "A2", "Sheet1!C10", "Location")
+	f.SetCellFormula("Sheet1", "B1", "[1]Data!A1*2")
+	f.SetCellFormula("Sheet1", "C1", "[2]Data!A1")
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+
+	// The last values of the external formulas, as Excel saves them
+	sheet, _ := p.Part("xl/worksheets/sheet1.xml")
+	content := strings.Replace(string(sheet), "<f>[1]Data!A1*2</f>", "<f>[1]Data!A1*2</f><v>84</v>", 1)
+	content = strings.Replace(content, "<f>[2]Data!A1</f>", "<f>[2]Data!A1</f><v>7</v>", 1)
+	p.SetPart("xl/worksheets/sheet1.xml", []byte(content))
+
+	workbookRelationships := ""
+	contentTypes := ""
+	for i, target := range []string{`file:///\\fileserver\finance\budget.xlsx`, "https://sharepoint.example.com/sites/finance/rates.xlsx"} {
+		index := string(rune('1' + i))
+		part := "externalLinks/externalLink" + index + ".xml"
+		p.SetPart("xl/"+part, []byte(`<externalLink xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="`+relationshipsNamespace+`"><externalBook r:id="rId1"><sheetNames><sheetName val="Data"/></sheetNames></externalBook></externalLink>`))
+		p.SetPart("xl/externalLinks/_rels/externalLink"+index+".xml.rels", []byte(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="`+relationshipsNamespace+`/externalLinkPath" Target="`+target+`" TargetMode="External"/></Relationships>`))
+		workbookRelationships += `<Relationship Id="rIdExternal` + index + `" Type="` + relationshipsNamespace + `/externalLink" Target="` + part + `"/>`
+		contentTypes += `<Override PartName="/xl/` + part + `" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.externalLink+xml"/>`
+	}
+	workbook, _ := p.Part("xl/workbook.xml")
+	p.SetPart("xl/workbook.xml", []byte(strings.Replace(string(workbook), "<calcPr",
+		`<externalReferences><externalReference r:id="rIdExternal1"/><externalReference r:id="rIdExternal2"/></externalReferences><calcPr`, 1)))
+
+	p.SetPart("xl/connections.xml", []byte(`<connections xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><connection id="1" name="Payroll" type="1"><dbPr connection="Provider=SQLOLEDB;Data Source=sql01.corp.local" command="SELECT * FROM salaries"/></connection></connections>`))
+	p.SetPart("xl/queryTables/queryTable1.xml", []byte(`<queryTable xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" name="Payroll" connectionId="1"/>`))
+	workbookRelationships += `<Relationship Id="rIdConnections" Type="` + relationshipsNamespace + `/connections" Target="connections.xml"/>`
+	contentTypes += `<Override PartName="/xl/connections.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.connections+xml"/>`
+	sheetRels, _ := p.Part("xl/worksheets/_rels/sheet1.xml.rels")
+	p.SetPart("xl/worksheets/_rels/sheet1.xml.rels", []byte(strings.Replace(string(sheetRels), "</Relationships>",
+		`<Relationship Id="rIdQuery" Type="`+relationshipsNamespace+`/queryTable" Target="../queryTables/queryTable1.xml"/></Relationships>`, 1)))
+
+	rels, _ := p.Part("xl/_rels/workbook.xml.rels")
+	p.SetPart("xl/_rels/workbook.xml.rels", []byte(strings.Replace(string(rels), "</Relationships>", workbookRelationships+"</Relationships>", 1)))
+	contentTypesPart, _ := p.Part("[Content_Types].xml")
+	p.SetPart("[Content_Types].xml", []byte(strings.Replace(string(contentTypesPart), "</Types>", contentTypes+"</Types>", 1)))
+
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	f, err = file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f
+}
+
+func TestLinks(t *testing.T) {
+	t.Run("list the links", func(t *testing.T) {
+		links, err := link.GetFileLinks(newLinkedFile(t))
+		if err != nil {
+			t.Fatalf("failed to get the links: %v", err)
+		}
+
+		assert.Equal(t, []types.Link{
+			{Type: "hyperlink", SheetName: "Sheet1", Cell: "A1", Target: "https://sharepoint.example.com/sites/hr"},
+			{Type: "hyperlink", SheetName: "Sheet1", Cell: "A2", Target: "Sheet1!C10"},
+			{Type: "externalLink", Target: `file:///\\fileserver\finance\budget.xlsx`},
+			{Type: "externalLink", Target: "https://sharepoint.example.com/sites/finance/rates.xlsx"},
+			{Type: "connection", Name: "Payroll", Target: "Provider=SQLOLEDB;Data Source=sql01.corp.local"},
+		}, links)
+	})
+
+	t.Run("remove the hyperlinks matching a pattern", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{Hyperlinks: &types.LinkPolicy{Action: "remove", Pattern: "sharepoint"}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		hasLink, _, _ := sanitizer.File.GetCellHyperLink("Sheet1", "A1")
+		assert.Equal(t, false, hasLink)
+		hasLink, _, _ = sanitizer.File.GetCellHyperLink("Sheet1", "A2")
+		assert.Equal(t, true, hasLink)
+		value, _ := sanitizer.File.GetCellValue("Sheet1", "A1")
+		assert.Equal(t, "HR site", value)
+		assert.Equal(t, []types.SanitizedLink{{
+			Link:   types.Link{Type: "hyperlink", SheetName: "Sheet1", Cell: "A1", Target: "https://sharepoint.example.com/sites/hr"},
+			Action: "remove",
+		}}, sanitizer.Report.Links)
+	})
+
+	t.Run("rewrite the hyperlinks", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{Hyperlinks: &types.LinkPolicy{
+			Action: "rewrite", Pattern: `^https://sharepoint\.example\.com/.*$`, Replacement: "https://example.com",
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		_, target, _ := sanitizer.File.GetCellHyperLink("Sheet1", "A1")
+		assert.Equal(t, "https://example.com", target)
+		assert.Equal(t, "https://example.com", sanitizer.Report.Links[0].RewrittenTarget)
+	})
+
+	t.Run("remove an external link and keep the last values of its formulas", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{ExternalLinks: &types.LinkPolicy{Action: "remove", Pattern: "budget"}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		formula, _ := sanitizer.File.GetCellFormula("Sheet1", "B1")
+		assert.Equal(t, "", formula)
+		value, _ := sanitizer.File.GetCellValue("Sheet1", "B1")
+		assert.Equal(t, "84", value)
+		// The remaining link is numbered again
+		formula, _ = sanitizer.File.GetCellFormula("Sheet1", "C1")
+		assert.Equal(t, "[1]Data!A1", formula)
+
+		links, _ := link.GetFileLinks(sanitizer.File)
+		assert.Equal(t, types.Link{Type: "externalLink", Target: "https://sharepoint.example.com/sites/finance/rates.xlsx"}, links[2])
+		assert.Equal(t, "remove", sanitizer.Report.Links[0].Action)
+		assert.Equal(t, `file:///\\fileserver\finance\budget.xlsx`, sanitizer.Report.Links[0].Target)
+	})
+
+	t.Run("remove the connections and their query tables", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{Connections: &types.LinkPolicy{Action: "remove"}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		for _, name := range p.Parts() {
+			assert.Equal(t, false, name == "xl/connections.xml" || strings.HasPrefix(name, "xl/queryTables/"))
+		}
+		assert.Equal(t, "Payroll", sanitizer.Report.Links[0].Name)
+	})
+
+	t.Run("rewrite the connection strings", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{Connections: &types.LinkPolicy{
+			Action: "rewrite", Pattern: `Data Source=[^;]*`, Replacement: "Data Source=redacted",
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		links, _ := link.GetFileLinks(sanitizer.File)
+		assert.Equal(t, "Provider=SQLOLEDB;Data Source=redacted", links[len(links)-1].Target)
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newLinkedFile(t), &types.Sanitize{Hyperlinks: &types.LinkPolicy{Action: "keep"}})
+		err := sanitizer.Execute()
+		assert.Equal(t, "'keep' is not a valid hyperlinks action, expected remove or rewrite", err.Error())
+	})
+}
@@ -0,0 +1,86 @@
+package sanitize
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	workbookPath = "xl/workbook.xml"
+	// vbaProjectPrefix covers the VBA project and its signatures, eg: xl/vbaProjectSignature.bin
+	vbaProjectPrefix    = "xl/vbaProject"
+	vbaProjectPath      = "xl/vbaProject.bin"
+	contentTypeWorkbook = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"
+	contentTypeTemplate = "application/vnd.openxmlformats-officedocument.spreadsheetml.template.main+xml"
+)
+
+// macroEnabledContentTypes maps the content types of the macro-enabled workbooks to their plain equivalent
+var macroEnabledContentTypes = map[string]string{
+	excelize.ContentTypeMacro:         contentTypeWorkbook,
+	excelize.ContentTypeTemplateMacro: contentTypeTemplate,
+	excelize.ContentTypeAddinMacro:    contentTypeWorkbook,
+}
+
+// detectMacros reports the VBA project and the macro-enabled content type of the workbook,
+// nil when it has neither
+func detectMacros(f *excelize.File) (*types.Macros, error) {
+	macros := &types.Macros{}
+	f.Pkg.Range(func(key, _ any) bool {
+		name := key.(string)
+		switch {
+		case name == vbaProjectPath:
+			macros.VbaProject = true
+		case strings.HasPrefix(name, vbaProjectPrefix+"Signature"):
+			macros.Signed = true
+		}
+		return true
+	})
+
+	contentTypes := &ooxml.ContentTypes{}
+	if data, found := f.Pkg.Load("[Content_Types].xml"); found {
+		if err := xml.Unmarshal(data.([]byte), contentTypes); err != nil {
+			return nil, err
+		}
+	}
+	for _, override := range contentTypes.Overrides {
+		if _, macroEnabled := macroEnabledContentTypes[override.ContentType]; macroEnabled && override.PartName == "/"+workbookPath {
+			macros.MacroEnabled = true
+		}
+	}
+
+	if !macros.MacroEnabled && !macros.VbaProject {
+		return nil, nil
+	}
+	return macros, nil
+}
+
+// removeMacros removes the VBA project and its signatures, and gives the workbook the content
+// type of a plain .xlsx so it opens without the macro warning
+func (s *Sanitizer) removeMacros(p *ooxml.Package) error {
+	if _, err := p.RemovePartsWithPrefix(vbaProjectPrefix); err != nil {
+		return err
+	}
+	if err := p.RemoveContentTypeDefaults(excelize.ContentTypeVBA); err != nil {
+		return err
+	}
+
+	contentTypes, err := p.ContentTypes()
+	if err != nil {
+		return err
+	}
+	for _, override := range contentTypes.Overrides {
+		if plainContentType, macroEnabled := macroEnabledContentTypes[override.ContentType]; macroEnabled && override.PartName == "/"+workbookPath {
+			if err = p.SetContentTypeOverride(workbookPath, plainContentType); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.Report.Macros.Removed = true
+	return nil
+}
@@ -0,0 +1,79 @@
+package sanitize
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newMacroEnabledFile creates a .xlsm workbook with a VBA project
+func newMacroEnabledFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "Salary")
+	// The VBA project is an OLE compound file. It is long enough to be compressed in the package,
+	// excelize takes a package holding the identifier as is for an encrypted file
+	vbaProject := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, bytes.Repeat([]byte("Attribute VB_Name"), 100)...)
+	err := f.AddVBAProject(vbaProject)
+	if err != nil {
+		t.Fatalf("failed to add the VBA project: %v", err)
+	}
+	// excelize sets the macro-enabled content type from the extension of the path
+	f.Path = "macros.xlsm"
+
+	buffer := new(bytes.Buffer)
+	if err = f.Write(buffer); err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	f, err = file.InitFileFromBytes(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f
+}
+
+func TestMacros(t *testing.T) {
+	t.Run("report the macros without options", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newMacroEnabledFile(t), nil)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, &types.Macros{MacroEnabled: true, VbaProject: true}, sanitizer.Report.Macros)
+	})
+
+	t.Run("no macros in a plain workbook", func(t *testing.T) {
+		sanitizer := MakeSanitizer(excelize.NewFile(), &types.Sanitize{RemoveMacros: true})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, (*types.Macros)(nil), sanitizer.Report.Macros)
+	})
+
+	t.Run("remove the VBA project", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newMacroEnabledFile(t), &types.Sanitize{RemoveMacros: true})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		_, found := p.Part("xl/vbaProject.bin")
+		assert.Equal(t, false, found)
+		contentTypes, _ := p.Part("[Content_Types].xml")
+		assert.Equal(t, false, strings.Contains(string(contentTypes), "macroEnabled"))
+		assert.Equal(t, false, strings.Contains(string(contentTypes), "vbaProject"))
+		assert.Equal(t, true, strings.Contains(string(contentTypes), "spreadsheetml.sheet.main+xml"))
+		rels, _ := p.Part("xl/_rels/workbook.xml.rels")
+		assert.Equal(t, false, strings.Contains(string(rels), "vbaProject"))
+		assert.Equal(t, &types.Macros{MacroEnabled: true, VbaProject: true, Removed: true}, sanitizer.Report.Macros)
+
+		value, _ := sanitizer.File.GetCellValue("Sheet1", "A1")
+		assert.Equal(t, "Salary", value)
+	})
+}
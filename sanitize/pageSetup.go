@@ -0,0 +1,130 @@
+package sanitize
+
+import (
+	"html"
+	"regexp"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/ooxml"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// headerFooterPattern matches the headers and footers of the odd, even and first pages. Their
+// text holds formatting codes, eg: &"Arial,Bold"&14Acme Corp&R&Z, and is redacted as a whole
+var headerFooterPattern = regexp.MustCompile(`(?s)(<(?:odd|even|first)(?:Header|Footer)>)(.*?)(</(?:odd|even|first)(?:Header|Footer)>)`)
+
+const printTitlesName = "_xlnm.Print_Titles"
+
+// redactHeadersFooters redacts the text of the headers and footers of every sheet
+func (s *Sanitizer) redactHeadersFooters(p *ooxml.Package) error {
+	redactText, err := textRedactor(s.options.HeadersFooters)
+	if err != nil {
+		return err
+	}
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, sheetPart := range p.Parts() {
+		if _, isSheet := sheetNames[sheetPart]; !isSheet {
+			continue
+		}
+		data, _ := p.Part(sheetPart)
+
+		var escapeErr error
+		content := headerFooterPattern.ReplaceAllStringFunc(string(data), func(headerFooter string) string {
+			matches := headerFooterPattern.FindStringSubmatch(headerFooter)
+			text := html.UnescapeString(matches[2])
+			redactedText := redactText(text)
+			if redactedText == text {
+				return headerFooter
+			}
+			escaped, err := escapeText(redactedText)
+			if err != nil {
+				escapeErr = err
+				return headerFooter
+			}
+			s.Report.RedactedHeadersFooters++
+			return matches[1] + escaped + matches[3]
+		})
+		if escapeErr != nil {
+			return escapeErr
+		}
+		p.SetPart(sheetPart, []byte(content))
+	}
+
+	return nil
+}
+
+// redactPrintTitles redacts the text of the cells in the rows and columns repeated on every
+// printed page. Formulas are left to the rules
+func (s *Sanitizer) redactPrintTitles() error {
+	f := s.File
+	redactText, err := textRedactor(s.options.PrintTitles)
+	if err != nil {
+		return err
+	}
+
+	for _, definedName := range f.GetDefinedName() {
+		if definedName.Name != printTitlesName {
+			continue
+		}
+		references := formula.GetReferences(f, definedName.Scope, definedName.RefersTo)
+
+		rows, err := f.GetRows(definedName.Scope, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return err
+		}
+		for rowIndex, row := range rows {
+			for colIndex, value := range row {
+				if value == "" || !inReferences(references, definedName.Scope, colIndex+1, rowIndex+1) {
+					continue
+				}
+				cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+				if err != nil {
+					return err
+				}
+				if err = s.redactPrintTitleCell(definedName.Scope, cellName, value, redactText); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// redactPrintTitleCell redacts the text of a cell, numbers and formulas are kept
+func (s *Sanitizer) redactPrintTitleCell(sheetName, cellName, value string, redactText func(string) string) error {
+	f := s.File
+	cellType, err := f.GetCellType(sheetName, cellName)
+	if err != nil {
+		return err
+	}
+	if cellType != excelize.CellTypeSharedString && cellType != excelize.CellTypeInlineString {
+		return nil
+	}
+	cellFormula, err := f.GetCellFormula(sheetName, cellName)
+	if err != nil || cellFormula != "" {
+		return err
+	}
+
+	redactedValue := redactText(value)
+	if redactedValue == value {
+		return nil
+	}
+	s.Report.RedactedPrintTitles++
+	return f.SetCellStr(sheetName, cellName, redactedValue)
+}
+
+// inReferences reports whether a cell is inside one of the areas
+func inReferences(references []formula.Reference, sheetName string, col, row int) bool {
+	for _, reference := range references {
+		if reference.Contains(sheetName, col, row) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newPrintedFile creates a workbook named after a client, with a header printing the file path,
+// a print title row and formulas referring to the client sheet
+func newPrintedFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", "Acme Corp")
+	f.SetSheetRow("Acme Corp", "A1", &[]any{"Salaries of Acme Corp", "Q1"})
+	f.SetSheetRow("Acme Corp", "A2", &[]any{"Jane", 5000})
+	f.NewSheet("Summary")
+	f.SetCellFormula("Summary", "A1", "SUM('Acme Corp'!B2:B9)")
+	f.SetCellValue("Summary", "A2", "Acme")
+	f.SetCellHyperLink("Summary", "A2", "'Acme Corp'!A1", "Location")
+
+	err := f.SetHeaderFooter("Acme Corp", &excelize.HeaderFooterOptions{
+		OddHeader: "&LAcme Corp&R&Z&F",
+		OddFooter: "&CPage &P",
+	})
+	if err != nil {
+		t.Fatalf("failed to set the header: %v", err)
+	}
+	err = f.SetDefinedName(&excelize.DefinedName{Name: "_xlnm.Print_Titles", RefersTo: "'Acme Corp'!$1:$1", Scope: "Acme Corp"})
+	if err != nil {
+		t.Fatalf("failed to set the print titles: %v", err)
+	}
+	err = f.SetDefinedName(&excelize.DefinedName{Name: "Salaries", RefersTo: "'Acme Corp'!$B$2:$B$9"})
+	if err != nil {
+		t.Fatalf("failed to set the defined name: %v", err)
+	}
+	return f
+}
+
+func TestPageSetup(t *testing.T) {
+	t.Run("redact the headers and footers", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{HeadersFooters: &types.TextRedaction{
+			Values: []string{"Acme Corp"}, Patterns: []string{"&[ZF]"},
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		sheet := readPart(t, sanitizer.File, "xl/worksheets/sheet1.xml")
+		assert.Equal(t, true, strings.Contains(sheet, "<oddHeader>&amp;L**redacted**&amp;R**redacted****redacted**</oddHeader>"))
+		assert.Equal(t, true, strings.Contains(sheet, "<oddFooter>&amp;CPage &amp;P</oddFooter>"))
+		assert.Equal(t, 1, sanitizer.Report.RedactedHeadersFooters)
+	})
+
+	t.Run("redact the print titles", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{PrintTitles: &types.TextRedaction{Values: []string{"Acme Corp"}}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		value, _ := sanitizer.File.GetCellValue("Acme Corp", "A1")
+		assert.Equal(t, "Salaries of **redacted**", value)
+		// Only the title rows are redacted
+		value, _ = sanitizer.File.GetCellValue("Summary", "A2")
+		assert.Equal(t, "Acme", value)
+		assert.Equal(t, 1, sanitizer.Report.RedactedPrintTitles)
+	})
+
+	t.Run("rename the sheets with a mapping", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{SheetNames: &types.SheetNames{
+			Mapping: map[string]string{"Acme Corp": "Summary", "Summary": "Client 1"},
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		f := sanitizer.File
+		assert.Equal(t, []string{"Summary", "Client 1"}, f.GetSheetList())
+		formula, _ := f.GetCellFormula("Client 1", "A1")
+		assert.Equal(t, "SUM('Summary'!B2:B9)", formula)
+		value, _ := f.GetCellValue("Summary", "A2")
+		assert.Equal(t, "Jane", value)
+		_, location, _ := f.GetCellHyperLink("Client 1", "A2")
+		assert.Equal(t, "'Summary'!A1", location)
+
+		for _, definedName := range f.GetDefinedName() {
+			switch definedName.Name {
+			case "Salaries":
+				assert.Equal(t, "'Summary'!$B$2:$B$9", definedName.RefersTo)
+			case "_xlnm.Print_Titles":
+				assert.Equal(t, "Summary", definedName.Scope)
+			}
+		}
+		assert.Equal(t, false, strings.Contains(readPart(t, f, "docProps/app.xml"), "Acme"))
+		assert.Equal(t, []types.RenamedSheet{
+			{SheetName: "Acme Corp", NewName: "Summary"},
+			{SheetName: "Summary", NewName: "Client 1"},
+		}, sanitizer.Report.RenamedSheets)
+	})
+
+	t.Run("rename the matching sheets with a pattern", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{SheetNames: &types.SheetNames{
+			Match: &types.TextRedaction{Patterns: []string{"(?i)corp"}},
+		}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, []string{"Sheet1", "Summary"}, sanitizer.File.GetSheetList())
+		formula, _ := sanitizer.File.GetCellFormula("Summary", "A1")
+		assert.Equal(t, "SUM('Sheet1'!B2:B9)", formula)
+	})
+
+	t.Run("invalid sheet name", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{SheetNames: &types.SheetNames{
+			Mapping: map[string]string{"Summary": "Q1/Q2"},
+		}})
+		err := sanitizer.Execute()
+		assert.Equal(t, "'Q1/Q2' is not a valid sheet name", err.Error())
+	})
+
+	t.Run("duplicate sheet names", func(t *testing.T) {
+		sanitizer := MakeSanitizer(newPrintedFile(t), &types.Sanitize{SheetNames: &types.SheetNames{
+			Mapping: map[string]string{"Acme Corp": "summary"},
+		}})
+		err := sanitizer.Execute()
+		assert.Equal(t, "renaming the sheets would give two sheets the name 'Summary'", err.Error())
+	})
+}
@@ -0,0 +1,189 @@
+package sanitize
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Constants for the picture modes, along with REMOVE
+const (
+	REDACTED string = "REDACTED"
+)
+
+// RemovesExcludedPictures reports whether the rules should delete the pictures anchored in
+// the rows and columns they exclude, excelize would otherwise move them to the neighbouring cells
+func RemovesExcludedPictures(options *types.Sanitize) bool {
+	return options != nil && strings.ToUpper(options.Pictures) == REDACTED
+}
+
+// removeExcludedPictures deletes the pictures anchored in a hidden row or column about to be
+// removed, like the rules do for the rows and columns they exclude
+func (s *Sanitizer) removeExcludedPictures(sheetName string, removedCol, removedRow int) error {
+	if !RemovesExcludedPictures(s.options) {
+		return nil
+	}
+
+	removed, err := sheet.DeletePictures(s.File, sheetName, func(col, row int) bool {
+		return col == removedCol || row == removedRow
+	})
+	s.Report.RemovedPictures += removed
+	return err
+}
+
+// Pictures are removed textually from the drawings, along with the pictures inside groups
+// which excelize does not see
+var (
+	pictureElementPattern  = regexp.MustCompile(`(?s)<xdr:pic\b.*?</xdr:pic>`)
+	drawingObjectPattern   = regexp.MustCompile(`<xdr:(?:sp|cxnSp|graphicFrame)\b`)
+	anchorFromPattern      = regexp.MustCompile(`(?s)<xdr:from>\s*<xdr:col>(\d+)</xdr:col>.*?<xdr:row>(\d+)</xdr:row>`)
+	anchorToPattern        = regexp.MustCompile(`(?s)<xdr:to>\s*<xdr:col>(\d+)</xdr:col>.*?<xdr:row>(\d+)</xdr:row>`)
+	relationshipIDsPattern = regexp.MustCompile(`\br:(?:id|embed|link)="([^"]*)"`)
+)
+
+// anchorArea returns the cells an anchor covers, numbered from 1. Absolute anchors are not
+// placed on cells
+func anchorArea(anchor string) (startCol, startRow, endCol, endRow int, ok bool) {
+	from := anchorFromPattern.FindStringSubmatch(anchor)
+	if from == nil {
+		return 0, 0, 0, 0, false
+	}
+	startCol, _ = strconv.Atoi(from[1])
+	startRow, _ = strconv.Atoi(from[2])
+	endCol, endRow = startCol, startRow
+	if to := anchorToPattern.FindStringSubmatch(anchor); to != nil {
+		endCol, _ = strconv.Atoi(to[1])
+		endRow, _ = strconv.Atoi(to[2])
+	}
+	return startCol + 1, startRow + 1, endCol + 1, endRow + 1, true
+}
+
+// removePictures removes every picture, or the pictures covering a redacted cell
+func (s *Sanitizer) removePictures() error {
+	mode := strings.ToUpper(s.options.Pictures)
+	if mode != REMOVE && mode != REDACTED {
+		return fmt.Errorf("'%s' is not a valid pictures mode", s.options.Pictures)
+	}
+
+	// The pictures of the excluded rows and columns were removed by the rules
+	if s.Manifest != nil {
+		s.Report.RemovedPictures += s.Manifest.RemovedPictures
+	}
+
+	redactedCells := map[string]bool{}
+	if s.Manifest != nil {
+		for _, redactedCell := range s.Manifest.RedactedCells {
+			if !redactedCell.Excluded {
+				redactedCells[redactedCell.SheetName+"!"+redactedCell.Cell] = true
+			}
+		}
+	}
+	// The cells are read from the workbook as it was before the package is edited
+	f := s.File
+	coversRedactedCell := func(sheetName, anchor string) bool {
+		startCol, startRow, endCol, endRow, ok := anchorArea(anchor)
+		if !ok {
+			return false
+		}
+		for row := startRow; row <= endRow; row++ {
+			for col := startCol; col <= endCol; col++ {
+				cellName, err := excelize.CoordinatesToCellName(col, row)
+				if err != nil {
+					return false
+				}
+				if redactedCells[sheetName+"!"+cellName] {
+					return true
+				}
+				// Cells redacted without a value to record are only known by their content
+				if cellValue, err := f.GetCellValue(sheetName, cellName); err == nil && cellValue == "**redacted**" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return s.editPackage(func(p *ooxml.Package) error {
+		sheetNames, err := p.SheetNames()
+		if err != nil {
+			return err
+		}
+		for _, sheetPart := range p.Parts() {
+			sheetName, isSheet := sheetNames[sheetPart]
+			if !isSheet {
+				continue
+			}
+			drawings, err := p.RelatedParts(sheetPart, "/drawing")
+			if err != nil {
+				return err
+			}
+			for _, drawing := range drawings {
+				data, _ := p.Part(drawing)
+				content := anchorPattern.ReplaceAllStringFunc(string(data), func(anchor string) string {
+					pictures := len(pictureElementPattern.FindAllString(anchor, -1))
+					if pictures == 0 || mode == REDACTED && !coversRedactedCell(sheetName, anchor) {
+						return anchor
+					}
+					s.Report.RemovedPictures += pictures
+					anchor = pictureElementPattern.ReplaceAllString(anchor, "")
+					// A group keeps its other shapes, an anchor left empty is removed
+					if !drawingObjectPattern.MatchString(anchor) {
+						return ""
+					}
+					return anchor
+				})
+				p.SetPart(drawing, []byte(content))
+				if err = removeUnusedRelationships(p, drawing, content); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// removeUnusedRelationships drops the relationships of a drawing no element refers to anymore,
+// along with the images nothing else uses
+func removeUnusedRelationships(p *ooxml.Package, drawing, content string) error {
+	used := map[string]bool{}
+	for _, matches := range relationshipIDsPattern.FindAllStringSubmatch(content, -1) {
+		used[matches[1]] = true
+	}
+	relsName := ooxml.RelsPath(drawing)
+	relationships, err := p.Relationships(relsName)
+	if err != nil {
+		return err
+	}
+
+	var unusedParts []string
+	for _, relationship := range relationships.Relationships {
+		if used[relationship.ID] {
+			continue
+		}
+		if err = p.RemoveRelationship(relsName, relationship.ID); err != nil {
+			return err
+		}
+		if relationship.TargetMode != "External" {
+			unusedParts = append(unusedParts, ooxml.ResolveTarget(relsName, relationship.Target))
+		}
+	}
+	for _, part := range unusedParts {
+		references, err := p.ReferencesTo(part)
+		if err != nil {
+			return err
+		}
+		if len(references) == 0 {
+			if err = p.RemovePart(part); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
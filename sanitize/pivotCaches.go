@@ -0,0 +1,256 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/transform"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Pivot parts are edited textually, the elements changed here never nest
+var (
+	pivotCachePartPattern  = regexp.MustCompile(`^xl/pivotCache/pivotCacheDefinition\d+\.xml$`)
+	pivotTablePartPattern  = regexp.MustCompile(`^xl/pivotTables/pivotTable\d+\.xml$`)
+	worksheetSourcePattern = regexp.MustCompile(`<worksheetSource\b[^>]*>`)
+	cacheDefinitionPattern = regexp.MustCompile(`<pivotCacheDefinition\b[^>]*>`)
+	pivotTablePattern      = regexp.MustCompile(`<pivotTableDefinition\b[^>]*>`)
+	locationPattern        = regexp.MustCompile(`<location\b[^>]*>`)
+	relationshipIDPattern  = regexp.MustCompile(`\sr:id="[^"]*"`)
+	refreshOnLoadPattern   = regexp.MustCompile(`\srefreshOnLoad="[^"]*"`)
+	sharedItemsPattern     = regexp.MustCompile(`(?s)<sharedItems\b[^>]*/>|<sharedItems\b[^>]*>.*?</sharedItems>`)
+	fieldGroupPattern      = regexp.MustCompile(`(?s)<fieldGroup\b[^>]*/>|<fieldGroup\b[^>]*>.*?</fieldGroup>`)
+	pivotItemsPattern      = regexp.MustCompile(`(?s)<(?:items|rowItems|colItems)\b[^>]*/>|<(?:items|rowItems|colItems)\b[^>]*>.*?</(?:items|rowItems|colItems)>`)
+	pageItemPattern        = regexp.MustCompile(`(<pageField\b[^>]*?)\sitem="\d+"`)
+)
+
+// pivotArea is where a removed or refreshed pivot table showed its values, numbered as in the original workbook
+type pivotArea struct {
+	sheetName string
+	ref       string
+}
+
+// scrubPivotCaches applies the pivot caches policy to every pivot cache whose source holds
+// redacted or excluded cells, along with the pivot tables using it
+func (s *Sanitizer) scrubPivotCaches(p *ooxml.Package) error {
+	policy := strings.ToUpper(s.options.PivotCaches)
+	sheetNames, err := p.SheetNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range p.Parts() {
+		if !pivotCachePartPattern.MatchString(name) {
+			continue
+		}
+		data, _ := p.Part(name)
+		if !s.duplicatesRedactedData(s.pivotSourceReferences(worksheetSourcePattern.FindString(string(data)))) {
+			continue
+		}
+
+		pivotTables, err := pivotTablesOfCache(p, name)
+		if err != nil {
+			return err
+		}
+		for _, pivotTable := range pivotTables {
+			sheetName, err := pivotTableSheetName(p, pivotTable, sheetNames)
+			if err != nil {
+				return err
+			}
+			pivotTableData, _ := p.Part(pivotTable)
+			pivotTableName := ooxml.Attribute(pivotTablePattern.FindString(string(pivotTableData)), "name")
+
+			ref := ooxml.Attribute(locationPattern.FindString(string(pivotTableData)), "ref")
+			switch policy {
+			case REFRESH:
+				// The items of the fields point into the shared items of the cache. The rendered
+				// values are cleared too, Excel shows them again once the cache is refreshed
+				p.SetPart(pivotTable, []byte(clearPivotItems(string(pivotTableData))))
+				s.pivotAreas = append(s.pivotAreas, pivotArea{sheetName, ref})
+			case REMOVE:
+				s.pivotAreas = append(s.pivotAreas, pivotArea{sheetName, ref})
+				fallthrough
+			case STATIC:
+				if err = p.RemovePart(pivotTable); err != nil {
+					return err
+				}
+			}
+			s.addScrubbedCache(pivotTableCache, sheetName, pivotTableName, policy)
+			if policy == STATIC {
+				// The values of the pivot table are plain cells of the sheet, they stay in place
+				s.Report.ScrubbedCaches[len(s.Report.ScrubbedCaches)-1].Range = ref
+			}
+		}
+
+		if policy == REFRESH {
+			err = refreshPivotCache(p, name)
+		} else {
+			err = removePivotCache(p, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pivotSourceReferences returns the source area of a pivot cache, given as a sheet and a range,
+// as a defined name or as a table
+func (s *Sanitizer) pivotSourceReferences(worksheetSource string) []formula.Reference {
+	if worksheetSource == "" {
+		return nil
+	}
+
+	name := ooxml.Attribute(worksheetSource, "name")
+	if name == "" {
+		startCol, startRow, endCol, endRow, ok := formula.ParseArea(ooxml.Attribute(worksheetSource, "ref"))
+		if !ok {
+			return nil
+		}
+		return []formula.Reference{{
+			SheetName: ooxml.Attribute(worksheetSource, "sheet"),
+			StartCol:  startCol,
+			StartRow:  startRow,
+			EndCol:    endCol,
+			EndRow:    endRow,
+		}}
+	}
+
+	if references := formula.GetReferences(s.File, "", name); len(references) > 0 {
+		return references
+	}
+	for _, sheetName := range s.File.GetSheetList() {
+		tables, err := s.File.GetTables(sheetName)
+		if err != nil {
+			continue
+		}
+		for _, table := range tables {
+			if table.Name == name {
+				return formula.GetReferences(s.File, sheetName, table.Range)
+			}
+		}
+	}
+	return nil
+}
+
+// pivotTablesOfCache returns the pivot tables built on a pivot cache
+func pivotTablesOfCache(p *ooxml.Package, cacheName string) ([]string, error) {
+	references, err := p.ReferencesTo(cacheName)
+	if err != nil {
+		return nil, err
+	}
+
+	var pivotTables []string
+	for _, reference := range references {
+		if pivotTablePartPattern.MatchString(reference.Source) {
+			pivotTables = append(pivotTables, reference.Source)
+		}
+	}
+	return pivotTables, nil
+}
+
+// pivotTableSheetName returns the sheet showing a pivot table, empty when it is not found
+func pivotTableSheetName(p *ooxml.Package, pivotTable string, sheetNames map[string]string) (string, error) {
+	sheets, err := p.ReferencesTo(pivotTable)
+	if err != nil {
+		return "", err
+	}
+	for _, sheet := range sheets {
+		if sheetName, found := sheetNames[sheet.Source]; found {
+			return sheetName, nil
+		}
+	}
+	return "", nil
+}
+
+// clearPivotItems drops the items of the fields and the rendered row and column items of a
+// pivot table, they are rebuilt when the pivot cache is refreshed
+func clearPivotItems(pivotTable string) string {
+	pivotTable = pivotItemsPattern.ReplaceAllString(pivotTable, "")
+	return pageItemPattern.ReplaceAllString(pivotTable, "$1")
+}
+
+// refreshPivotCache removes the cached records and values of a pivot cache and has it
+// refreshed when the workbook is opened
+func refreshPivotCache(p *ooxml.Package, cacheName string) error {
+	records, err := p.RelatedParts(cacheName, "/pivotCacheRecords")
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err = p.RemovePart(record); err != nil {
+			return err
+		}
+	}
+
+	data, _ := p.Part(cacheName)
+	content := cacheDefinitionPattern.ReplaceAllStringFunc(string(data), func(definition string) string {
+		definition = relationshipIDPattern.ReplaceAllString(definition, "")
+		definition = refreshOnLoadPattern.ReplaceAllString(definition, "")
+		return strings.Replace(definition, "<pivotCacheDefinition", `<pivotCacheDefinition refreshOnLoad="1"`, 1)
+	})
+	content = sharedItemsPattern.ReplaceAllString(content, "<sharedItems/>")
+	content = fieldGroupPattern.ReplaceAllString(content, "")
+	p.SetPart(cacheName, []byte(content))
+
+	return nil
+}
+
+// removePivotCache removes a pivot cache with its records and its entry in the workbook
+func removePivotCache(p *ooxml.Package, cacheName string) error {
+	references, err := p.ReferencesTo(cacheName)
+	if err != nil {
+		return err
+	}
+	for _, reference := range references {
+		if reference.Source != "xl/workbook.xml" {
+			continue
+		}
+		data, _ := p.Part(reference.Source)
+		entryPattern := regexp.MustCompile(`<pivotCache\b[^>]*\sr:id="` + regexp.QuoteMeta(reference.ID) + `"[^>]*(?:/>|>\s*</pivotCache>)`)
+		content := entryPattern.ReplaceAllString(string(data), "")
+		content = regexp.MustCompile(`<pivotCaches\s*/>|<pivotCaches>\s*</pivotCaches>`).ReplaceAllString(content, "")
+		p.SetPart(reference.Source, []byte(content))
+	}
+
+	records, err := p.RelatedParts(cacheName, "/pivotCacheRecords")
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err = p.RemovePart(record); err != nil {
+			return err
+		}
+	}
+	return p.RemovePart(cacheName)
+}
+
+// clearPivotAreas clears the cells where the removed and refreshed pivot tables showed their values
+func (s *Sanitizer) clearPivotAreas() error {
+	for _, area := range s.pivotAreas {
+		startCol, startRow, endCol, endRow, ok := formula.ParseArea(area.ref)
+		if !ok || area.sheetName == "" {
+			continue
+		}
+		for row := startRow; row <= endRow; row++ {
+			for col := startCol; col <= endCol; col++ {
+				currentCol, currentRow, excluded := transform.CurrentCoordinates(s.Manifest, area.sheetName, col, row)
+				if excluded {
+					continue
+				}
+				cellName, err := excelize.CoordinatesToCellName(currentCol, currentRow)
+				if err != nil {
+					return err
+				}
+				if err = s.File.SetCellValue(area.sheetName, cellName, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/pkg/verify"
+)
+
+const sharedStringsPath = "xl/sharedStrings.xml"
+
+// Cells and shared strings are edited textually, neither of them nests
+var (
+	sharedStringsTagPattern = regexp.MustCompile(`<sst\b[^>]*>`)
+	calcPrPattern           = regexp.MustCompile(`<calcPr\b[^>]*>`)
+)
+
+// purges reports whether the output needs purging, the redactions and the sanitize options leave
+// the original strings behind
+func (s *Sanitizer) purges() bool {
+	if s.options != nil {
+		return true
+	}
+	return s.Manifest != nil &&
+		(len(s.Manifest.RedactedCells) > 0 || len(s.Manifest.ScrubbedFormulas) > 0 || len(s.Manifest.Exclusions) > 0)
+}
+
+// redactedOriginals returns the original values of the redacted cells and scrubbed formulas
+func (s *Sanitizer) redactedOriginals() []string {
+	if s.Manifest == nil {
+		return nil
+	}
+	var originals []string
+	seen := map[string]bool{}
+	for _, redactedCell := range slices.Concat(s.Manifest.RedactedCells, s.Manifest.ScrubbedFormulas) {
+		value := strings.TrimSpace(redactedCell.Value)
+		if value == "" || value == "**redacted**" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		originals = append(originals, value)
+	}
+	return originals
+}
+
+// purge rebuilds the shared string table from the live cells and clears the cached values of
+// the formulas still holding a redacted original, then verifies the output when asked to
+func (s *Sanitizer) purge() error {
+	if !s.purges() {
+		return nil
+	}
+	originals := s.redactedOriginals()
+
+	return s.editPackage(func(p *ooxml.Package) error {
+		sheetNames, err := p.SheetNames()
+		if err != nil {
+			return err
+		}
+		var sheetParts []string
+		for _, name := range p.Parts() {
+			if _, isSheet := sheetNames[name]; isSheet {
+				sheetParts = append(sheetParts, name)
+			}
+		}
+
+		if err = s.clearStaleCachedValues(p, sheetParts, originals); err != nil {
+			return err
+		}
+		if err = s.purgeSharedStrings(p, sheetParts); err != nil {
+			return err
+		}
+		if s.options != nil && s.options.VerifyRedaction {
+			return s.verifyRedaction(p)
+		}
+		return nil
+	})
+}
+
+// purgeSharedStrings drops the shared strings no cell uses anymore, the remaining ones keep
+// their order and the cells are numbered again
+func (s *Sanitizer) purgeSharedStrings(p *ooxml.Package, sheetParts []string) error {
+	data, found := p.Part(sharedStringsPath)
+	if !found {
+		return nil
+	}
+	content := string(data)
+	itemIndexes := ooxml.SharedStringItemPattern.FindAllStringIndex(content, -1)
+
+	used := make([]bool, len(itemIndexes))
+	count := 0
+	for _, sheetPart := range sheetParts {
+		sheet, _ := p.Part(sheetPart)
+		for _, cell := range ooxml.CellPattern.FindAllString(string(sheet), -1) {
+			if index, isShared := ooxml.SharedStringIndex(cell); isShared && index < len(used) {
+				used[index] = true
+				count++
+			}
+		}
+	}
+
+	renumbered := make([]int, len(itemIndexes))
+	var keptItems strings.Builder
+	kept := 0
+	for index, itemIndex := range itemIndexes {
+		if !used[index] {
+			continue
+		}
+		renumbered[index] = kept
+		keptItems.WriteString(content[itemIndex[0]:itemIndex[1]])
+		kept++
+	}
+	if kept == len(itemIndexes) {
+		return nil
+	}
+	s.Report.PurgedSharedStrings = len(itemIndexes) - kept
+
+	for _, sheetPart := range sheetParts {
+		sheet, _ := p.Part(sheetPart)
+		p.SetPart(sheetPart, []byte(ooxml.CellPattern.ReplaceAllStringFunc(string(sheet), func(cell string) string {
+			index, isShared := ooxml.SharedStringIndex(cell)
+			if !isShared || index >= len(renumbered) {
+				return cell
+			}
+			return ooxml.CellValuePattern.ReplaceAllLiteralString(cell, "<v>"+strconv.Itoa(renumbered[index])+"</v>")
+		})))
+	}
+
+	// The items are replaced, whatever follows them such as extensions is kept
+	startTag := sharedStringsTagPattern.FindStringIndex(content)
+	if startTag == nil {
+		return fmt.Errorf("failed to read %s", sharedStringsPath)
+	}
+	tail := content[startTag[1]:]
+	if len(itemIndexes) > 0 {
+		tail = content[itemIndexes[len(itemIndexes)-1][1]:]
+	}
+	root := ooxml.SetAttribute(content[startTag[0]:startTag[1]], "count", strconv.Itoa(count))
+	root = ooxml.SetAttribute(root, "uniqueCount", strconv.Itoa(kept))
+	p.SetPart(sharedStringsPath, []byte(content[:startTag[0]]+root+keptItems.String()+tail))
+
+	return nil
+}
+
+// clearStaleCachedValues drops the cached values of the formulas that still show a redacted
+// original. Excel computes them again when the workbook is opened
+func (s *Sanitizer) clearStaleCachedValues(p *ooxml.Package, sheetParts []string, originals []string) error {
+	if len(originals) == 0 {
+		return nil
+	}
+	isOriginal := map[string]bool{}
+	for _, original := range originals {
+		isOriginal[original] = true
+	}
+
+	cleared := 0
+	for _, sheetPart := range sheetParts {
+		sheet, _ := p.Part(sheetPart)
+		p.SetPart(sheetPart, []byte(ooxml.CellPattern.ReplaceAllStringFunc(string(sheet), func(cell string) string {
+			if !strings.Contains(cell, "<f>") && !strings.Contains(cell, "<f ") {
+				return cell
+			}
+			match := ooxml.CellValuePattern.FindStringSubmatch(cell)
+			if match == nil || !isOriginal[strings.TrimSpace(html.UnescapeString(match[1]))] {
+				return cell
+			}
+			cleared++
+			return ooxml.CellValuePattern.ReplaceAllLiteralString(cell, "")
+		})))
+	}
+	if cleared == 0 {
+		return nil
+	}
+	s.Report.ClearedCachedValues = cleared
+
+	workbook, _ := p.Part(workbookPath)
+	content := calcPrPattern.ReplaceAllStringFunc(string(workbook), func(calcPr string) string {
+		if ooxml.Attribute(calcPr, "fullCalcOnLoad") != "" {
+			return ooxml.SetAttribute(calcPr, "fullCalcOnLoad", "1")
+		}
+		return strings.Replace(calcPr, "<calcPr", `<calcPr fullCalcOnLoad="1"`, 1)
+	})
+	p.SetPart(workbookPath, []byte(content))
+	return nil
+}
+
+// verifyRedaction looks for the redacted originals in the traces of their cells, the cells are
+// looked up by the names the sheets have now. The originals are not part of the error, which may
+// be sent to a webhook
+func (s *Sanitizer) verifyRedaction(p *ooxml.Package) error {
+	if s.Manifest == nil {
+		return nil
+	}
+	newNames := map[string]string{}
+	for _, renamedSheet := range s.Report.RenamedSheets {
+		newNames[renamedSheet.SheetName] = renamedSheet.NewName
+	}
+	var redactedCells []types.RedactedCell
+	for _, redactedCell := range slices.Concat(s.Manifest.RedactedCells, s.Manifest.ScrubbedFormulas) {
+		if newName, renamed := newNames[redactedCell.SheetName]; renamed {
+			redactedCell.SheetName = newName
+		}
+		redactedCells = append(redactedCells, redactedCell)
+	}
+
+	hits, err := verify.CheckTraces(s.File, p, redactedCells, verify.ValueMatcher)
+	if err != nil {
+		return err
+	}
+	var residualParts []string
+	for _, hit := range hits {
+		if !slices.Contains(residualParts, hit.Part) {
+			residualParts = append(residualParts, hit.Part)
+		}
+	}
+	if len(residualParts) > 0 {
+		return fmt.Errorf("redacted values remain in the output: %s", strings.Join(residualParts, ", "))
+	}
+	return nil
+}
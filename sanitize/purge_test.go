@@ -0,0 +1,117 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newRedactedFile creates a workbook whose A2 was redacted, with a formula still caching its value
+func newRedactedFile(t *testing.T) (*excelize.File, *types.Manifest) {
+	f := excelize.NewFile()
+	f.SetSheetCol("Sheet1", "A1", &[]any{"Name", "Jane Doe", "John Roe"})
+	f.SetCellFormula("Sheet1", "B2", "A2")
+	// Redacting leaves the original in the shared strings
+	f.SetCellValue("Sheet1", "A2", "**redacted**")
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+	sheet, _ := p.Part("xl/worksheets/sheet1.xml")
+	p.SetPart("xl/worksheets/sheet1.xml", []byte(strings.Replace(string(sheet),
+		`<f>A2</f></c>`, `<f>A2</f><v>Jane Doe</v></c>`, 1)))
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	f, err = file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f, &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "A2", Value: "Jane Doe"}}}
+}
+
+func TestPurge(t *testing.T) {
+	t.Run("purge the orphaned shared strings and the stale cached values", func(t *testing.T) {
+		f, manifest := newRedactedFile(t)
+		sanitizer := MakeSanitizer(f, nil)
+		sanitizer.Manifest = manifest
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		sharedStrings, _ := p.Part("xl/sharedStrings.xml")
+		assert.Equal(t, false, strings.Contains(string(sharedStrings), "Jane Doe"))
+		sheet, _ := p.Part("xl/worksheets/sheet1.xml")
+		assert.Equal(t, false, strings.Contains(string(sheet), "Jane Doe"))
+		// Excel computes the cleared values when the workbook is opened
+		workbook, _ := p.Part("xl/workbook.xml")
+		assert.Equal(t, true, strings.Contains(string(workbook), `fullCalcOnLoad="true"`))
+
+		cols, _ := sanitizer.File.GetCols("Sheet1")
+		assert.Equal(t, []string{"Name", "**redacted**", "John Roe"}, cols[0])
+		formula, _ := sanitizer.File.GetCellFormula("Sheet1", "B2")
+		assert.Equal(t, "A2", formula)
+		assert.Equal(t, 1, sanitizer.Report.PurgedSharedStrings)
+		assert.Equal(t, 1, sanitizer.Report.ClearedCachedValues)
+	})
+
+	t.Run("nothing to purge without redactions", func(t *testing.T) {
+		f, _ := newRedactedFile(t)
+		sanitizer := MakeSanitizer(f, nil)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		assert.Equal(t, 0, sanitizer.Report.PurgedSharedStrings)
+	})
+
+	t.Run("verify the redaction", func(t *testing.T) {
+		f, manifest := newRedactedFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{VerifyRedaction: true})
+		sanitizer.Manifest = manifest
+		assert.Equal(t, nil, sanitizer.Execute())
+	})
+
+	t.Run("fail when a redacted original remains in a trace of its cell", func(t *testing.T) {
+		f, manifest := newRedactedFile(t)
+		f.AddComment("Sheet1", excelize.Comment{Cell: "A2", Author: "Auditor", Text: "Checked with Jane Doe"})
+		f.SetDefinedName(&excelize.DefinedName{Name: "Contact", RefersTo: `"Jane Doe"`})
+		sanitizer := MakeSanitizer(f, &types.Sanitize{VerifyRedaction: true})
+		sanitizer.Manifest = manifest
+
+		err := sanitizer.Execute()
+		assert.Equal(t, "redacted values remain in the output: xl/comments1.xml, xl/workbook.xml", err.Error())
+	})
+
+	t.Run("ignore the originals held by unrelated cells", func(t *testing.T) {
+		f, manifest := newRedactedFile(t)
+		manifest.RedactedCells[0].Value = "Jane"
+		f.SetCellValue("Sheet1", "C1", "Paid to Jane Doe")
+		f.NewSheet("Sheet2")
+		f.SetCellValue("Sheet2", "A1", "Janet Smith")
+		sanitizer := MakeSanitizer(f, &types.Sanitize{VerifyRedaction: true})
+		sanitizer.Manifest = manifest
+		assert.Equal(t, nil, sanitizer.Execute())
+	})
+
+	t.Run("skip the verification unless asked", func(t *testing.T) {
+		f, manifest := newRedactedFile(t)
+		f.AddComment("Sheet1", excelize.Comment{Cell: "A2", Author: "Auditor", Text: "Checked with Jane Doe"})
+		sanitizer := MakeSanitizer(f, nil)
+		sanitizer.Manifest = manifest
+		assert.Equal(t, nil, sanitizer.Execute())
+	})
+}
@@ -0,0 +1,226 @@
+package sanitize
+
+import (
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/link"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sanitizer cleans up the workbook metadata before the file is delivered
+type Sanitizer struct {
+	File    *excelize.File
+	options *types.Sanitize
+	// Report lists what was found in the workbook
+	Report *types.SanitizeReport
+	// Manifest of the rules, kept in sync when rows, columns or sheets are removed. It is optional
+	Manifest *types.Manifest
+	// redactedPositions are the redacted cells of every sheet, numbered as in the original workbook
+	redactedPositions map[string][]position
+	// pivotAreas are cleared once the pivot tables are removed or refreshed
+	pivotAreas []pivotArea
+	// The compiled link policies, nil when not set
+	hyperlinks    *linkPolicy
+	externalLinks *linkPolicy
+	connections   *linkPolicy
+	// removedExternalLinks are selected before the formulas using them are flattened
+	removedExternalLinks []link.ExternalLink
+}
+
+func MakeSanitizer(file *excelize.File, options *types.Sanitize) *Sanitizer {
+	return &Sanitizer{
+		File:    file,
+		options: options,
+		Report:  &types.SanitizeReport{},
+	}
+}
+
+// Execute applies the sanitize options. Removing package parts reopens the workbook,
+// so File must be read again afterwards
+func (s *Sanitizer) Execute() error {
+	// Macros are reported whether or not they are removed
+	macros, err := detectMacros(s.File)
+	if err != nil {
+		return err
+	}
+	s.Report.Macros = macros
+
+	if s.options != nil {
+		if err = s.sanitize(); err != nil {
+			return err
+		}
+	}
+
+	// The original strings are purged from the output whether or not options are given
+	return s.purge()
+}
+
+// sanitize applies the options
+func (s *Sanitizer) sanitize() error {
+	options := s.options
+	if err := s.validateCachePolicies(); err != nil {
+		return err
+	}
+	if err := s.compileLinkPolicies(); err != nil {
+		return err
+	}
+	if err := s.validateSheetNames(); err != nil {
+		return err
+	}
+
+	// Hidden content is removed first so it does not show up in the later passes
+	if options.HiddenContent != "" {
+		if err := s.handleHiddenContent(); err != nil {
+			return err
+		}
+	}
+
+	if options.Pictures != "" {
+		if err := s.removePictures(); err != nil {
+			return err
+		}
+	}
+
+	// Document properties are edited through excelize
+	if options.ClearDocProps {
+		if err := s.clearDocProps(); err != nil {
+			return err
+		}
+	}
+	if options.DocProps != nil {
+		if err := s.overwriteDocProps(); err != nil {
+			return err
+		}
+	}
+
+	if options.PrintTitles != nil {
+		if err := s.redactPrintTitles(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.editParts(); err != nil {
+		return err
+	}
+
+	// Sheets are renamed last, the previous steps look them up by their original name
+	if options.SheetNames != nil {
+		return s.renameSheets()
+	}
+	return nil
+}
+
+// editParts applies the options editing the package directly, which excelize cannot do
+func (s *Sanitizer) editParts() error {
+	options := s.options
+	editsShapes := options.RemoveShapes || options.ShapeText != nil
+	editsCaches := options.ChartCaches != "" || options.PivotCaches != ""
+	editsLinks := s.hyperlinks != nil || s.externalLinks != nil || s.connections != nil
+	removesMacros := options.RemoveMacros && s.Report.Macros != nil
+	if !options.RemoveCustomProps && !options.RemoveCustomXml && !editsShapes && !editsCaches && !editsLinks &&
+		!removesMacros && options.HeadersFooters == nil {
+		return nil
+	}
+	if s.externalLinks != nil && s.externalLinks.action == REMOVE {
+		// Formulas are flattened through excelize before the links are removed from the package
+		if err := s.prepareExternalLinks(); err != nil {
+			return err
+		}
+	}
+	if editsCaches {
+		// Redacted cells are looked up before the workbook is reopened
+		if err := s.loadRedactedPositions(); err != nil {
+			return err
+		}
+	}
+	err := s.editPackage(func(p *ooxml.Package) error {
+		if options.ChartCaches != "" {
+			if err := s.scrubChartCaches(p); err != nil {
+				return err
+			}
+		}
+		if options.PivotCaches != "" {
+			if err := s.scrubPivotCaches(p); err != nil {
+				return err
+			}
+		}
+		if editsShapes {
+			if err := s.sanitizeShapes(p); err != nil {
+				return err
+			}
+		}
+		if s.hyperlinks != nil {
+			if err := s.sanitizeHyperlinks(p); err != nil {
+				return err
+			}
+		}
+		if s.externalLinks != nil {
+			if err := s.sanitizeExternalLinks(p); err != nil {
+				return err
+			}
+		}
+		if s.connections != nil {
+			if err := s.sanitizeConnections(p); err != nil {
+				return err
+			}
+		}
+		if options.HeadersFooters != nil {
+			if err := s.redactHeadersFooters(p); err != nil {
+				return err
+			}
+		}
+		if options.RemoveCustomProps {
+			if err := p.RemovePart("docProps/custom.xml"); err != nil {
+				return err
+			}
+		}
+		if options.RemoveCustomXml {
+			if _, err := p.RemovePartsWithPrefix("customXml/"); err != nil {
+				return err
+			}
+		}
+		if removesMacros {
+			if err := s.removeMacros(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The values of the removed and refreshed pivot tables are cells of the reopened workbook
+	return s.clearPivotAreas()
+}
+
+// editPackage writes the workbook, edits its parts and reopens it
+func (s *Sanitizer) editPackage(edit func(p *ooxml.Package) error) error {
+	buffer, err := s.File.WriteToBuffer()
+	if err != nil {
+		return err
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err = edit(p); err != nil {
+		return err
+	}
+
+	fileContents, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+	f, err := file.InitFileFromBytes(fileContents)
+	if err != nil {
+		return err
+	}
+	s.File.Close()
+	s.File = f
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newMetadataFile creates a workbook with document properties, custom properties and a custom XML part
+func newMetadataFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "Data")
+	f.SetDocProps(&excelize.DocProperties{Creator: "Jane Reviewer", LastModifiedBy: "John Reviewer", Title: "Salaries"})
+	f.SetAppProps(&excelize.AppProperties{Application: "Microsoft Excel", Company: "Internal Corp"})
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+
+	// Adding the custom parts along with their relationships and content types
+	p.SetPart("docProps/custom.xml", []byte(`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties"/>`))
+	p.SetPart("customXml/item1.xml", []byte(`<reviewers><name>Jane Reviewer</name></reviewers>`))
+	rootRels, _ := p.Part("_rels/.rels")
+	p.SetPart("_rels/.rels", []byte(strings.Replace(string(rootRels), "</Relationships>",
+		`<Relationship Id="rIdCustom" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties" Target="docProps/custom.xml"/></Relationships>`, 1)))
+	workbookRels, _ := p.Part("xl/_rels/workbook.xml.rels")
+	p.SetPart("xl/_rels/workbook.xml.rels", []byte(strings.Replace(string(workbookRels), "</Relationships>",
+		`<Relationship Id="rIdXml" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml" Target="../customXml/item1.xml"/></Relationships>`, 1)))
+	contentTypes, _ := p.Part("[Content_Types].xml")
+	p.SetPart("[Content_Types].xml", []byte(strings.Replace(string(contentTypes), "</Types>",
+		`<Override PartName="/docProps/custom.xml" ContentType="application/vnd.openxmlformats-officedocument.custom-properties+xml"/></Types>`, 1)))
+
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	f, err = file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f
+}
+
+// readPackage reads the parts of the sanitized workbook
+func readPackage(t *testing.T, f *excelize.File) *ooxml.Package {
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+	return p
+}
+
+func TestSanitizer(t *testing.T) {
+	t.Run("no options leave the file untouched", func(t *testing.T) {
+		f := newMetadataFile(t)
+		sanitizer := MakeSanitizer(f, nil)
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		docProps, _ := sanitizer.File.GetDocProps()
+		assert.Equal(t, "Jane Reviewer", docProps.Creator)
+	})
+
+	t.Run("clear and overwrite the document properties", func(t *testing.T) {
+		f := newMetadataFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{
+			ClearDocProps: true,
+			DocProps:      &types.DocProps{Creator: "Records Team"},
+		})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		docProps, _ := sanitizer.File.GetDocProps()
+		assert.Equal(t, "Records Team", docProps.Creator)
+		assert.Equal(t, "", docProps.LastModifiedBy)
+		assert.Equal(t, "", docProps.Title)
+		appProps, _ := sanitizer.File.GetAppProps()
+		assert.Equal(t, "", appProps.Company)
+		assert.Equal(t, "Microsoft Excel", appProps.Application)
+	})
+
+	t.Run("overwrite the manager and the company", func(t *testing.T) {
+		f := newMetadataFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{
+			DocProps: &types.DocProps{Manager: "Records & Archives", Company: "Acme"},
+		})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		appProps, _ := sanitizer.File.GetAppProps()
+		assert.Equal(t, "Acme", appProps.Company)
+		p := readPackage(t, sanitizer.File)
+		app, _ := p.Part("docProps/app.xml")
+		assert.Equal(t, 1, strings.Count(string(app), "<Manager>Records &amp; Archives</Manager>"))
+
+		// A manager already set is replaced
+		sanitizer = MakeSanitizer(sanitizer.File, &types.Sanitize{DocProps: &types.DocProps{Manager: "Legal"}})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+		p = readPackage(t, sanitizer.File)
+		app, _ = p.Part("docProps/app.xml")
+		assert.Equal(t, 1, strings.Count(string(app), "<Manager>"))
+		assert.Equal(t, true, strings.Contains(string(app), "<Manager>Legal</Manager>"))
+	})
+
+	t.Run("remove the custom properties and the custom XML", func(t *testing.T) {
+		f := newMetadataFile(t)
+		sanitizer := MakeSanitizer(f, &types.Sanitize{RemoveCustomProps: true, RemoveCustomXml: true})
+		if err := sanitizer.Execute(); err != nil {
+			t.Fatalf("failed to sanitize: %v", err)
+		}
+
+		p := readPackage(t, sanitizer.File)
+		for _, name := range p.Parts() {
+			assert.Equal(t, false, name == "docProps/custom.xml" || strings.HasPrefix(name, "customXml/"))
+		}
+		rootRels, _ := p.Part("_rels/.rels")
+		assert.Equal(t, false, strings.Contains(string(rootRels), "custom.xml"))
+		workbookRels, _ := p.Part("xl/_rels/workbook.xml.rels")
+		assert.Equal(t, false, strings.Contains(string(workbookRels), "customXml"))
+		contentTypes, _ := p.Part("[Content_Types].xml")
+		assert.Equal(t, false, strings.Contains(string(contentTypes), "custom.xml"))
+
+		// The cells survive the round trip
+		value, _ := sanitizer.File.GetCellValue("Sheet1", "A1")
+		assert.Equal(t, "Data", value)
+	})
+}
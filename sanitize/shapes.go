@@ -0,0 +1,175 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"xlsx-processor/pkg/matcher"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+)
+
+// Drawings are edited textually since excelize has no support for shapes. Anchors never nest,
+// so removing one leaves the rest of the drawing as it is
+var (
+	drawingPartPattern = regexp.MustCompile(`^xl/drawings/drawing\d+\.xml$`)
+	anchorPattern      = regexp.MustCompile(`(?s)<xdr:(?:twoCellAnchor|oneCellAnchor|absoluteAnchor)\b.*?</xdr:(?:twoCellAnchor|oneCellAnchor|absoluteAnchor)>`)
+	paragraphPattern   = regexp.MustCompile(`(?s)<a:p(?:\s[^>]*)?>.*?</a:p>`)
+	textRunPattern     = regexp.MustCompile(`(?s)(<a:t(?:\s[^>]*)?>)(.*?)(</a:t>)`)
+)
+
+// isShapeAnchor reports whether an anchor holds a shape, a text box, a connector or a group
+// rather than a single picture or a chart. Groups count as shapes even when they hold pictures
+func isShapeAnchor(anchor string) bool {
+	if strings.Contains(anchor, "<xdr:grpSp") {
+		return true
+	}
+	return !strings.Contains(anchor, "<xdr:pic>") && !strings.Contains(anchor, "<xdr:pic ") &&
+		!strings.Contains(anchor, "<xdr:graphicFrame")
+}
+
+// textMatcher returns the start and end of the parts of a text to redact, sorted and not
+// overlapping
+type textMatcher func(text string) [][]int
+
+// newTextMatcher builds the matcher of the values, the patterns and the PII detectors of the
+// options, the detectors are those of the scan
+func newTextMatcher(options *types.TextRedaction) (textMatcher, error) {
+	patterns := make([]*regexp.Regexp, 0, len(options.Values)+len(options.Patterns))
+	for _, value := range options.Values {
+		if value != "" {
+			patterns = append(patterns, regexp.MustCompile(regexp.QuoteMeta(value)))
+		}
+	}
+	for _, pattern := range options.Patterns {
+		patternRegex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid regular expression: %w", pattern, err)
+		}
+		patterns = append(patterns, patternRegex)
+	}
+	var detectors []matcher.Detector
+	if len(options.Detectors) > 0 {
+		var err error
+		if detectors, err = matcher.SelectDetectors(options.Detectors); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(text string) [][]int {
+		var locations [][]int
+		for _, pattern := range patterns {
+			locations = append(locations, pattern.FindAllStringIndex(text, -1)...)
+		}
+		for _, d := range detectors {
+			locations = append(locations, d.FindAll(text)...)
+		}
+		return matcher.MergeLocations(locations)
+	}, nil
+}
+
+// textRedactor builds the function redacting the values, the patterns and the PII of the options
+func textRedactor(options *types.TextRedaction) (func(string) string, error) {
+	match, err := newTextMatcher(options)
+	if err != nil {
+		return nil, err
+	}
+	return func(text string) string {
+		return matcher.RedactRuns([]string{text}, match(text))[0]
+	}, nil
+}
+
+// escapeText escapes a text to be written inside an element
+func escapeText(text string) (string, error) {
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(text)); err != nil {
+		return "", err
+	}
+	return escaped.String(), nil
+}
+
+// redactParagraph redacts the text of a paragraph. Its runs are joined before matching, since
+// Excel splits a text in runs wherever the formatting or the spell check changes
+func redactParagraph(paragraph string, match textMatcher) (string, error) {
+	runs := textRunPattern.FindAllStringSubmatchIndex(paragraph, -1)
+	texts := make([]string, len(runs))
+	var joined strings.Builder
+	for i, run := range runs {
+		texts[i] = html.UnescapeString(paragraph[run[4]:run[5]])
+		joined.WriteString(texts[i])
+	}
+	locations := match(joined.String())
+	if len(locations) == 0 {
+		return paragraph, nil
+	}
+
+	redactedTexts := matcher.RedactRuns(texts, locations)
+	var redacted strings.Builder
+	position := 0
+	for i, run := range runs {
+		if redactedTexts[i] == texts[i] {
+			continue
+		}
+		escaped, err := escapeText(redactedTexts[i])
+		if err != nil {
+			return "", err
+		}
+		redacted.WriteString(paragraph[position:run[4]])
+		redacted.WriteString(escaped)
+		position = run[5]
+	}
+	redacted.WriteString(paragraph[position:])
+	return redacted.String(), nil
+}
+
+// sanitizeShapes removes the shapes or redacts their text in every drawing of the package
+func (s *Sanitizer) sanitizeShapes(p *ooxml.Package) error {
+	match := func(string) [][]int { return nil }
+	if s.options.ShapeText != nil {
+		var err error
+		if match, err = newTextMatcher(s.options.ShapeText); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range p.Parts() {
+		if !drawingPartPattern.MatchString(name) {
+			continue
+		}
+		data, _ := p.Part(name)
+
+		var redactErr error
+		content := anchorPattern.ReplaceAllStringFunc(string(data), func(anchor string) string {
+			if !isShapeAnchor(anchor) {
+				return anchor
+			}
+			if s.options.RemoveShapes {
+				s.Report.RemovedShapes++
+				return ""
+			}
+
+			redacted := paragraphPattern.ReplaceAllStringFunc(anchor, func(paragraph string) string {
+				redactedParagraph, err := redactParagraph(paragraph, match)
+				if err != nil {
+					redactErr = err
+					return paragraph
+				}
+				return redactedParagraph
+			})
+			if redacted != anchor {
+				s.Report.RedactedShapes++
+			}
+			return redacted
+		})
+		if redactErr != nil {
+			return redactErr
+		}
+		p.SetPart(name, []byte(content))
+	}
+
+	return nil
+}
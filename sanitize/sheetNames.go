@@ -0,0 +1,105 @@
+package sanitize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+)
+
+const defaultSheetNamePattern = "Sheet{n}"
+
+// validateSheetNames checks the sheet name options before anything is changed
+func (s *Sanitizer) validateSheetNames() error {
+	options := s.options.SheetNames
+	if options == nil {
+		return nil
+	}
+	for _, newName := range options.Mapping {
+		if err := sheet.ValidateName(newName); err != nil {
+			return err
+		}
+	}
+	if options.Match != nil {
+		if _, err := textRedactor(options.Match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sheetRenames returns the new name of the renamed sheets. Sheets of the mapping missing from
+// the workbook are ignored, since they may have been removed by the previous steps
+func (s *Sanitizer) sheetRenames() (map[string]string, error) {
+	options := s.options.SheetNames
+	pattern := options.Pattern
+	if pattern == "" && options.Match != nil {
+		pattern = defaultSheetNamePattern
+	}
+	matches := func(string) bool { return true }
+	if options.Match != nil {
+		redactText, err := textRedactor(options.Match)
+		if err != nil {
+			return nil, err
+		}
+		matches = func(name string) bool { return redactText(name) != name }
+	}
+
+	renames := map[string]string{}
+	for index, sheetName := range s.File.GetSheetList() {
+		newName, mapped := options.Mapping[sheetName]
+		if !mapped && pattern != "" && matches(sheetName) {
+			newName = strings.ReplaceAll(pattern, "{n}", strconv.Itoa(index+1))
+		}
+		if newName == "" || newName == sheetName {
+			continue
+		}
+		if err := sheet.ValidateName(newName); err != nil {
+			return nil, err
+		}
+		renames[sheetName] = newName
+	}
+
+	// Excel compares sheet names without case
+	names := map[string]bool{}
+	for _, sheetName := range s.File.GetSheetList() {
+		newName := sheetName
+		if renamed, found := renames[sheetName]; found {
+			newName = renamed
+		}
+		if names[strings.ToLower(newName)] {
+			return nil, fmt.Errorf("renaming the sheets would give two sheets the name '%s'", newName)
+		}
+		names[strings.ToLower(newName)] = true
+	}
+
+	return renames, nil
+}
+
+// renameSheets renames the sheets along with the formulas, defined names, data validations,
+// conditional formats, chart series and pivot sources referring to them
+func (s *Sanitizer) renameSheets() error {
+	renames, err := s.sheetRenames()
+	if err != nil || len(renames) == 0 {
+		return err
+	}
+
+	err = s.editPackage(func(p *ooxml.Package) error {
+		return sheet.Rename(p, renames)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sheetName := range s.File.GetSheetList() {
+		for from, to := range renames {
+			if to == sheetName {
+				s.Report.RenamedSheets = append(s.Report.RenamedSheets, types.RenamedSheet{SheetName: from, NewName: to})
+			}
+		}
+	}
+	return nil
+}
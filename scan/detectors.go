@@ -0,0 +1,43 @@
+package scan
+
+import (
+	"unicode"
+
+	"xlsx-processor/pkg/matcher"
+)
+
+// Constants for the categories of findings, the detectors are those of the matcher package
+const (
+	EMAIL       string = matcher.EMAIL
+	PHONE       string = matcher.PHONE
+	SSN         string = matcher.SSN
+	CREDIT_CARD string = matcher.CREDIT_CARD
+	IBAN        string = matcher.IBAN
+	IP_ADDRESS  string = matcher.IP_ADDRESS
+	DICTIONARY  string = "dictionary"
+	REGEX       string = "regex"
+)
+
+// mask hides most of a value, the numbers keep their last 4 digits and the other values their
+// first character, eg: ************1111 or j***************
+func mask(category, value string) string {
+	runes := []rune(value)
+	kept := func(i int) bool { return i == 0 }
+	switch category {
+	case CREDIT_CARD, IBAN, SSN, PHONE:
+		kept = func(i int) bool { return i >= len(runes)-4 }
+	}
+	if len(runes) <= 4 {
+		kept = func(int) bool { return false }
+	}
+
+	masked := make([]rune, len(runes))
+	for i, r := range runes {
+		if kept(i) || unicode.IsSpace(r) || r == '-' || r == '@' || r == '.' && category != IP_ADDRESS {
+			masked[i] = r
+		} else {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
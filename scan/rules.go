@@ -0,0 +1,138 @@
+package scan
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// suggestRules drafts a rule per sheet redacting its findings. The dictionary terms found in the
+// cells become a dictionary action, the other cells are redacted by range, and the text found in
+// the comments is redacted with the pattern that found it
+func (s *Scanner) suggestRules() []types.Rule {
+	rules := []types.Rule{}
+	for _, sheetName := range s.File.GetSheetList() {
+		var terms, commentPatterns []string
+		var cells []string
+		dictionaryCells := map[string]bool{}
+		for _, finding := range s.findings {
+			if finding.SheetName != sheetName {
+				continue
+			}
+			switch {
+			case finding.Location == CELL && finding.Category == DICTIONARY:
+				terms = appendUnique(terms, finding.Term)
+				dictionaryCells[finding.Cell] = true
+			case finding.Location == CELL:
+				cells = appendUnique(cells, finding.Cell)
+			case finding.Location == COMMENT:
+				commentPatterns = appendUnique(commentPatterns, s.commentPattern(finding))
+			}
+		}
+
+		var actions []types.Action
+		if len(terms) > 0 {
+			slices.Sort(terms)
+			options := *s.Options.Dictionary
+			options.Terms = terms
+			options.Source = nil
+			actions = append(actions, types.Action{Operation: transform.DICTIONARY, ActionType: transform.REDACT, Dictionary: &options})
+		}
+		cells = slices.DeleteFunc(cells, func(cellName string) bool { return dictionaryCells[cellName] })
+		for _, cellRange := range cellRanges(cells) {
+			actions = append(actions, types.Action{Operation: transform.RANGE, Value: cellRange, ActionType: transform.REDACT})
+		}
+		for _, pattern := range commentPatterns {
+			actions = append(actions, types.Action{
+				Operation:  transform.COMMENT,
+				Value:      pattern,
+				ActionType: transform.REDACT,
+				Comment:    &types.CommentOptions{Regex: true},
+			})
+		}
+		if len(actions) == 0 {
+			continue
+		}
+
+		rules = append(rules, types.Rule{
+			PageCondition: types.PageCondition{SheetName: sheetName, NonEmptyValueRedact: true},
+			Actions:       actions,
+		})
+	}
+	return rules
+}
+
+// commentPattern returns the regular expression redacting a finding in a comment
+func (s *Scanner) commentPattern(finding finding) string {
+	if finding.Category != DICTIONARY {
+		return finding.Pattern
+	}
+	pattern := regexp.QuoteMeta(finding.Term)
+	if s.Options.Dictionary.WholeWord {
+		pattern = `\b` + pattern + `\b`
+	}
+	if s.Options.Dictionary.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	return pattern
+}
+
+// suggestSanitize drafts the sanitize options redacting the findings of the headers and footers
+func (s *Scanner) suggestSanitize() *types.Sanitize {
+	var values, patterns []string
+	for _, finding := range s.findings {
+		if finding.Location == CELL || finding.Location == COMMENT {
+			continue
+		}
+		if finding.Category == DICTIONARY {
+			values = appendUnique(values, finding.Text)
+		} else {
+			patterns = appendUnique(patterns, finding.Pattern)
+		}
+	}
+	if len(values) == 0 && len(patterns) == 0 {
+		return nil
+	}
+	return &types.Sanitize{HeadersFooters: &types.TextRedaction{Values: values, Patterns: patterns}}
+}
+
+// cellRanges joins the cells following each other in a column, eg: B2, B3 and B4 become B2:B4.
+// A range operation always takes two cells, a single cell becomes B2:B2
+func cellRanges(cells []string) []string {
+	type coordinates struct{ col, row int }
+	var sorted []coordinates
+	for _, cellName := range cells {
+		col, row, err := excelize.CellNameToCoordinates(cellName)
+		if err == nil {
+			sorted = append(sorted, coordinates{col, row})
+		}
+	}
+	slices.SortFunc(sorted, func(a, b coordinates) int {
+		return cmp.Or(cmp.Compare(a.col, b.col), cmp.Compare(a.row, b.row))
+	})
+
+	var ranges []string
+	for start := 0; start < len(sorted); {
+		end := start
+		for end+1 < len(sorted) && sorted[end+1].col == sorted[start].col && sorted[end+1].row == sorted[end].row+1 {
+			end++
+		}
+		first, _ := excelize.CoordinatesToCellName(sorted[start].col, sorted[start].row)
+		last, _ := excelize.CoordinatesToCellName(sorted[end].col, sorted[end].row)
+		ranges = append(ranges, first+":"+last)
+		start = end + 1
+	}
+	return ranges
+}
+
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,237 @@
+package scan
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"xlsx-processor/pkg/comment"
+	"xlsx-processor/pkg/matcher"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Constants for the locations of findings, headers and footers are named after their element
+const (
+	CELL    string = "cell"
+	COMMENT string = "comment"
+)
+
+// finding is a finding along with what a rule needs to redact it
+type finding struct {
+	types.ScanFinding
+	// Term is the dictionary term found, Text the text it matched
+	Term string
+	Text string
+	// Pattern is the regular expression that found the value
+	Pattern string
+}
+
+// match is a value found in a text
+type match struct {
+	Start, End int
+	Category   string
+	Confidence float64
+	Term       string
+	Pattern    string
+	// PatternIndex is set for the patterns of the request
+	PatternIndex *int
+}
+
+// Scanner finds sensitive values in a workbook without changing it
+type Scanner struct {
+	File    *excelize.File
+	Options *types.RequestBodyScan
+
+	detectors  []matcher.Detector
+	patterns   []*regexp.Regexp
+	dictionary *matcher.Dictionary
+	findings   []finding
+}
+
+// MakeScanner creates a scanner for the options of the request
+func MakeScanner(f *excelize.File, options *types.RequestBodyScan) *Scanner {
+	if options == nil {
+		options = &types.RequestBodyScan{}
+	}
+	return &Scanner{
+		File:    f,
+		Options: options,
+	}
+}
+
+// Execute scans the cells, the comments and the headers and footers of every sheet
+func (s *Scanner) Execute() (*types.ScanResult, error) {
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+
+	for _, sheetName := range s.File.GetSheetList() {
+		if err := s.scanCells(sheetName); err != nil {
+			return nil, err
+		}
+		if err := s.scanComments(sheetName); err != nil {
+			return nil, err
+		}
+		if err := s.scanHeadersFooters(sheetName); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &types.ScanResult{
+		Findings:          make([]types.ScanFinding, 0, len(s.findings)),
+		Counts:            map[string]int{},
+		SuggestedRules:    s.suggestRules(),
+		SuggestedSanitize: s.suggestSanitize(),
+	}
+	for _, finding := range s.findings {
+		result.Findings = append(result.Findings, finding.ScanFinding)
+		result.Counts[finding.Category]++
+	}
+	return result, nil
+}
+
+// prepare selects the detectors, compiles the patterns and builds the dictionary
+func (s *Scanner) prepare() error {
+	options := s.Options
+	detectors, err := matcher.SelectDetectors(options.Detectors)
+	if err != nil {
+		return err
+	}
+	s.detectors = detectors
+
+	for _, pattern := range options.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid regular expression: %w", pattern, err)
+		}
+		s.patterns = append(s.patterns, compiled)
+	}
+
+	if dictionary := options.Dictionary; dictionary != nil {
+		terms := slices.Clone(dictionary.Terms)
+		if dictionary.Source != nil {
+			storedTerms, err := transform.LoadTermList(*dictionary.Source)
+			if err != nil {
+				return err
+			}
+			terms = append(terms, storedTerms...)
+		}
+		s.dictionary = matcher.NewDictionary(terms, dictionary.CaseInsensitive, dictionary.WholeWord)
+	}
+	return nil
+}
+
+// findMatches runs the dictionary, the patterns and the detectors over a text. The matches
+// overlapping a previous one are dropped, so each value is reported once
+func (s *Scanner) findMatches(text string) []match {
+	var matches []match
+	add := func(m match) {
+		for _, previous := range matches {
+			if m.Start < previous.End && previous.Start < m.End {
+				return
+			}
+		}
+		matches = append(matches, m)
+	}
+
+	if s.dictionary != nil {
+		for _, dictionaryMatch := range s.dictionary.FindAll(text) {
+			add(match{Start: dictionaryMatch.Start, End: dictionaryMatch.End, Category: DICTIONARY, Confidence: 1, Term: dictionaryMatch.Term})
+		}
+	}
+	for index, pattern := range s.patterns {
+		for _, location := range pattern.FindAllStringIndex(text, -1) {
+			if location[0] == location[1] {
+				continue
+			}
+			patternIndex := index
+			add(match{Start: location[0], End: location[1], Category: REGEX, Confidence: 1, Pattern: pattern.String(), PatternIndex: &patternIndex})
+		}
+	}
+	for _, d := range s.detectors {
+		for _, location := range d.FindAll(text) {
+			add(match{Start: location[0], End: location[1], Category: d.Category, Confidence: d.Confidence, Pattern: d.Pattern.String()})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+// record adds the matches of a text to the findings
+func (s *Scanner) record(sheetName, cellName, location, text string) {
+	for _, m := range s.findMatches(text) {
+		s.findings = append(s.findings, finding{
+			ScanFinding: types.ScanFinding{
+				SheetName:    sheetName,
+				Cell:         cellName,
+				Location:     location,
+				Category:     m.Category,
+				Confidence:   m.Confidence,
+				Preview:      mask(m.Category, text[m.Start:m.End]),
+				PatternIndex: m.PatternIndex,
+			},
+			Term:    m.Term,
+			Text:    text[m.Start:m.End],
+			Pattern: m.Pattern,
+		})
+	}
+}
+
+// scanCells scans the raw values of the cells, so long numbers are not shown in scientific notation
+func (s *Scanner) scanCells(sheetName string) error {
+	rows, err := s.File.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return err
+	}
+	for rowIndex, row := range rows {
+		for colIndex, cellValue := range row {
+			if cellValue == "" {
+				continue
+			}
+			cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+			if err != nil {
+				return err
+			}
+			s.record(sheetName, cellName, CELL, cellValue)
+		}
+	}
+	return nil
+}
+
+// scanComments scans the notes and the threaded comments
+func (s *Scanner) scanComments(sheetName string) error {
+	comments, err := comment.GetComments(s.File, sheetName)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		s.record(sheetName, c.Cell, COMMENT, c.Text)
+	}
+	return nil
+}
+
+// scanHeadersFooters scans the headers and footers of the odd, even and first pages
+func (s *Scanner) scanHeadersFooters(sheetName string) error {
+	headerFooter, err := s.File.GetHeaderFooter(sheetName)
+	if err != nil || headerFooter == nil {
+		return err
+	}
+	for _, part := range []struct{ location, text string }{
+		{"oddHeader", headerFooter.OddHeader},
+		{"oddFooter", headerFooter.OddFooter},
+		{"evenHeader", headerFooter.EvenHeader},
+		{"evenFooter", headerFooter.EvenFooter},
+		{"firstHeader", headerFooter.FirstHeader},
+		{"firstFooter", headerFooter.FirstFooter},
+	} {
+		if part.text != "" {
+			s.record(sheetName, "", part.location, part.text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/transform"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newFile creates a workbook with PII in its cells, a comment and a header
+func newFile() *excelize.File {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]any{"Name", "Email", "SSN", "Card"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{"Jane Doe", "jane.doe@example.com", "123-45-6789", "4111 1111 1111 1111"})
+	f.SetSheetRow("Sheet1", "A3", &[]any{"John Roe", "john.roe@example.com", "000-12-3456", "4111 1111 1111 1112"})
+	f.AddComment("Sheet1", excelize.Comment{Cell: "A3", Author: "HR", Text: "Call 555-123-4567"})
+	f.SetHeaderFooter("Sheet1", &excelize.HeaderFooterOptions{OddHeader: "&LPayroll of Jane Doe"})
+	return f
+}
+
+func TestScan(t *testing.T) {
+	t.Run("find the PII of the cells, comments and headers", func(t *testing.T) {
+		f := newFile()
+		scanner := MakeScanner(f, &types.RequestBodyScan{
+			Dictionary: &types.DictionaryOptions{Terms: []string{"jane doe"}, CaseInsensitive: true},
+		})
+		result, err := scanner.Execute()
+		if err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+
+		// The invalid SSN and the card number failing the checksum are left out
+		assert.Equal(t, map[string]int{DICTIONARY: 2, EMAIL: 2, SSN: 1, CREDIT_CARD: 1, PHONE: 1}, result.Counts)
+		assert.Equal(t, types.ScanFinding{
+			SheetName:  "Sheet1",
+			Cell:       "D2",
+			Location:   CELL,
+			Category:   CREDIT_CARD,
+			Confidence: 0.95,
+			Preview:    "**** **** **** 1111",
+		}, result.Findings[3])
+		assert.Equal(t, "***-**-6789", result.Findings[2].Preview)
+		assert.Equal(t, "oddHeader", result.Findings[6].Location)
+
+		assert.Equal(t, 1, len(result.SuggestedRules))
+		actions := result.SuggestedRules[0].Actions
+		assert.Equal(t, []string{"jane doe"}, actions[0].Dictionary.Terms)
+		assert.Equal(t, "B2:B3", actions[1].Value)
+		assert.Equal(t, "C2:C2", actions[2].Value)
+		assert.Equal(t, "D2:D2", actions[3].Value)
+		assert.Equal(t, transform.COMMENT, actions[4].Operation)
+		assert.Equal(t, []string{"Jane Doe"}, result.SuggestedSanitize.HeadersFooters.Values)
+	})
+
+	t.Run("the suggested rules redact the findings", func(t *testing.T) {
+		f := newFile()
+		result, err := MakeScanner(f, nil).Execute()
+		if err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+
+		if transformErr := transform.MakeRulesExecutor(f, result.SuggestedRules).Execute(); transformErr != nil {
+			t.Fatalf("failed to transform: %v", transformErr.Message)
+		}
+		result, err = MakeScanner(f, nil).Execute()
+		if err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		assert.Equal(t, 0, len(result.Findings))
+	})
+
+	t.Run("select the detectors and add patterns", func(t *testing.T) {
+		scanner := MakeScanner(newFile(), &types.RequestBodyScan{Detectors: []string{"SSN"}, Patterns: []string{`John \w+`}})
+		result, err := scanner.Execute()
+		if err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+
+		assert.Equal(t, map[string]int{SSN: 1, REGEX: 1}, result.Counts)
+		assert.Equal(t, 0, *result.Findings[1].PatternIndex)
+		assert.Equal(t, "J*** ***", result.Findings[1].Preview)
+	})
+
+	t.Run("reject unknown detectors", func(t *testing.T) {
+		_, err := MakeScanner(newFile(), &types.RequestBodyScan{Detectors: []string{"passport"}}).Execute()
+		assert.Equal(t, "'passport' is not a detector", err.Error())
+	})
+}
+
+func TestDetectors(t *testing.T) {
+	assert.Equal(t, "j***.***@*******.***", mask(EMAIL, "jane.doe@example.com"))
+	assert.Equal(t, []string{"A1:A3", "A5:A5", "B2:B2"}, cellRanges([]string{"A3", "B2", "A1", "A2", "A5"}))
+}
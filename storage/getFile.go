@@ -43,6 +43,16 @@ func GetFileFromJson(storageType string, input types.Input) (*excelize.File, err
 	return f, nil
 }
 
+// GetFileBytes downloads a file as is, for inputs that are not workbooks
+func GetFileBytes(storageType string, input types.Input) ([]byte, error) {
+	fileBytes, err := downloadProxy(storageType, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	return fileBytes, nil
+}
+
 func GetFile(storageType string, input types.Input) (*excelize.File, error) {
 	fileBytes, err := downloadProxy(storageType, input)
 	if err != nil {
@@ -1,6 +1,7 @@
 package transform
 
 import (
+	"xlsx-processor/pkg/cell"
 	"xlsx-processor/pkg/types"
 
 	"github.com/xuri/excelize/v2"
@@ -15,6 +16,14 @@ type ActionExecutor struct {
 	Action              *types.Action
 	ActionIndex         int
 	RuleIndex           int
+	// Manifest records the redacted cells, it is optional
+	Manifest *types.Manifest
+	// MergePolicy is EXPAND (default) or UNMERGE, see mergePolicy.go
+	MergePolicy string
+	// RemoveExcludedPictures deletes the pictures anchored in the excluded rows and columns
+	RemoveExcludedPictures bool
+	// mergeAreas caches the merged areas of the sheet
+	mergeAreas []cell.MergeArea
 }
 
 // MakeActionExecutor creates a new Actions instance
@@ -39,84 +48,38 @@ func (a *ActionExecutor) newTransformError(message, key string) *types.Transform
 	}
 }
 
-// Execute executes the appropriate operation based on the action type
+// Execute runs the operation registered for the action type and the operation of the action
 func (a *ActionExecutor) Execute() *types.TransformError {
-	switch a.Action.ActionType {
-	case REDACT:
-		transformErr := a.ExecuteRedact()
-		if transformErr != nil {
-			return transformErr
-		}
-	case EXCLUDE:
-		transformErr := a.ExecuteExclude()
-		if transformErr != nil {
-			return transformErr
-		}
-	default:
-		return a.newTransformError("Invalid action type", "actionType")
-	}
-
-	return nil
+	return a.executeOperation(a.Action.ActionType)
 }
 
-// ExecuteRedact handles all redact operations
+// ExecuteRedact runs the redact operation named by the action, whatever its action type
 func (a *ActionExecutor) ExecuteRedact() *types.TransformError {
-	// Skip empty values
-	if a.Action.Value == "" {
-		return nil
-	}
-
-	switch a.Action.Operation {
-	case RANGE:
-		if err := a.RedactRange(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case VALUE:
-		if err := a.RedactValue(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case TEXT_COLOR:
-		if err := a.RedactTextColor(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case BG_COLOR:
-		if err := a.RedactBgColor(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case COLUMN:
-		if err := a.RedactColumn(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case ROW:
-		if err := a.RedactRow(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	default:
-		return a.newTransformError("Invalid operation", "operation")
-	}
-
-	return nil
+	return a.executeOperation(REDACT)
 }
 
-// ExecuteExclude handles all exclude operations
+// ExecuteExclude runs the exclude operation named by the action, whatever its action type
 func (a *ActionExecutor) ExecuteExclude() *types.TransformError {
-	// Skip empty values
-	if a.Action.Value == "" {
-		return nil
+	return a.executeOperation(EXCLUDE)
+}
+
+// executeOperation runs the operation of the action registered for the action type
+func (a *ActionExecutor) executeOperation(actionType string) *types.TransformError {
+	registered, isActionType := lookupOperation(actionType, a.Action.Operation)
+	if !isActionType {
+		return a.newTransformError("Invalid action type", "actionType")
 	}
 
-	switch a.Action.Operation {
-	case ROW:
-		if err := a.ExcludeRow(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	case COLUMN:
-		if err := a.ExcludeColumn(); err != nil {
-			return a.newTransformError(err.Error(), "value")
-		}
-	default:
+	// Skip empty values, dictionaries can list their terms outside of the value
+	if a.Action.Value == "" && (actionType != REDACT || a.Action.Dictionary == nil) {
+		return nil
+	}
+	if registered == nil {
 		return a.newTransformError("Invalid operation", "operation")
 	}
 
+	if err := registered.operation.Execute(a); err != nil {
+		return a.newTransformError(err.Error(), registered.errorKey)
+	}
 	return nil
 }
@@ -0,0 +1,290 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// AllowlistExecutor runs the actions of a rule in allowlist mode. The cells, rows
+// and columns targeted by the actions are kept verbatim, every other populated cell
+// is redacted (REDACT actions) and every other row or column is removed (EXCLUDE actions)
+type AllowlistExecutor struct {
+	File                *excelize.File
+	SheetName           string
+	NonEmptyValueRedact bool
+	Actions             []types.Action
+	RuleIndex           int
+	// Manifest records the redacted cells, it is optional
+	Manifest *types.Manifest
+	// RemoveExcludedPictures deletes the pictures anchored in the removed rows and columns
+	RemoveExcludedPictures bool
+	// MergePolicy handles the merged areas the removed rows and columns cut through
+	MergePolicy string
+}
+
+// MakeAllowlistExecutor creates a new AllowlistExecutor instance
+func MakeAllowlistExecutor(f *excelize.File, sheetName string, nonEmptyValueRedact bool, actions []types.Action, ruleIndex int) *AllowlistExecutor {
+	return &AllowlistExecutor{
+		File:                f,
+		SheetName:           sheetName,
+		NonEmptyValueRedact: nonEmptyValueRedact,
+		Actions:             actions,
+		RuleIndex:           ruleIndex,
+	}
+}
+
+// newTransformError creates a new TransformError for errors that are not tied to a single action
+func (e *AllowlistExecutor) newTransformError(message string) *types.TransformError {
+	return &types.TransformError{
+		Message:   message,
+		RuleIndex: &e.RuleIndex,
+		Key:       "mode",
+	}
+}
+
+// Execute collects what every action keeps and then removes everything else
+func (e *AllowlistExecutor) Execute() *types.TransformError {
+	var keepCells []CellMatcher
+	keepRows := map[int]bool{}
+	keepCols := map[int]bool{}
+
+	for actionIndex, action := range e.Actions {
+		actionExecutor := MakeActionExecutor(e.File, e.SheetName, e.NonEmptyValueRedact, &action, actionIndex, e.RuleIndex)
+
+		// Skip empty values
+		if action.Value == "" {
+			continue
+		}
+
+		switch action.ActionType {
+		case REDACT:
+			matcher, transformErr := actionExecutor.keepMatcher()
+			if transformErr != nil {
+				return transformErr
+			}
+			keepCells = append(keepCells, matcher)
+		case EXCLUDE:
+			switch action.Operation {
+			case ROW:
+				rowNum, err := strconv.Atoi(action.Value)
+				if err != nil || rowNum < 1 {
+					return actionExecutor.newTransformError(fmt.Sprintf("'%s' is not a valid row number", action.Value), "value")
+				}
+				keepRows[rowNum] = true
+			case COLUMN:
+				colNum := cell.ColumnToNumber(action.Value)
+				if colNum == 0 {
+					return actionExecutor.newTransformError(fmt.Sprintf("'%s' is invalid", action.Value), "value")
+				}
+				keepCols[colNum] = true
+			default:
+				return actionExecutor.newTransformError("Invalid operation", "operation")
+			}
+		default:
+			return actionExecutor.newTransformError("Invalid action type", "actionType")
+		}
+	}
+
+	if len(keepCells) > 0 {
+		if err := e.redactOutside(keepCells); err != nil {
+			return e.newTransformError(err.Error())
+		}
+	}
+
+	if len(keepRows) > 0 || len(keepCols) > 0 {
+		if err := e.excludeOutside(keepRows, keepCols); err != nil {
+			return e.newTransformError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// redactOutside redacts every populated cell that none of the matchers keep
+func (e *AllowlistExecutor) redactOutside(keepCells []CellMatcher) error {
+	rows, err := e.File.GetRows(e.SheetName)
+	if err != nil {
+		return err
+	}
+
+	for rowIndex, row := range rows {
+		for colIndex, cellValue := range row {
+			if cellValue == "" {
+				continue
+			}
+			cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+			if err != nil {
+				return err
+			}
+
+			keep := false
+			for _, matcher := range keepCells {
+				keep, err = matcher(colIndex+1, rowIndex+1, cellName, cellValue)
+				if err != nil {
+					return err
+				}
+				if keep {
+					break
+				}
+			}
+			if keep {
+				continue
+			}
+
+			err = redactCell(e.File, e.Manifest, e.SheetName, cellName, e.NonEmptyValueRedact, &e.RuleIndex, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// excludeOutside removes every row and column of the sheet's range that is not kept, the merged
+// areas they cut through are handled by the merge policy of the rule. An expansion that would
+// remove a kept row or column unmerges the areas instead. Rows are only removed when at least
+// one row is kept, and likewise for columns
+func (e *AllowlistExecutor) excludeOutside(keepRows, keepCols map[int]bool) error {
+	_, startRow, startCol, _, endRow, endCol, err := cell.GetRange(e.File, e.SheetName)
+	if err != nil {
+		return err
+	}
+	actionExecutor := MakeActionExecutor(e.File, e.SheetName, e.NonEmptyValueRedact, &types.Action{ActionType: EXCLUDE}, 0, e.RuleIndex)
+	actionExecutor.Manifest = e.Manifest
+	actionExecutor.MergePolicy = e.MergePolicy
+	actionExecutor.RemoveExcludedPictures = e.RemoveExcludedPictures
+
+	// Past the removed index only the kept ones remain, the ones before it keep their number
+	exclude := func(num int, kept map[int]bool, expand func([]cell.MergeArea, int) (int, int), remove func(int) (int, error)) (int, error) {
+		if actionExecutor.getMergePolicy(true) == EXPAND {
+			mergeAreas, err := actionExecutor.getMergeAreas()
+			if err != nil {
+				return 0, err
+			}
+			start, end := expand(mergeAreas, num)
+			for index := start; index <= end; index++ {
+				if index > num || kept[index] {
+					actionExecutor.MergePolicy = UNMERGE
+					defer func() { actionExecutor.MergePolicy = e.MergePolicy }()
+					break
+				}
+			}
+		}
+		return remove(num)
+	}
+
+	// Removing from the end so the remaining indexes do not shift, an expanded removal resumes
+	// before the first column or row it removed
+	if len(keepCols) > 0 {
+		for colNum := cell.ColumnToNumber(endCol); colNum >= cell.ColumnToNumber(startCol); colNum-- {
+			if keepCols[colNum] {
+				continue
+			}
+			if colNum, err = exclude(colNum, keepCols, expandToMergedCols, actionExecutor.excludeColumn); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(keepRows) > 0 {
+		for rowNum := endRow; rowNum >= startRow; rowNum-- {
+			if keepRows[rowNum] {
+				continue
+			}
+			if rowNum, err = exclude(rowNum, keepRows, expandToMergedRows, actionExecutor.excludeRow); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// keepMatcher builds the matcher registered for the redact operation of the action
+func (a *ActionExecutor) keepMatcher() (CellMatcher, *types.TransformError) {
+	registered, _ := lookupOperation(REDACT, a.Action.Operation)
+	if registered == nil || registered.matcher == nil {
+		return nil, a.newTransformError("Invalid operation", "operation")
+	}
+	matcher, err := registered.matcher(a)
+	if err != nil {
+		return nil, a.newTransformError(err.Error(), registered.errorKey)
+	}
+	return matcher, nil
+}
+
+// rangeMatcher targets the cells of the range of the action
+func (a *ActionExecutor) rangeMatcher() (CellMatcher, error) {
+	value := a.Action.Value
+	split := strings.Split(value, ":")
+	if len(split) != 2 {
+		return nil, fmt.Errorf("'%s' is invalid", value)
+	}
+	startCol, startRow, err := cell.SplitReference(split[0])
+	if err != nil {
+		return nil, err
+	}
+	endCol, endRow, err := cell.SplitReference(split[1])
+	if err != nil {
+		return nil, err
+	}
+	startColNum, endColNum := cell.ColumnToNumber(startCol), cell.ColumnToNumber(endCol)
+	return func(colNum, rowNum int, _, _ string) (bool, error) {
+		return colNum >= startColNum && colNum <= endColNum && rowNum >= startRow && rowNum <= endRow, nil
+	}, nil
+}
+
+// columnMatcher targets the cells of the column of the action
+func (a *ActionExecutor) columnMatcher() (CellMatcher, error) {
+	keepColNum := cell.ColumnToNumber(a.Action.Value)
+	if keepColNum == 0 {
+		return nil, fmt.Errorf("'%s' is invalid", a.Action.Value)
+	}
+	return func(colNum, _ int, _, _ string) (bool, error) {
+		return colNum == keepColNum, nil
+	}, nil
+}
+
+// rowMatcher targets the cells of the row of the action
+func (a *ActionExecutor) rowMatcher() (CellMatcher, error) {
+	keepRowNum, err := strconv.Atoi(a.Action.Value)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid row number", a.Action.Value)
+	}
+	return func(_, rowNum int, _, _ string) (bool, error) {
+		return rowNum == keepRowNum, nil
+	}, nil
+}
+
+// valueMatcher targets the cells holding the value of the action
+func (a *ActionExecutor) valueMatcher() (CellMatcher, error) {
+	value := a.Action.Value
+	return func(_, _ int, _, cellValue string) (bool, error) {
+		return cellValue == value, nil
+	}, nil
+}
+
+// textColorMatcher targets the cells whose text has the color of the action
+func (a *ActionExecutor) textColorMatcher() (CellMatcher, error) {
+	file, sheetName, value := a.File, a.SheetName, a.Action.Value
+	return func(_, _ int, cellName, _ string) (bool, error) {
+		textColor, err := cell.GetTextColor(file, sheetName, cellName)
+		return textColor == value, err
+	}, nil
+}
+
+// bgColorMatcher targets the cells whose background has the color of the action
+func (a *ActionExecutor) bgColorMatcher() (CellMatcher, error) {
+	file, sheetName, value := a.File, a.SheetName, a.Action.Value
+	return func(_, _ int, cellName, _ string) (bool, error) {
+		bgColor, err := cell.GetBgColor(file, sheetName, cellName)
+		return bgColor == value, err
+	}, nil
+}
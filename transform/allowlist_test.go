@@ -0,0 +1,135 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func newAllowlistTestFile(t *testing.T) *excelize.File {
+	t.Helper()
+
+	f := excelize.NewFile()
+	rows := [][]interface{}{
+		{"Name", "SSN", "Amount", "Notes"},
+		{"Alice", "111-22-3333", 10, "first"},
+		{"Bob", "444-55-6666", 20, "second"},
+	}
+	for rowIndex, row := range rows {
+		cellName, _ := excelize.CoordinatesToCellName(1, rowIndex+1)
+		if err := f.SetSheetRow("Sheet1", cellName, &row); err != nil {
+			t.Fatalf("failed to set row: %v", err)
+		}
+	}
+	return f
+}
+
+func TestAllowlist(t *testing.T) {
+	t.Run("redact everything outside the kept columns", func(t *testing.T) {
+		f := newAllowlistTestFile(t)
+		defer f.Close()
+
+		rules := []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, NonEmptyValueRedact: true, Mode: "allowlist"},
+				Actions: []types.Action{
+					{ActionType: REDACT, Operation: COLUMN, Value: "A"},
+					{ActionType: REDACT, Operation: RANGE, Value: "C1:C2"},
+				},
+			},
+		}
+		transformErr := MakeRulesExecutor(f, rules).Execute()
+		if transformErr != nil {
+			t.Fatalf("failed to execute rules: %s", transformErr.Message)
+		}
+
+		rows, err := f.GetRows("Sheet1")
+		if err != nil {
+			t.Fatalf("failed to get rows: %v", err)
+		}
+		assert.Equal(t, [][]string{
+			{"Name", "**redacted**", "Amount", "**redacted**"},
+			{"Alice", "**redacted**", "10", "**redacted**"},
+			{"Bob", "**redacted**", "**redacted**", "**redacted**"},
+		}, rows)
+	})
+
+	t.Run("exclude everything outside the kept rows and columns", func(t *testing.T) {
+		f := newAllowlistTestFile(t)
+		defer f.Close()
+
+		rules := []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, Mode: "ALLOWLIST"},
+				Actions: []types.Action{
+					{ActionType: EXCLUDE, Operation: COLUMN, Value: "A"},
+					{ActionType: EXCLUDE, Operation: COLUMN, Value: "C"},
+					{ActionType: EXCLUDE, Operation: ROW, Value: "1"},
+					{ActionType: EXCLUDE, Operation: ROW, Value: "3"},
+				},
+			},
+		}
+		transformErr := MakeRulesExecutor(f, rules).Execute()
+		if transformErr != nil {
+			t.Fatalf("failed to execute rules: %s", transformErr.Message)
+		}
+
+		rows, err := f.GetRows("Sheet1")
+		if err != nil {
+			t.Fatalf("failed to get rows: %v", err)
+		}
+		assert.Equal(t, [][]string{
+			{"Name", "Amount"},
+			{"Bob", "20"},
+		}, rows)
+	})
+
+	t.Run("never expand an exclusion into a kept row", func(t *testing.T) {
+		f := newAllowlistTestFile(t)
+		defer f.Close()
+		f.MergeCell("Sheet1", "A2", "A3")
+
+		rules := []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", Mode: "ALLOWLIST", MergePolicy: "expand"},
+				Actions: []types.Action{
+					{ActionType: EXCLUDE, Operation: ROW, Value: "1"},
+					{ActionType: EXCLUDE, Operation: ROW, Value: "3"},
+				},
+			},
+		}
+		transformErr := MakeRulesExecutor(f, rules).Execute()
+		if transformErr != nil {
+			t.Fatalf("failed to execute rules: %s", transformErr.Message)
+		}
+
+		// The merged area is unmerged and filled rather than removed along with row 2
+		rows, err := f.GetRows("Sheet1")
+		if err != nil {
+			t.Fatalf("failed to get rows: %v", err)
+		}
+		assert.Equal(t, [][]string{
+			{"Name", "SSN", "Amount", "Notes"},
+			{"Alice", "444-55-6666", "20", "second"},
+		}, rows)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		f := newAllowlistTestFile(t)
+		defer f.Close()
+
+		rules := []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, Mode: "keep"},
+			},
+		}
+		transformErr := MakeRulesExecutor(f, rules).Execute()
+		if transformErr == nil {
+			t.Fatal("expected an error for an invalid mode")
+		}
+		assert.Equal(t, "mode", transformErr.Key)
+	})
+}
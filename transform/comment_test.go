@@ -0,0 +1,183 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/comment"
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// newCommentedFile creates a workbook with notes on A1 and B1 and a comment thread on C1
+func newCommentedFile(t *testing.T) *excelize.File {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]string{"111-22-3333", "Jane", "Sales"})
+	f.AddComment("Sheet1", excelize.Comment{Cell: "A1", Author: "Reviewer", Text: "this is John's SSN"})
+	f.AddComment("Sheet1", excelize.Comment{Cell: "B1", Author: "Reviewer", Text: "checked by John"})
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("failed to write the file: %v", err)
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the package: %v", err)
+	}
+
+	// Adding a thread on C1 with a reply, along with the people of the workbook
+	p.SetPart("xl/threadedComments/threadedComment1.xml", []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<ThreadedComments xmlns="http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments" xmlns:x="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><threadedComment ref="C1" dT="2024-01-01T00:00:00.00" personId="{P1}" id="{T1}"><text>John&apos;s team</text></threadedComment><threadedComment ref="C1" dT="2024-01-02T00:00:00.00" personId="{P1}" id="{T2}" parentId="{T1}"><text>ask John</text></threadedComment></ThreadedComments>`))
+	p.SetPart("xl/persons/person.xml", []byte(`<personList xmlns="http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments"><person displayName="Jane Reviewer" id="{P1}" userId="jane" providerId="None"/></personList>`))
+	sheetRels, _ := p.Part("xl/worksheets/_rels/sheet1.xml.rels")
+	p.SetPart("xl/worksheets/_rels/sheet1.xml.rels", []byte(strings.Replace(string(sheetRels), "</Relationships>",
+		`<Relationship Id="rIdThread" Type="http://schemas.microsoft.com/office/2017/10/relationships/threadedComment" Target="../threadedComments/threadedComment1.xml"/></Relationships>`, 1)))
+	workbookRels, _ := p.Part("xl/_rels/workbook.xml.rels")
+	p.SetPart("xl/_rels/workbook.xml.rels", []byte(strings.Replace(string(workbookRels), "</Relationships>",
+		`<Relationship Id="rIdPerson" Type="http://schemas.microsoft.com/office/2017/10/relationships/person" Target="persons/person.xml"/></Relationships>`, 1)))
+
+	fileContents, err := p.Bytes()
+	if err != nil {
+		t.Fatalf("failed to write the package: %v", err)
+	}
+	f, err = file.InitFileFromBytes(fileContents)
+	if err != nil {
+		t.Fatalf("failed to open the file: %v", err)
+	}
+	return f
+}
+
+func TestComment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		actions  []types.Action
+		expected []types.Comment
+	}{
+		{
+			name: "redact text inside the comments",
+			actions: []types.Action{
+				{ActionType: REDACT, Operation: COMMENT, Value: "John"},
+			},
+			expected: []types.Comment{
+				{Cell: "A1", Author: "Reviewer", Text: "this is **redacted**'s SSN"},
+				{Cell: "B1", Author: "Reviewer", Text: "checked by **redacted**"},
+				{Cell: "C1", Author: "Jane Reviewer", Text: "**redacted**'s team", Threaded: true},
+				{Cell: "C1", Author: "Jane Reviewer", Text: "ask **redacted**", Threaded: true, IsReply: true},
+			},
+		},
+		{
+			name: "redact text inside the comments with a regex",
+			actions: []types.Action{
+				{ActionType: REDACT, Operation: COMMENT, Value: `\bSSN\b`, Comment: &types.CommentOptions{Regex: true}},
+			},
+			expected: []types.Comment{
+				{Cell: "A1", Author: "Reviewer", Text: "this is John's **redacted**"},
+				{Cell: "B1", Author: "Reviewer", Text: "checked by John"},
+				{Cell: "C1", Author: "Jane Reviewer", Text: "John's team", Threaded: true},
+				{Cell: "C1", Author: "Jane Reviewer", Text: "ask John", Threaded: true, IsReply: true},
+			},
+		},
+		{
+			name: "remove the comments of redacted cells",
+			actions: []types.Action{
+				{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"},
+				{ActionType: REDACT, Operation: RANGE, Value: "C1:C1"},
+				{ActionType: EXCLUDE, Operation: COMMENT, Value: "redacted"},
+			},
+			expected: []types.Comment{
+				{Cell: "B1", Author: "Reviewer", Text: "checked by John"},
+			},
+		},
+		{
+			name: "remove all the comments",
+			actions: []types.Action{
+				{ActionType: EXCLUDE, Operation: COMMENT, Value: "all"},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newCommentedFile(t)
+			defer f.Close()
+
+			manifest := &types.Manifest{}
+			for actionIndex, action := range tc.actions {
+				actionExecutor := MakeActionExecutor(f, "Sheet1", true, &action, actionIndex, 0)
+				actionExecutor.Manifest = manifest
+				transformErr := actionExecutor.Execute()
+				if transformErr != nil {
+					t.Fatalf("failed to execute action: %s", transformErr.Message)
+				}
+			}
+
+			// Reading the comments back from the saved file
+			buffer, err := f.WriteToBuffer()
+			if err != nil {
+				t.Fatalf("failed to write the file: %v", err)
+			}
+			savedFile, err := file.InitFileFromBytes(buffer.Bytes())
+			if err != nil {
+				t.Fatalf("failed to open the file: %v", err)
+			}
+			comments, err := comment.GetComments(savedFile, "Sheet1")
+			if err != nil {
+				t.Fatalf("failed to get the comments: %v", err)
+			}
+			assert.Equal(t, tc.expected, comments)
+		})
+	}
+
+	t.Run("keep the comments of cells holding the redacted text", func(t *testing.T) {
+		f := newCommentedFile(t)
+		defer f.Close()
+		// B1 was redacted before, its original value is not in the manifest
+		f.SetCellValue("Sheet1", "B1", "**redacted**")
+
+		actionExecutor := MakeActionExecutor(f, "Sheet1", true, &types.Action{ActionType: EXCLUDE, Operation: COMMENT, Value: "redacted"}, 0, 0)
+		actionExecutor.Manifest = &types.Manifest{RedactedCells: []types.RedactedCell{{SheetName: "Sheet1", Cell: "A1", Value: "111-22-3333"}}}
+		if transformErr := actionExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute action: %s", transformErr.Message)
+		}
+
+		notes, _ := f.GetComments("Sheet1")
+		assert.Equal(t, 1, len(notes))
+		assert.Equal(t, "B1", notes[0].Cell)
+	})
+
+	t.Run("redact values split across the runs of a note", func(t *testing.T) {
+		f := newCommentedFile(t)
+		defer f.Close()
+		f.DeleteComment("Sheet1", "A1")
+		f.AddComment("Sheet1", excelize.Comment{Cell: "A1", Author: "Reviewer", Paragraph: []excelize.RichTextRun{
+			{Text: "SSN of Jo"},
+			{Text: "hn Smith", Font: &excelize.Font{Bold: true}},
+		}})
+
+		action := &types.Action{ActionType: REDACT, Operation: COMMENT, Value: "John Smith"}
+		if transformErr := MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute(); transformErr != nil {
+			t.Fatalf("failed to execute action: %s", transformErr.Message)
+		}
+
+		notes, _ := f.GetComments("Sheet1")
+		for _, note := range notes {
+			if note.Cell == "A1" {
+				assert.Equal(t, "SSN of **redacted**", comment.GetNoteText(note))
+			}
+		}
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		f := newCommentedFile(t)
+		defer f.Close()
+
+		action := &types.Action{ActionType: EXCLUDE, Operation: COMMENT, Value: "some"}
+		transformErr := MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute()
+		assert.Equal(t, "'some' is invalid, expected all or redacted", transformErr.Message)
+	})
+}
@@ -0,0 +1,181 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/expr"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// The variables and functions a compute expression can use
+var (
+	computeVariables = []string{"value", "row", "cell", "sheet"}
+	computeFunctions = []string{"col"}
+)
+
+// Compute replaces each target cell with the result of the expression of the action, eg:
+// upper(value), row["Amount"] * 1.1 or if(col("Country") == "CA", mask(value, 4), value).
+// Every cell is computed from the values of the sheet before the action
+func (a *ActionExecutor) Compute() (err error) {
+	file := a.File
+	sheetName := a.SheetName
+
+	expression, err := expr.Parse(a.Action.Value)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid expression: %w", a.Action.Value, err)
+	}
+	if err = expression.Check(computeVariables, computeFunctions); err != nil {
+		return fmt.Errorf("'%s' is not a valid expression: %w", a.Action.Value, err)
+	}
+
+	// The expressions work on the values as stored, not as the number formats display them
+	rows, err := file.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return err
+	}
+	headerRow := 1
+	if a.Action.Compute != nil && a.Action.Compute.HeaderRow != 0 {
+		headerRow = a.Action.Compute.HeaderRow
+	}
+	headers := map[string]int{}
+	if headerRow > 0 && headerRow <= len(rows) {
+		for colIndex, header := range rows[headerRow-1] {
+			if _, found := headers[header]; header != "" && !found {
+				headers[header] = colIndex
+			}
+		}
+	}
+
+	targets, err := a.computeTargets(rows, headerRow)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		col, rowNum := target[0], target[1]
+		var row []string
+		if rowNum <= len(rows) {
+			row = rows[rowNum-1]
+		}
+		cellValue := func(colIndex int) string {
+			if colIndex < len(row) {
+				return row[colIndex]
+			}
+			return ""
+		}
+
+		rowValues := map[string]string{}
+		for header, colIndex := range headers {
+			rowValues[header] = cellValue(colIndex)
+		}
+		cellName, err := excelize.CoordinatesToCellName(col, rowNum)
+		if err != nil {
+			return err
+		}
+		env := expr.Env{
+			Variables: map[string]any{
+				"value": cellValue(col - 1),
+				"row":   rowValues,
+				"cell":  cellName,
+				"sheet": sheetName,
+			},
+			Functions: map[string]expr.Function{
+				// col reads the cell of the row under a header, or in a column such as C
+				"col": func(args []any) (any, error) {
+					if len(args) != 1 {
+						return nil, fmt.Errorf("expects 1 argument")
+					}
+					name := expr.ToText(args[0])
+					if colIndex, found := headers[name]; found {
+						return cellValue(colIndex), nil
+					}
+					if colNum := cell.ColumnToNumber(name); colNum > 0 && strings.ToUpper(name) == name {
+						return cellValue(colNum - 1), nil
+					}
+					return nil, fmt.Errorf("no column '%s'", name)
+				},
+			},
+		}
+
+		result, err := expression.Eval(env)
+		if err != nil {
+			return fmt.Errorf("%s: %w", cellName, err)
+		}
+		if expr.ToText(result) == cellValue(col-1) {
+			continue
+		}
+		if err = file.SetCellValue(sheetName, cellName, result); err != nil {
+			return err
+		}
+		a.recordComputedCell(cellName)
+	}
+
+	return nil
+}
+
+// computeTargets returns the column and the row of the computed cells, the header row and the
+// rows past the last row of the sheet are left out
+func (a *ActionExecutor) computeTargets(rows [][]string, headerRow int) ([][2]int, error) {
+	target := ""
+	if a.Action.Compute != nil {
+		target = a.Action.Compute.Target
+	}
+	startCol, startRow, endCol, endRow, err := parseComputeTarget(a.Action.Operation, target)
+	if err != nil {
+		return nil, err
+	}
+	// Rows end with the sheet, a column or a range can still fill a new column
+	endRow = min(endRow, len(rows))
+	if a.Action.Operation == ROW {
+		endCol = 0
+		for _, row := range rows {
+			endCol = max(endCol, len(row))
+		}
+	}
+
+	var targets [][2]int
+	for rowNum := startRow; rowNum <= endRow; rowNum++ {
+		if rowNum == headerRow {
+			continue
+		}
+		for col := startCol; col <= endCol; col++ {
+			targets = append(targets, [2]int{col, rowNum})
+		}
+	}
+	return targets, nil
+}
+
+// parseComputeTarget reads the cells a compute operation targets: a range, a column or a row
+func parseComputeTarget(operation, target string) (startCol, startRow, endCol, endRow int, err error) {
+	switch operation {
+	case RANGE:
+		split := strings.Split(target, ":")
+		if len(split) == 2 {
+			startCol, startRow, err = excelize.CellNameToCoordinates(split[0])
+			if err == nil {
+				endCol, endRow, err = excelize.CellNameToCoordinates(split[1])
+			}
+		}
+		if len(split) != 2 || err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("'%s' is invalid, expected a target range such as A1:C10", target)
+		}
+		return startCol, startRow, endCol, endRow, nil
+	case COLUMN:
+		col := cell.ColumnToNumber(target)
+		if col == 0 || col > excelize.MaxColumns {
+			return 0, 0, 0, 0, fmt.Errorf("'%s' is invalid, expected a target column such as C", target)
+		}
+		return col, 1, col, excelize.TotalRows, nil
+	case ROW:
+		rowNum, err := strconv.Atoi(target)
+		if err != nil || rowNum < 1 || rowNum > excelize.TotalRows {
+			return 0, 0, 0, 0, fmt.Errorf("'%s' is not a valid target row number", target)
+		}
+		return 1, rowNum, excelize.MaxColumns, rowNum, nil
+	}
+	return 0, 0, 0, 0, fmt.Errorf("Invalid operation")
+}
@@ -0,0 +1,92 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCompute(t *testing.T) {
+	newFile := func() *excelize.File {
+		f := excelize.NewFile()
+		f.SetSheetRow("Sheet1", "A1", &[]string{"Name", "Country", "Card", "Amount"})
+		f.SetSheetRow("Sheet1", "A2", &[]any{"jane doe", "CA", "4111111111111111", 100})
+		f.SetSheetRow("Sheet1", "A3", &[]any{"john smith", "US", "5500000000000004", 50})
+		return f
+	}
+	compute := func(f *excelize.File, operation, expression, target string) *types.TransformError {
+		action := &types.Action{ActionType: COMPUTE, Operation: operation, Value: expression, Compute: &types.ComputeOptions{Target: target}}
+		return MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute()
+	}
+
+	t.Run("compute a column", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		assert.Equal(t, (*types.TransformError)(nil), compute(f, COLUMN, `upper(value)`, "A"))
+		assert.Equal(t, (*types.TransformError)(nil), compute(f, COLUMN, `if(col("Country") == "CA", mask(value, 4), value)`, "C"))
+		assert.Equal(t, (*types.TransformError)(nil), compute(f, RANGE, `row["Amount"] * 1.5`, "E2:E3"))
+
+		cols, _ := f.GetCols("Sheet1")
+		assert.Equal(t, []string{"Name", "JANE DOE", "JOHN SMITH"}, cols[0])
+		assert.Equal(t, []string{"Card", "************1111", "5500000000000004"}, cols[2])
+		assert.Equal(t, []string{"", "150", "75"}, cols[4])
+	})
+
+	t.Run("compute a row", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		assert.Equal(t, (*types.TransformError)(nil), compute(f, ROW, `if(isNumber(value), value, "[" & cell & "]")`, "3"))
+
+		rows, _ := f.GetRows("Sheet1")
+		assert.Equal(t, []string{"[A3]", "[B3]", "5500000000000004", "50"}, rows[2])
+	})
+
+	t.Run("compute the stored values and record the changed cells", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		style, _ := f.NewStyle(&excelize.Style{NumFmt: 9})
+		f.SetCellValue("Sheet1", "E2", 0.25)
+		f.SetCellStyle("Sheet1", "E2", "E2", style)
+		action := &types.Action{ActionType: COMPUTE, Operation: RANGE, Value: `value * 2`, Compute: &types.ComputeOptions{Target: "E2:E2"}}
+		actionExecutor := MakeActionExecutor(f, "Sheet1", true, action, 0, 0)
+		actionExecutor.Manifest = &types.Manifest{}
+		assert.Equal(t, (*types.TransformError)(nil), actionExecutor.Execute())
+
+		value, _ := f.GetCellValue("Sheet1", "E2", excelize.Options{RawCellValue: true})
+		assert.Equal(t, "0.5", value)
+		// Computed cells are not redactions, their originals are never looked for in the output
+		assert.Equal(t, 0, len(actionExecutor.Manifest.RedactedCells))
+		recorded := actionExecutor.Manifest.ComputedCells
+		assert.Equal(t, 1, len(recorded))
+		assert.Equal(t, "E2", recorded[0].Cell)
+		assert.Equal(t, "", recorded[0].Value)
+	})
+
+	t.Run("report the failing cell", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		transformErr := compute(f, COLUMN, `value * 2`, "A")
+		assert.Equal(t, "A2: at 7: 'jane doe' is not a number", transformErr.Message)
+		assert.Equal(t, "value", transformErr.Key)
+	})
+
+	t.Run("validate the expression and the target", func(t *testing.T) {
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1"},
+			Actions: []types.Action{
+				{ActionType: COMPUTE, Operation: COLUMN, Value: `upper(value`, Compute: &types.ComputeOptions{Target: "A"}},
+				{ActionType: COMPUTE, Operation: RANGE, Value: `trim(value)`, Compute: &types.ComputeOptions{Target: "A2"}},
+				{ActionType: COMPUTE, Operation: VALUE, Value: `trim(value)`},
+				{ActionType: COMPUTE, Operation: ROW, Value: `trim(value)`, Compute: &types.ComputeOptions{Target: "2"}},
+			},
+		}}
+		assert.Equal(t, []string{
+			"rule 0 action 0 value: 'upper(value' is not a valid expression: at 12: expected ',' or ')' at the end",
+			"rule 0 action 1 compute: 'A2' is invalid, expected a target range such as A1:C10",
+			"rule 0 action 2 operation: Invalid operation 'VALUE', expected one of RANGE, COLUMN, ROW",
+		}, problems(ValidateRules(rules, nil).Errors))
+	})
+}
@@ -0,0 +1,169 @@
+package transform
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Constants for the types of the rule conditions
+const (
+	HEADER    string = "HEADER"
+	CELL      string = "CELL"
+	FILE_NAME string = "FILE_NAME"
+)
+
+// Constants for the ways a rule condition matches its value
+const (
+	EQUALS   string = "EQUALS"
+	CONTAINS string = "CONTAINS"
+	REGEX    string = "REGEX"
+	GLOB     string = "GLOB"
+)
+
+// ruleOrder returns the indexes of the rules by priority, the highest first, rules of the same
+// priority keep their order
+func ruleOrder(rules []types.Rule) []int {
+	order := make([]int, len(rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rules[order[i]].Priority > rules[order[j]].Priority
+	})
+	return order
+}
+
+// skipRule records a rule that did not apply in the manifest
+func (r *RulesExecutor) skipRule(ruleIndex int, reason string) {
+	if r.Manifest == nil {
+		return
+	}
+	r.Manifest.SkippedRules = append(r.Manifest.SkippedRules, types.SkippedRule{RuleIndex: ruleIndex, Reason: reason})
+}
+
+// checkConditions returns why the conditions of a rule do not hold, or an empty reason when the
+// rule applies
+func (r *RulesExecutor) checkConditions(ruleIndex int, rule types.Rule) (string, *types.TransformError) {
+	for conditionIndex, condition := range rule.Conditions {
+		holds, err := r.conditionHolds(rule, condition)
+		if err != nil {
+			return "", &types.TransformError{
+				Message:   fmt.Sprintf("condition %d: %s", conditionIndex, err.Error()),
+				RuleIndex: &ruleIndex,
+				Key:       "conditions",
+			}
+		}
+		if holds == condition.Negate {
+			return fmt.Sprintf("condition %d does not hold", conditionIndex), nil
+		}
+	}
+	return "", nil
+}
+
+// conditionHolds checks a condition against the workbook, Negate is left to the caller
+func (r *RulesExecutor) conditionHolds(rule types.Rule, condition types.RuleCondition) (bool, error) {
+	sheetName := condition.SheetName
+	if sheetName == "" {
+		sheetName = rule.PageCondition.SheetName
+	}
+	conditionType := conditionName(condition.Type)
+	if (conditionType == HEADER || conditionType == CELL) && !slices.Contains(r.File.GetSheetList(), sheetName) {
+		return false, nil
+	}
+
+	switch conditionType {
+	case HEADER:
+		headers, err := headerRow(r.File, sheetName, condition.HeaderRow)
+		if err != nil {
+			return false, err
+		}
+		for _, header := range headers {
+			matches, err := conditionMatches(condition, header, EQUALS)
+			if err != nil || matches {
+				return matches, err
+			}
+		}
+		return false, nil
+	case CELL:
+		if _, _, err := excelize.CellNameToCoordinates(condition.Cell); err != nil {
+			return false, fmt.Errorf("'%s' is not a valid cell", condition.Cell)
+		}
+		cellValue, err := r.File.GetCellValue(sheetName, condition.Cell)
+		if err != nil {
+			return false, err
+		}
+		return conditionMatches(condition, cellValue, EQUALS)
+	case FILE_NAME:
+		return conditionMatches(condition, r.FileName, GLOB)
+	}
+	return false, fmt.Errorf("Invalid condition type")
+}
+
+// headerRow returns the cells of the header row, without reading the rows after it
+func headerRow(f *excelize.File, sheetName string, rowNum int) ([]string, error) {
+	if rowNum == 0 {
+		rowNum = 1
+	}
+	if rowNum < 0 {
+		return nil, fmt.Errorf("'%d' is not a valid header row", rowNum)
+	}
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for current := 1; rows.Next(); current++ {
+		if current == rowNum {
+			return rows.Columns()
+		}
+	}
+	return nil, rows.Error()
+}
+
+// conditionName reads the type or the match of a condition, eg: fileName as FILE_NAME
+func conditionName(name string) string {
+	var normalized strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(rune(name[i-1])) {
+			normalized.WriteRune('_')
+		}
+		normalized.WriteRune(unicode.ToUpper(r))
+	}
+	return normalized.String()
+}
+
+// conditionMatches compares a text to the value of a condition
+func conditionMatches(condition types.RuleCondition, text string, defaultMatch string) (bool, error) {
+	match := conditionName(condition.Match)
+	if match == "" {
+		match = defaultMatch
+	}
+	switch match {
+	case EQUALS:
+		return text == condition.Value, nil
+	case CONTAINS:
+		return strings.Contains(text, condition.Value), nil
+	case REGEX:
+		pattern, err := regexp.Compile(condition.Value)
+		if err != nil {
+			return false, fmt.Errorf("'%s' is not a valid regular expression: %v", condition.Value, err)
+		}
+		return pattern.MatchString(text), nil
+	case GLOB:
+		matches, err := path.Match(condition.Value, text)
+		if err != nil {
+			return false, fmt.Errorf("'%s' is not a valid pattern", condition.Value)
+		}
+		return matches, nil
+	}
+	return false, fmt.Errorf("Invalid match")
+}
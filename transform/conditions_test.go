@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRuleConditions(t *testing.T) {
+	newFile := func() *excelize.File {
+		f := excelize.NewFile()
+		f.SetSheetRow("Sheet1", "A1", &[]string{"Template v2", "SSN"})
+		f.SetSheetRow("Sheet1", "A2", &[]string{"Jane Doe", "123-45-6789"})
+		return f
+	}
+	redact := func(cellRange string) []types.Action {
+		return []types.Action{{Operation: RANGE, Value: cellRange, ActionType: REDACT}}
+	}
+	pageCondition := types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, NonEmptyValueRedact: true}
+
+	t.Run("apply the rules whose conditions hold", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rules := []types.Rule{
+			{PageCondition: pageCondition, Actions: redact("A2:A2"), Conditions: []types.RuleCondition{
+				{Type: "cell", Cell: "A1", Value: "Template v2", Match: "contains"},
+				{Type: "header", Value: "SSN"},
+			}},
+			{PageCondition: pageCondition, Actions: redact("B2:B2"), Conditions: []types.RuleCondition{
+				{Type: "fileName", Value: "acme-*.xlsx"},
+			}},
+			{PageCondition: pageCondition, Actions: redact("B2:B2"), Conditions: []types.RuleCondition{
+				{Type: "header", Value: "(?i)^ssn$", Match: "regex", Negate: true},
+			}},
+		}
+
+		rulesExecutor := MakeRulesExecutor(f, rules)
+		rulesExecutor.FileName = "globex-2024.xlsx"
+		if transformErr := rulesExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+		rows, _ := f.GetRows("Sheet1")
+		assert.Equal(t, []string{"**redacted**", "123-45-6789"}, rows[1])
+		assert.Equal(t, []types.SkippedRule{
+			{RuleIndex: 1, Reason: "condition 0 does not hold"},
+			{RuleIndex: 2, Reason: "condition 0 does not hold"},
+		}, rulesExecutor.Manifest.SkippedRules)
+	})
+
+	t.Run("run by priority and stop on match", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rules := []types.Rule{
+			{PageCondition: pageCondition, Actions: redact("A2:A2")},
+			{PageCondition: pageCondition, Actions: redact("B2:B2"), Priority: 10, StopOnMatch: true},
+			{PageCondition: types.PageCondition{SheetName: "Missing"}, Actions: redact("A1:A1"), Priority: 5},
+		}
+
+		rulesExecutor := MakeRulesExecutor(f, rules)
+		if transformErr := rulesExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+		rows, _ := f.GetRows("Sheet1")
+		assert.Equal(t, []string{"Jane Doe", "**redacted**"}, rows[1])
+		assert.Equal(t, []types.SkippedRule{
+			{RuleIndex: 2, Reason: "sheet 'Missing' does not exist"},
+			{RuleIndex: 0, Reason: "stopped by rule 1"},
+		}, rulesExecutor.Manifest.SkippedRules)
+	})
+
+	t.Run("report invalid conditions", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rules := []types.Rule{{PageCondition: pageCondition, Actions: redact("A2:A2"), Conditions: []types.RuleCondition{
+			{Type: "cell", Cell: "A0", Value: "x"},
+			{Type: "filename", Value: "["},
+			{Type: "header", Value: "SSN(", Match: "regex"},
+			{Type: "header", SheetName: "Missing", Value: "SSN"},
+		}}}
+
+		assert.Equal(t, []string{
+			"rule 0 conditions: condition 0: 'A0' is not a valid cell",
+			"rule 0 conditions: condition 1: Invalid condition type 'filename', did you mean 'FILE_NAME'?",
+			"rule 0 conditions: condition 2: 'SSN(' is not a valid regular expression: error parsing regexp: missing closing ): `SSN(`",
+			"rule 0 conditions: condition 3: Sheet 'Missing' does not exist, the condition would not hold",
+		}, problems(ValidateRules(rules, f).Errors))
+
+		transformErr := MakeRulesExecutor(f, rules).Execute()
+		assert.Equal(t, "condition 0: 'A0' is not a valid cell", transformErr.Message)
+		assert.Equal(t, "conditions", transformErr.Key)
+	})
+}
@@ -28,8 +28,8 @@ func (a *ActionExecutor) ExcludeColumn() (err error) {
 	if colAsNum < startColAsNum || colAsNum > endColAsNum {
 		return fmt.Errorf("'%s' is out of range", col)
 	}
-	// Remove the column
-	err = file.RemoveCol(sheetName, col)
+	// Remove the column according to the merge policy
+	_, err = a.excludeColumn(colAsNum)
 	if err != nil {
 		return err
 	}
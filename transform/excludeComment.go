@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"xlsx-processor/pkg/comment"
+)
+
+// ExcludeComment removes the notes and threaded comments of the sheet, either all of them
+// or only the ones attached to the cells the manifest records as redacted
+func (a *ActionExecutor) ExcludeComment() (err error) {
+	file := a.File
+	sheetName := a.SheetName
+	scope := strings.ToUpper(a.Action.Value)
+
+	if scope != ALL && scope != REDACTED {
+		return fmt.Errorf("'%s' is invalid, expected all or redacted", a.Action.Value)
+	}
+
+	// The redacted cells are those of the manifest, a cell merely holding **redacted** was not
+	redactedCells := map[string]bool{}
+	if a.Manifest != nil {
+		for _, redactedCell := range a.Manifest.RedactedCells {
+			if redactedCell.SheetName == sheetName && !redactedCell.Excluded {
+				redactedCells[redactedCell.Cell] = true
+			}
+		}
+	}
+	isRemoved := func(cellName string) bool {
+		return scope == ALL || redactedCells[cellName]
+	}
+
+	notes, err := file.GetComments(sheetName)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		if !isRemoved(note.Cell) {
+			continue
+		}
+		err = file.DeleteComment(sheetName, note.Cell)
+		if err != nil {
+			return err
+		}
+	}
+
+	return comment.UpdateThreadedComments(file, sheetName, func(threadedComment *comment.ThreadedComment) bool {
+		return !isRemoved(threadedComment.Cell)
+	})
+}
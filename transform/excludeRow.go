@@ -24,8 +24,8 @@ func (a *ActionExecutor) ExcludeRow() (err error) {
 	if rowNum < startRowNum || rowNum > endRowNum {
 		return fmt.Errorf("'%d' is out of range", rowNum)
 	}
-	// Remove the row
-	err = file.RemoveRow(sheetName, rowNum)
+	// Remove the row according to the merge policy
+	_, err = a.excludeRow(rowNum)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,239 @@
+package transform
+
+import (
+	"slices"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// redactCell redacts a single cell and records its original value in the manifest. The value is
+// recorded as stored, not as its number format displays it, so it can be found in the package
+func redactCell(f *excelize.File, manifest *types.Manifest, sheetName, cellName string, nonEmptyValueRedact bool, ruleIndex, actionIndex *int) error {
+	cellValue, err := f.GetCellValue(sheetName, cellName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return err
+	}
+
+	err = cell.SetValue(f, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+	if err != nil {
+		return err
+	}
+
+	recordCell(manifest, sheetName, cellName, cellValue, ruleIndex, actionIndex)
+	return nil
+}
+
+// recordCell records the original value of a changed cell in the manifest
+func recordCell(manifest *types.Manifest, sheetName, cellName, cellValue string, ruleIndex, actionIndex *int) {
+	// Empty and already redacted cells have nothing worth recording
+	if manifest == nil || cellValue == "" || cellValue == "**redacted**" {
+		return
+	}
+	manifest.RedactedCells = append(manifest.RedactedCells, types.RedactedCell{
+		SheetName:   sheetName,
+		Cell:        cellName,
+		RuleIndex:   ruleIndex,
+		ActionIndex: actionIndex,
+		Value:       cellValue,
+	})
+}
+
+// RedactCell redacts a single cell targeted by the action
+func (a *ActionExecutor) RedactCell(cellName string) error {
+	// Cells inside a merged area are resolved according to the merge policy
+	cellName, err := a.resolveMergedTarget(cellName)
+	if err != nil {
+		return err
+	}
+	return redactCell(a.File, a.Manifest, a.SheetName, cellName, a.NonEmptyValueRedact, &a.RuleIndex, &a.ActionIndex)
+}
+
+// RecordCell reports a cell the action redacted in its own way, eg: masked, along with its
+// original value, so it is purged and verified like the other redacted cells
+func (a *ActionExecutor) RecordCell(cellName, originalValue string) {
+	recordCell(a.Manifest, a.SheetName, cellName, originalValue, &a.RuleIndex, &a.ActionIndex)
+}
+
+// recordComputedCell reports a cell a compute action changed. Its original is not a secret, so
+// it is kept apart from the redacted cells and never looked for in the output
+func (a *ActionExecutor) recordComputedCell(cellName string) {
+	if a.Manifest == nil {
+		return
+	}
+	a.Manifest.ComputedCells = append(a.Manifest.ComputedCells, types.RedactedCell{
+		SheetName:   a.SheetName,
+		Cell:        cellName,
+		RuleIndex:   &a.RuleIndex,
+		ActionIndex: &a.ActionIndex,
+	})
+}
+
+// ShiftRedactedCells keeps the manifest in sync after a row or a column of a sheet is removed,
+// the dimension that was not removed is passed as 0. The removal is recorded and the redacted
+// cells after it are shifted
+func ShiftRedactedCells(manifest *types.Manifest, sheetName string, removedCol, removedRow int) error {
+	if manifest == nil {
+		return nil
+	}
+
+	originalCol, originalRow := OriginalCoordinates(manifest, sheetName, removedCol, removedRow)
+	if removedCol == 0 {
+		originalCol = 0
+	}
+	if removedRow == 0 {
+		originalRow = 0
+	}
+	manifest.Exclusions = append(manifest.Exclusions, types.Exclusion{
+		SheetName: sheetName,
+		Row:       originalRow,
+		Column:    originalCol,
+	})
+
+	for i := range manifest.RedactedCells {
+		redactedCell := &manifest.RedactedCells[i]
+		if redactedCell.SheetName != sheetName || redactedCell.Excluded {
+			continue
+		}
+
+		col, row, err := excelize.CellNameToCoordinates(redactedCell.Cell)
+		if err != nil {
+			return err
+		}
+		switch {
+		case col == removedCol || row == removedRow:
+			redactedCell.Excluded = true
+			continue
+		case removedCol > 0 && col > removedCol:
+			col--
+		case removedRow > 0 && row > removedRow:
+			row--
+		}
+		redactedCell.Cell, err = excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeExcludedPictures deletes the pictures anchored in a row or a column about to be removed,
+// the dimension that is not removed is passed as 0
+func removeExcludedPictures(f *excelize.File, manifest *types.Manifest, sheetName string, removedCol, removedRow int) error {
+	removed, err := sheet.DeletePictures(f, sheetName, func(col, row int) bool {
+		return col == removedCol || row == removedRow
+	})
+	if err != nil {
+		return err
+	}
+
+	if manifest != nil {
+		manifest.RemovedPictures += removed
+	}
+	return nil
+}
+
+// ExcludeRedactedSheet records the removal of a sheet and marks its redacted cells as excluded
+func ExcludeRedactedSheet(manifest *types.Manifest, sheetName string) {
+	if manifest == nil {
+		return
+	}
+	manifest.Exclusions = append(manifest.Exclusions, types.Exclusion{SheetName: sheetName})
+
+	for i := range manifest.RedactedCells {
+		if manifest.RedactedCells[i].SheetName == sheetName {
+			manifest.RedactedCells[i].Excluded = true
+		}
+	}
+}
+
+// renameRedactedSheet keeps the manifest in sync after a sheet is renamed
+func renameRedactedSheet(manifest *types.Manifest, sheetName, newName string) {
+	if manifest == nil {
+		return
+	}
+	for i := range manifest.RedactedCells {
+		if manifest.RedactedCells[i].SheetName == sheetName {
+			manifest.RedactedCells[i].SheetName = newName
+		}
+	}
+	for i := range manifest.ScrubbedFormulas {
+		if manifest.ScrubbedFormulas[i].SheetName == sheetName {
+			manifest.ScrubbedFormulas[i].SheetName = newName
+		}
+	}
+	for i := range manifest.ComputedCells {
+		if manifest.ComputedCells[i].SheetName == sheetName {
+			manifest.ComputedCells[i].SheetName = newName
+		}
+	}
+	for i := range manifest.Exclusions {
+		if manifest.Exclusions[i].SheetName == sheetName {
+			manifest.Exclusions[i].SheetName = newName
+		}
+	}
+}
+
+// OriginalCoordinates maps a position of a sheet back to the original workbook by accounting
+// for the rows and columns removed before it
+func OriginalCoordinates(manifest *types.Manifest, sheetName string, col, row int) (int, int) {
+	if manifest == nil {
+		return col, row
+	}
+
+	var removedCols, removedRows []int
+	for _, exclusion := range manifest.Exclusions {
+		if exclusion.SheetName != sheetName {
+			continue
+		}
+		if exclusion.Column > 0 {
+			removedCols = append(removedCols, exclusion.Column)
+		}
+		if exclusion.Row > 0 {
+			removedRows = append(removedRows, exclusion.Row)
+		}
+	}
+
+	return toOriginal(removedCols, col), toOriginal(removedRows, row)
+}
+
+// toOriginal maps a row or column number back to the original numbering
+func toOriginal(removed []int, num int) int {
+	slices.Sort(removed)
+	for _, removedNum := range removed {
+		if removedNum <= num {
+			num++
+		}
+	}
+	return num
+}
+
+// CurrentCoordinates maps a position of the original workbook to the sheet as it is now,
+// excluded is set when its row, its column or its sheet was removed
+func CurrentCoordinates(manifest *types.Manifest, sheetName string, col, row int) (currentCol, currentRow int, excluded bool) {
+	currentCol, currentRow = col, row
+	if manifest == nil {
+		return currentCol, currentRow, false
+	}
+
+	for _, exclusion := range manifest.Exclusions {
+		if exclusion.SheetName != sheetName {
+			continue
+		}
+		switch {
+		case exclusion.Column == 0 && exclusion.Row == 0:
+			return 0, 0, true
+		case exclusion.Column == col || exclusion.Row == row:
+			return 0, 0, true
+		case exclusion.Column > 0 && exclusion.Column < col:
+			currentCol--
+		case exclusion.Row > 0 && exclusion.Row < row:
+			currentRow--
+		}
+	}
+	return currentCol, currentRow, false
+}
@@ -0,0 +1,226 @@
+package transform
+
+import (
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// getMergePolicy returns the merge policy of the action. By default a redaction EXPANDs to the
+// whole merged area, so its value is redacted, while an exclusion UNMERGEs the areas it cuts
+// through, so only the excluded rows and columns are removed
+func (a *ActionExecutor) getMergePolicy(excluding bool) string {
+	if a.MergePolicy == "" && excluding {
+		return UNMERGE
+	}
+	if a.MergePolicy == "" {
+		return EXPAND
+	}
+	return strings.ToUpper(a.MergePolicy)
+}
+
+// getMergeAreas returns the merged areas of the sheet, they are loaded once per action
+func (a *ActionExecutor) getMergeAreas() ([]cell.MergeArea, error) {
+	if a.mergeAreas != nil {
+		return a.mergeAreas, nil
+	}
+
+	mergeAreas, err := cell.GetMergeAreas(a.File, a.SheetName)
+	if err != nil {
+		return nil, err
+	}
+	a.mergeAreas = mergeAreas
+	return mergeAreas, nil
+}
+
+// resolveMergedTarget applies the merge policy to a cell about to be redacted. With EXPAND
+// a cell inside a merged area resolves to the top left cell, which holds the value of the
+// whole area. With UNMERGE the area is unmerged and filled with its value first
+func (a *ActionExecutor) resolveMergedTarget(cellName string) (string, error) {
+	mergeAreas, err := a.getMergeAreas()
+	if err != nil {
+		return "", err
+	}
+	if len(mergeAreas) == 0 {
+		return cellName, nil
+	}
+
+	col, row, err := excelize.CellNameToCoordinates(cellName)
+	if err != nil {
+		return "", err
+	}
+	mergeArea, found := cell.FindMergeArea(mergeAreas, col, row)
+	if !found {
+		return cellName, nil
+	}
+
+	if a.getMergePolicy(false) == UNMERGE {
+		return cellName, a.unmergeAndFill(mergeArea)
+	}
+	return mergeArea.TopLeft(), nil
+}
+
+// unmergeAndFill unmerges an area and copies the value of its top left cell to every cell
+func (a *ActionExecutor) unmergeAndFill(mergeArea cell.MergeArea) error {
+	file := a.File
+	sheetName := a.SheetName
+
+	topLeft := mergeArea.TopLeft()
+	bottomRight, err := excelize.CoordinatesToCellName(mergeArea.EndCol, mergeArea.EndRow)
+	if err != nil {
+		return err
+	}
+	value, err := file.GetCellValue(sheetName, topLeft)
+	if err != nil {
+		return err
+	}
+
+	err = file.UnmergeCell(sheetName, topLeft, bottomRight)
+	if err != nil {
+		return err
+	}
+	for col := mergeArea.StartCol; col <= mergeArea.EndCol; col++ {
+		for row := mergeArea.StartRow; row <= mergeArea.EndRow; row++ {
+			if col == mergeArea.StartCol && row == mergeArea.StartRow {
+				continue
+			}
+			cellName, err := excelize.CoordinatesToCellName(col, row)
+			if err != nil {
+				return err
+			}
+			err = file.SetCellValue(sheetName, cellName, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// The merged areas changed so they are loaded again on the next lookup
+	a.mergeAreas = nil
+	return nil
+}
+
+// excludeRow removes a row according to the merge policy and returns the first removed row.
+// With UNMERGE the merged areas the row cuts through are unmerged and filled with their value
+// so only the row itself is removed, with EXPAND the rows of those areas are removed as well
+func (a *ActionExecutor) excludeRow(rowNum int) (int, error) {
+	mergeAreas, err := a.getMergeAreas()
+	if err != nil {
+		return 0, err
+	}
+
+	startRow, endRow := rowNum, rowNum
+	for _, mergeArea := range mergeAreas {
+		if mergeArea.StartRow > rowNum || mergeArea.EndRow < rowNum || mergeArea.StartRow == mergeArea.EndRow {
+			continue
+		}
+		if a.getMergePolicy(true) == UNMERGE {
+			if err = a.unmergeAndFill(mergeArea); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if a.getMergePolicy(true) != UNMERGE {
+		startRow, endRow = expandToMergedRows(mergeAreas, rowNum)
+	}
+
+	// Removing from the bottom so the remaining row numbers do not shift
+	for row := endRow; row >= startRow; row-- {
+		if a.RemoveExcludedPictures {
+			if err = removeExcludedPictures(a.File, a.Manifest, a.SheetName, 0, row); err != nil {
+				return 0, err
+			}
+		}
+		if err = a.File.RemoveRow(a.SheetName, row); err != nil {
+			return 0, err
+		}
+		// Keep the redacted cells below the removed row in sync
+		if err = ShiftRedactedCells(a.Manifest, a.SheetName, 0, row); err != nil {
+			return 0, err
+		}
+	}
+
+	a.mergeAreas = nil
+	return startRow, nil
+}
+
+// excludeColumn removes a column according to the merge policy and returns the first removed
+// column, see excludeRow
+func (a *ActionExecutor) excludeColumn(colNum int) (int, error) {
+	mergeAreas, err := a.getMergeAreas()
+	if err != nil {
+		return 0, err
+	}
+
+	startCol, endCol := colNum, colNum
+	for _, mergeArea := range mergeAreas {
+		if mergeArea.StartCol > colNum || mergeArea.EndCol < colNum || mergeArea.StartCol == mergeArea.EndCol {
+			continue
+		}
+		if a.getMergePolicy(true) == UNMERGE {
+			if err = a.unmergeAndFill(mergeArea); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if a.getMergePolicy(true) != UNMERGE {
+		startCol, endCol = expandToMergedCols(mergeAreas, colNum)
+	}
+
+	// Removing from the right so the remaining column numbers do not shift
+	for col := endCol; col >= startCol; col-- {
+		colName, err := excelize.ColumnNumberToName(col)
+		if err != nil {
+			return 0, err
+		}
+		if a.RemoveExcludedPictures {
+			if err = removeExcludedPictures(a.File, a.Manifest, a.SheetName, col, 0); err != nil {
+				return 0, err
+			}
+		}
+		if err = a.File.RemoveCol(a.SheetName, colName); err != nil {
+			return 0, err
+		}
+		// Keep the redacted cells right of the removed column in sync
+		if err = ShiftRedactedCells(a.Manifest, a.SheetName, col, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	a.mergeAreas = nil
+	return startCol, nil
+}
+
+// expandToMergedRows grows a row into the span of rows that no merged area crosses
+func expandToMergedRows(mergeAreas []cell.MergeArea, rowNum int) (startRow, endRow int) {
+	startRow, endRow = rowNum, rowNum
+	for changed := true; changed; {
+		changed = false
+		for _, mergeArea := range mergeAreas {
+			intersects := mergeArea.StartRow <= endRow && mergeArea.EndRow >= startRow
+			if intersects && (mergeArea.StartRow < startRow || mergeArea.EndRow > endRow) {
+				startRow, endRow = min(startRow, mergeArea.StartRow), max(endRow, mergeArea.EndRow)
+				changed = true
+			}
+		}
+	}
+	return startRow, endRow
+}
+
+// expandToMergedCols grows a column into the span of columns that no merged area crosses
+func expandToMergedCols(mergeAreas []cell.MergeArea, colNum int) (startCol, endCol int) {
+	startCol, endCol = colNum, colNum
+	for changed := true; changed; {
+		changed = false
+		for _, mergeArea := range mergeAreas {
+			intersects := mergeArea.StartCol <= endCol && mergeArea.EndCol >= startCol
+			if intersects && (mergeArea.StartCol < startCol || mergeArea.EndCol > endCol) {
+				startCol, endCol = min(startCol, mergeArea.StartCol), max(endCol, mergeArea.EndCol)
+				changed = true
+			}
+		}
+	}
+	return startCol, endCol
+}
@@ -0,0 +1,126 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestMergePolicy(t *testing.T) {
+	newMergedFile := func() *excelize.File {
+		f := excelize.NewFile()
+		// A title merged across A1:C1 and a name merged across A2:A3
+		f.SetCellValue("Sheet1", "A1", "Quarterly report")
+		f.MergeCell("Sheet1", "A1", "C1")
+		f.SetCellValue("Sheet1", "A2", "Jane Doe")
+		f.MergeCell("Sheet1", "A2", "A3")
+		f.SetCellValue("Sheet1", "B2", "100")
+		f.SetCellValue("Sheet1", "B3", "200")
+		f.SetCellValue("Sheet1", "A4", "Total")
+		f.SetCellValue("Sheet1", "B4", "300")
+		return f
+	}
+
+	testCases := []struct {
+		name           string
+		action         types.Action
+		mergePolicy    string
+		expected       map[string]string
+		expectedMerges int
+	}{
+		{
+			name:           "expand redacts a cell inside a merged area",
+			action:         types.Action{ActionType: REDACT, Operation: RANGE, Value: "B1:B1"},
+			expected:       map[string]string{"A1": "**redacted**", "A2": "Jane Doe"},
+			expectedMerges: 2,
+		},
+		{
+			name:           "unmerge fills the area before redacting",
+			action:         types.Action{ActionType: REDACT, Operation: RANGE, Value: "B1:B1"},
+			mergePolicy:    UNMERGE,
+			expected:       map[string]string{"A1": "Quarterly report", "B1": "**redacted**", "C1": "Quarterly report"},
+			expectedMerges: 1,
+		},
+		{
+			name:           "expand excludes every row of a merged area",
+			action:         types.Action{ActionType: EXCLUDE, Operation: ROW, Value: "3"},
+			mergePolicy:    EXPAND,
+			expected:       map[string]string{"A2": "Total", "B2": "300"},
+			expectedMerges: 1,
+		},
+		{
+			name:           "unmerge excludes only the row",
+			action:         types.Action{ActionType: EXCLUDE, Operation: ROW, Value: "2"},
+			mergePolicy:    UNMERGE,
+			expected:       map[string]string{"A2": "Jane Doe", "B2": "200", "A3": "Total"},
+			expectedMerges: 1,
+		},
+		{
+			name:           "exclusions unmerge by default",
+			action:         types.Action{ActionType: EXCLUDE, Operation: COLUMN, Value: "B"},
+			expected:       map[string]string{"A1": "Quarterly report", "B1": "Quarterly report", "A2": "Jane Doe", "B2": ""},
+			expectedMerges: 1,
+		},
+		{
+			name:           "expand excludes every column of a merged area",
+			action:         types.Action{ActionType: EXCLUDE, Operation: COLUMN, Value: "B"},
+			mergePolicy:    EXPAND,
+			expected:       map[string]string{"A1": "", "A2": ""},
+			expectedMerges: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newMergedFile()
+			defer f.Close()
+
+			actionExecutor := MakeActionExecutor(f, "Sheet1", true, &tc.action, 0, 0)
+			actionExecutor.MergePolicy = tc.mergePolicy
+			transformErr := actionExecutor.Execute()
+			if transformErr != nil {
+				t.Fatalf("failed to execute action: %s", transformErr.Message)
+			}
+
+			for cellName, expected := range tc.expected {
+				value, _ := f.GetCellValue("Sheet1", cellName)
+				assert.Equal(t, expected, value)
+			}
+			mergeCells, err := f.GetMergeCells("Sheet1")
+			if err != nil {
+				t.Fatalf("failed to get merged cells: %v", err)
+			}
+			assert.Equal(t, tc.expectedMerges, len(mergeCells))
+		})
+	}
+
+	t.Run("allowlist rules apply the merge policy to the removed rows", func(t *testing.T) {
+		// Row 3 is removed alone either way, expanding it would remove the kept row 2
+		for mergePolicy, expected := range map[string][]string{
+			"":     {"Name", "Jane Doe", "Total"},
+			EXPAND: {"Name", "Jane Doe", "Total"},
+		} {
+			f := newMergedFile()
+			f.SetCellValue("Sheet1", "A1", "Name")
+			f.UnmergeCell("Sheet1", "A1", "C1")
+			rules := []types.Rule{{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", Mode: ALLOWLIST, IncludeFormulas: true, MergePolicy: mergePolicy},
+				Actions: []types.Action{
+					{ActionType: EXCLUDE, Operation: ROW, Value: "1"},
+					{ActionType: EXCLUDE, Operation: ROW, Value: "2"},
+					{ActionType: EXCLUDE, Operation: ROW, Value: "4"},
+				},
+			}}
+			if transformErr := MakeRulesExecutor(f, rules).Execute(); transformErr != nil {
+				t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+			}
+
+			cols, _ := f.GetCols("Sheet1")
+			assert.Equal(t, expected, cols[0])
+			f.Close()
+		}
+	})
+}
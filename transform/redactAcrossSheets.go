@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"github.com/xuri/excelize/v2"
+
+	"xlsx-processor/pkg/types"
+)
+
+// redactAcrossSheets redacts every other occurrence of the values redacted by the rules
+// that set redactAcrossSheets, on every sheet of the workbook including the sheets that
+// no rule targets
+func (r *RulesExecutor) redactAcrossSheets() *types.TransformError {
+	file := r.File
+	rules := *r.rules
+
+	// Mapping each redacted value to the first rule that redacted it
+	valueRules := map[string]int{}
+	for _, redactedCell := range r.Manifest.RedactedCells {
+		if redactedCell.RuleIndex == nil {
+			continue
+		}
+		ruleIndex := *redactedCell.RuleIndex
+		if !rules[ruleIndex].PageCondition.RedactAcrossSheets {
+			continue
+		}
+		if _, exists := valueRules[redactedCell.Value]; !exists {
+			valueRules[redactedCell.Value] = ruleIndex
+		}
+	}
+
+	if len(valueRules) == 0 {
+		return nil
+	}
+
+	for _, sheetName := range file.GetSheetList() {
+		// The manifest holds the values as stored
+		rows, err := file.GetRows(sheetName, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return &types.TransformError{
+				Message: err.Error(),
+				Key:     "redactAcrossSheets",
+			}
+		}
+
+		for rowIndex, row := range rows {
+			for colIndex, cellValue := range row {
+				ruleIndex, exists := valueRules[cellValue]
+				if !exists {
+					continue
+				}
+				cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+				if err != nil {
+					return &types.TransformError{
+						Message:   err.Error(),
+						RuleIndex: &ruleIndex,
+						Key:       "redactAcrossSheets",
+					}
+				}
+				// Redacting with the settings of the rule that first redacted the value
+				nonEmptyValueRedact := rules[ruleIndex].PageCondition.NonEmptyValueRedact
+				err = redactCell(file, r.Manifest, sheetName, cellName, nonEmptyValueRedact, &ruleIndex, nil)
+				if err != nil {
+					return &types.TransformError{
+						Message:   err.Error(),
+						RuleIndex: &ruleIndex,
+						Key:       "redactAcrossSheets",
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
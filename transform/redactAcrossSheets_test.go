@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRedactAcrossSheets(t *testing.T) {
+	testCases := []struct {
+		name               string
+		redactAcrossSheets bool
+		expectedDetail     string
+	}{
+		{"copies on other sheets are redacted", true, "**redacted**"},
+		{"copies on other sheets are kept", false, "Acme Corp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := excelize.NewFile()
+			defer f.Close()
+			if _, err := f.NewSheet("Detail"); err != nil {
+				t.Fatalf("failed to create sheet: %v", err)
+			}
+			f.SetCellValue("Sheet1", "A1", "Acme Corp")
+			f.SetCellValue("Sheet1", "B1", "Total")
+			f.SetCellValue("Detail", "C3", "Acme Corp")
+			f.SetCellValue("Detail", "C4", "Other Client")
+
+			rules := []types.Rule{
+				{
+					PageCondition: types.PageCondition{
+						SheetName:           "Sheet1",
+						IncludeFormulas:     true,
+						NonEmptyValueRedact: true,
+						RedactAcrossSheets:  tc.redactAcrossSheets,
+					},
+					Actions: []types.Action{
+						{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"},
+					},
+				},
+			}
+			rulesExecutor := MakeRulesExecutor(f, rules)
+			transformErr := rulesExecutor.Execute()
+			if transformErr != nil {
+				t.Fatalf("failed to execute rules: %s", transformErr.Message)
+			}
+
+			detail, _ := f.GetCellValue("Detail", "C3")
+			assert.Equal(t, tc.expectedDetail, detail)
+			other, _ := f.GetCellValue("Detail", "C4")
+			assert.Equal(t, "Other Client", other)
+			assert.Equal(t, "Acme Corp", rulesExecutor.Manifest.RedactedCells[0].Value)
+		})
+	}
+}
+
+func TestRedactAcrossSheetsStoredValues(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.NewSheet("Detail")
+	// The same rate shown as a percentage and as a decimal
+	percent, _ := f.NewStyle(&excelize.Style{NumFmt: 9})
+	decimal, _ := f.NewStyle(&excelize.Style{NumFmt: 2})
+	f.SetCellValue("Sheet1", "A1", 0.25)
+	f.SetCellStyle("Sheet1", "A1", "A1", percent)
+	f.SetCellValue("Sheet1", "B1", "Rate")
+	f.SetCellValue("Detail", "C3", 0.25)
+	f.SetCellStyle("Detail", "C3", "C3", decimal)
+
+	rules := []types.Rule{{
+		PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, NonEmptyValueRedact: true, RedactAcrossSheets: true},
+		Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"}},
+	}}
+	rulesExecutor := MakeRulesExecutor(f, rules)
+	if transformErr := rulesExecutor.Execute(); transformErr != nil {
+		t.Fatalf("failed to execute rules: %s", transformErr.Message)
+	}
+
+	// The originals are recorded as stored, which is what the verification searches for
+	assert.Equal(t, 2, len(rulesExecutor.Manifest.RedactedCells))
+	for _, redactedCell := range rulesExecutor.Manifest.RedactedCells {
+		assert.Equal(t, "0.25", redactedCell.Value)
+	}
+}
@@ -10,7 +10,6 @@ import (
 func (a *ActionExecutor) RedactBgColor() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	colorHex := a.Action.Value
 	foundBgColor := false
 
@@ -36,7 +35,7 @@ func (a *ActionExecutor) RedactBgColor() (err error) {
 			if bgColor == colorHex {
 				foundBgColor = true
 				// Redact the cell
-				err = cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+				err = a.RedactCell(cellName)
 				if err != nil {
 					return err
 				}
@@ -11,7 +11,6 @@ import (
 func (a *ActionExecutor) RedactColumn() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	col := a.Action.Value
 	/*
 		User input validation
@@ -60,7 +59,7 @@ func (a *ActionExecutor) RedactColumn() (err error) {
 			return err
 		}
 		// Redact the cell
-		err = cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+		err = a.RedactCell(cellName)
 		if err != nil {
 			return err
 		}
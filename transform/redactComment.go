@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"xlsx-processor/pkg/comment"
+	"xlsx-processor/pkg/matcher"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// RedactComment redacts the text matching the value inside the notes and threaded comments,
+// the comments themselves are kept
+func (a *ActionExecutor) RedactComment() (err error) {
+	file := a.File
+	sheetName := a.SheetName
+	value := a.Action.Value
+
+	pattern := regexp.QuoteMeta(value)
+	if a.Action.Comment != nil && a.Action.Comment.Regex {
+		pattern = value
+	}
+	valueRegex, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid regular expression: %w", value, err)
+	}
+	find := func(text string) [][]int {
+		return matcher.MergeLocations(valueRegex.FindAllStringIndex(text, -1))
+	}
+	redactText := func(text string) string {
+		return matcher.RedactRuns([]string{text}, find(text))[0]
+	}
+
+	notes, err := file.GetComments(sheetName)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		// The text and the runs of a note are matched as a whole, a value may span several runs
+		runs := []string{note.Text}
+		for _, run := range note.Paragraph {
+			runs = append(runs, run.Text)
+		}
+		redactedRuns := matcher.RedactRuns(runs, find(strings.Join(runs, "")))
+		redactedNote := note
+		redactedNote.Text = redactedRuns[0]
+		redactedNote.Paragraph = make([]excelize.RichTextRun, len(note.Paragraph))
+		for i, run := range note.Paragraph {
+			run.Text = redactedRuns[i+1]
+			redactedNote.Paragraph[i] = run
+		}
+		if comment.GetNoteText(redactedNote) == comment.GetNoteText(note) {
+			continue
+		}
+
+		// Notes cannot be edited in place so they are replaced
+		err = file.DeleteComment(sheetName, note.Cell)
+		if err != nil {
+			return err
+		}
+		err = file.AddComment(sheetName, redactedNote)
+		if err != nil {
+			return err
+		}
+	}
+
+	return comment.UpdateThreadedComments(file, sheetName, func(threadedComment *comment.ThreadedComment) bool {
+		threadedComment.Text = redactText(threadedComment.Text)
+		return true
+	})
+}
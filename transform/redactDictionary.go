@@ -0,0 +1,163 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/matcher"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/storage"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetColumnPattern matches a column of a sheet in the same workbook, eg: Roster!B or 'Client List'!C
+var sheetColumnPattern = regexp.MustCompile(`^'?(.+?)'?!([A-Za-z]{1,3})$`)
+
+func (a *ActionExecutor) RedactDictionary() (err error) {
+	f := a.File
+	sheetName := a.SheetName
+	options := a.Action.Dictionary
+	if options == nil {
+		options = &types.DictionaryOptions{}
+	}
+
+	terms, err := a.getDictionaryTerms()
+	if err != nil {
+		return err
+	}
+	dictionary := matcher.NewDictionary(terms, options.CaseInsensitive, options.WholeWord)
+	if dictionary.Len() == 0 {
+		return fmt.Errorf("the dictionary has no terms")
+	}
+
+	cols, err := f.GetCols(sheetName)
+	if err != nil {
+		return err
+	}
+
+	for colIndex, col := range cols {
+		// Iterate over each cell in the row
+		for rowIndex, cellValue := range col {
+			// Check if the cell contains any of the terms
+			if !dictionary.Contains(cellValue) {
+				continue
+			}
+			// Access individual cell
+			cellName, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+			if err != nil {
+				return err
+			}
+			// Redact the cell
+			err = a.RedactCell(cellName)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getDictionaryTerms gathers the terms from the value, the inline terms and the stored term list.
+// The value is either a column of a sheet in the workbook, below its header, or terms separated
+// by new lines
+func (a *ActionExecutor) getDictionaryTerms() ([]string, error) {
+	var terms []string
+	value := a.Action.Value
+	options := a.Action.Dictionary
+
+	if matches := sheetColumnPattern.FindStringSubmatch(value); matches != nil {
+		if !slices.Contains(a.File.GetSheetList(), matches[1]) {
+			return nil, fmt.Errorf("sheet '%s' of the dictionary column '%s' does not exist", matches[1], value)
+		}
+		cols, err := a.File.GetCols(matches[1])
+		if err != nil {
+			return nil, err
+		}
+		colIndex := cell.ColumnToNumber(matches[2]) - 1
+		if colIndex < len(cols) {
+			terms = append(terms, withoutHeader(cols[colIndex])...)
+		}
+	} else if value != "" {
+		terms = append(terms, strings.Split(value, "\n")...)
+	}
+
+	if options == nil {
+		return terms, nil
+	}
+	terms = append(terms, options.Terms...)
+
+	if options.Source != nil {
+		storedTerms, err := LoadTermList(*options.Source)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, storedTerms...)
+	}
+
+	return terms, nil
+}
+
+// LoadTermList downloads a term list kept in a storage backend
+func LoadTermList(source types.Input) ([]string, error) {
+	fileBytes, err := storage.GetFileBytes(source.StorageType, source)
+	if err != nil {
+		return nil, err
+	}
+	return parseTermList(source.Reference.Prefix, fileBytes)
+}
+
+// parseTermList reads a stored term list: the first column of the first sheet of a workbook,
+// below its header, the first field of each record of a csv file, otherwise one term per line
+func parseTermList(fileName string, fileBytes []byte) ([]string, error) {
+	fileName = strings.ToLower(fileName)
+
+	switch {
+	case strings.HasSuffix(fileName, ".xlsx") || strings.HasSuffix(fileName, ".xlsm"):
+		f, err := file.InitFileFromBytes(fileBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the term list: %w", err)
+		}
+		defer f.Close()
+		cols, err := f.GetCols(f.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+		if len(cols) == 0 {
+			return nil, nil
+		}
+		return withoutHeader(cols[0]), nil
+	case strings.HasSuffix(fileName, ".csv"):
+		reader := csv.NewReader(bytes.NewReader(fileBytes))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the term list: %w", err)
+		}
+		terms := make([]string, 0, len(records))
+		for _, record := range records {
+			if len(record) > 0 {
+				terms = append(terms, record[0])
+			}
+		}
+		return terms, nil
+	default:
+		return strings.Split(strings.ReplaceAll(string(fileBytes), "\r\n", "\n"), "\n"), nil
+	}
+}
+
+// withoutHeader drops the first row of a column of terms, its header, eg: Name
+func withoutHeader(col []string) []string {
+	if len(col) < 2 {
+		return nil
+	}
+	return col[1:]
+}
@@ -0,0 +1,84 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRedactDictionary(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if _, err := f.NewSheet("Roster"); err != nil {
+		t.Fatalf("failed to create sheet: %v", err)
+	}
+	f.SetSheetCol("Roster", "B1", &[]string{"Name", "Jane Doe", "John Smith"})
+	f.SetSheetCol("Sheet1", "A1", &[]string{"Paid to JANE DOE", "Paid to Johnson", "Bonus: john smith", "Rent", "Name"})
+
+	action := &types.Action{
+		ActionType: REDACT,
+		Operation:  DICTIONARY,
+		Value:      "Roster!B",
+		Dictionary: &types.DictionaryOptions{Terms: []string{"Rent"}, CaseInsensitive: true, WholeWord: true},
+	}
+	transformErr := MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute()
+	if transformErr != nil {
+		t.Fatalf("failed to execute action: %s", transformErr.Message)
+	}
+
+	cols, err := f.GetCols("Sheet1")
+	if err != nil {
+		t.Fatalf("failed to get cols: %v", err)
+	}
+	// The header of the term column is not a term
+	assert.Equal(t, []string{"**redacted**", "Paid to Johnson", "**redacted**", "**redacted**", "Name"}, cols[0])
+}
+
+func TestRedactDictionaryMissingSheet(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetCol("Sheet1", "A1", &[]string{"Roster!B", "Jane Doe"})
+
+	action := &types.Action{ActionType: REDACT, Operation: DICTIONARY, Value: "Roster!B"}
+	transformErr := MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute()
+	if transformErr == nil {
+		t.Fatalf("expected an error for the missing sheet")
+	}
+	assert.Equal(t, "sheet 'Roster' of the dictionary column 'Roster!B' does not exist", transformErr.Message)
+
+	// The reference is not read as a term
+	value, err := f.GetCellValue("Sheet1", "A1")
+	if err != nil {
+		t.Fatalf("failed to get cell value: %v", err)
+	}
+	assert.Equal(t, "Roster!B", value)
+}
+
+func TestParseTermList(t *testing.T) {
+	t.Run("skip the header of a workbook", func(t *testing.T) {
+		f := excelize.NewFile()
+		defer f.Close()
+		f.SetSheetCol("Sheet1", "A1", &[]string{"Name", "Jane Doe", "John Smith"})
+		buffer, err := f.WriteToBuffer()
+		if err != nil {
+			t.Fatalf("failed to write workbook: %v", err)
+		}
+
+		terms, err := parseTermList("terms/Clients.XLSX", buffer.Bytes())
+		if err != nil {
+			t.Fatalf("failed to parse the term list: %v", err)
+		}
+		assert.Equal(t, []string{"Jane Doe", "John Smith"}, terms)
+	})
+
+	t.Run("keep every line of a text file", func(t *testing.T) {
+		terms, err := parseTermList("terms.txt", []byte("Jane Doe\r\nJohn Smith"))
+		if err != nil {
+			t.Fatalf("failed to parse the term list: %v", err)
+		}
+		assert.Equal(t, []string{"Jane Doe", "John Smith"}, terms)
+	})
+}
@@ -0,0 +1,135 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func (a *ActionExecutor) RedactLabelAdjacent() (err error) {
+	file := a.File
+	sheetName := a.SheetName
+	label := a.Action.Value
+	options := a.Action.Label
+	if options == nil {
+		options = &types.LabelOptions{}
+	}
+
+	/*
+		User input validation
+	*/
+	direction := strings.ToUpper(options.Direction)
+	if direction == "" {
+		direction = RIGHT
+	}
+	colStep, rowStep := 0, 0
+	switch direction {
+	case RIGHT:
+		colStep = 1
+	case LEFT:
+		colStep = -1
+	case BELOW:
+		rowStep = 1
+	case ABOVE:
+		rowStep = -1
+	default:
+		return fmt.Errorf("'%s' is not a valid direction", options.Direction)
+	}
+	offset := max(options.Offset, 1)
+	count := max(options.Count, 1)
+
+	matchesLabel := func(cellValue string) bool {
+		if options.CaseInsensitive {
+			return strings.EqualFold(strings.TrimSpace(cellValue), strings.TrimSpace(label))
+		}
+		return strings.TrimSpace(cellValue) == strings.TrimSpace(label)
+	}
+	if options.Regex {
+		pattern := label
+		if options.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		labelRegex, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid regular expression: %w", label, err)
+		}
+		matchesLabel = labelRegex.MatchString
+	}
+
+	mergeAreas, err := cell.GetMergeAreas(file, sheetName)
+	if err != nil {
+		return err
+	}
+	// stepOver moves a position to the far edge of a merged area in the walking direction
+	stepOver := func(col, row int, mergeArea cell.MergeArea) (int, int) {
+		switch direction {
+		case RIGHT:
+			return mergeArea.EndCol, row
+		case LEFT:
+			return mergeArea.StartCol, row
+		case BELOW:
+			return col, mergeArea.EndRow
+		default:
+			return col, mergeArea.StartRow
+		}
+	}
+
+	rows, err := file.GetRows(sheetName)
+	if err != nil {
+		return err
+	}
+
+	// Collecting the targets before redacting anything so redacted values cannot be mistaken for labels
+	var targets []string
+	for rowIndex, row := range rows {
+		for colIndex, cellValue := range row {
+			if cellValue == "" || !matchesLabel(cellValue) {
+				continue
+			}
+
+			col, row := colIndex+1, rowIndex+1
+			// A merged label starts the walk from the edge of its merged area
+			if mergeArea, found := cell.FindMergeArea(mergeAreas, col, row); found {
+				col, row = stepOver(col, row, mergeArea)
+			}
+
+			// Walking away from the label, a merged value cell counts as a single cell
+			for step := 1; step < offset+count; step++ {
+				col, row = col+colStep, row+rowStep
+				if col < 1 || row < 1 || col > excelize.MaxColumns || row > excelize.TotalRows {
+					break
+				}
+
+				targetCol, targetRow := col, row
+				if mergeArea, found := cell.FindMergeArea(mergeAreas, col, row); found {
+					targetCol, targetRow = mergeArea.StartCol, mergeArea.StartRow
+					col, row = stepOver(col, row, mergeArea)
+				}
+				if step < offset {
+					continue
+				}
+
+				cellName, err := excelize.CoordinatesToCellName(targetCol, targetRow)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, cellName)
+			}
+		}
+	}
+
+	for _, cellName := range targets {
+		// Redact the cell
+		err = a.RedactCell(cellName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
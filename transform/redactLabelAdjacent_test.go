@@ -0,0 +1,87 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRedactLabelAdjacent(t *testing.T) {
+	newFormFile := func() *excelize.File {
+		f := excelize.NewFile()
+		f.SetCellValue("Sheet1", "A1", "SSN:")
+		f.SetCellValue("Sheet1", "B1", "111-22-3333")
+		f.SetCellValue("Sheet1", "C1", "Name:")
+		// The value of the name is merged across D1:E1
+		f.SetCellValue("Sheet1", "D1", "Jane Doe")
+		f.MergeCell("Sheet1", "D1", "E1")
+		f.SetCellValue("Sheet1", "F1", "Kept")
+		f.SetCellValue("Sheet1", "A2", "Phone 1")
+		f.SetCellValue("Sheet1", "A3", "555-0100")
+		f.SetCellValue("Sheet1", "A4", "555-0199")
+		return f
+	}
+
+	testCases := []struct {
+		name     string
+		value    string
+		label    *types.LabelOptions
+		expected map[string]string
+	}{
+		{
+			name:     "right of a literal label",
+			value:    " SSN: ",
+			expected: map[string]string{"B1": "**redacted**", "D1": "Jane Doe"},
+		},
+		{
+			name:     "labels match the case by default",
+			value:    "ssn:",
+			expected: map[string]string{"B1": "111-22-3333"},
+		},
+		{
+			name:     "labels without case",
+			value:    "ssn:",
+			label:    &types.LabelOptions{CaseInsensitive: true},
+			expected: map[string]string{"B1": "**redacted**", "D1": "Jane Doe"},
+		},
+		{
+			name:     "regex labels without case",
+			value:    `^phone \d$`,
+			label:    &types.LabelOptions{Regex: true, CaseInsensitive: true, Direction: "below"},
+			expected: map[string]string{"A3": "**redacted**", "A4": "555-0199"},
+		},
+		{
+			name:     "merged value cells count as one cell",
+			value:    "Name:",
+			label:    &types.LabelOptions{Count: 2},
+			expected: map[string]string{"D1": "**redacted**", "F1": "**redacted**", "B1": "111-22-3333"},
+		},
+		{
+			name:     "below a regex label with an offset",
+			value:    `^Phone \d$`,
+			label:    &types.LabelOptions{Regex: true, Direction: "below", Offset: 2},
+			expected: map[string]string{"A3": "555-0100", "A4": "**redacted**"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFormFile()
+			defer f.Close()
+
+			action := &types.Action{ActionType: REDACT, Operation: LABEL_ADJACENT, Value: tc.value, Label: tc.label}
+			transformErr := MakeActionExecutor(f, "Sheet1", true, action, 0, 0).Execute()
+			if transformErr != nil {
+				t.Fatalf("failed to execute action: %s", transformErr.Message)
+			}
+
+			for cellName, expected := range tc.expected {
+				value, _ := f.GetCellValue("Sheet1", cellName)
+				assert.Equal(t, expected, value)
+			}
+		})
+	}
+}
@@ -11,7 +11,6 @@ import (
 func (a *ActionExecutor) RedactRange() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	rangeString := a.Action.Value
 	split := strings.Split(rangeString, ":")
 	// Checking if value is formatted properly
@@ -61,7 +60,7 @@ func (a *ActionExecutor) RedactRange() (err error) {
 			// Getting the cell column and row pair, eg: A1
 			cellName, _ := excelize.CoordinatesToCellName(startColNum, startRowNum)
 			// Redacting the cell
-			err := cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+			err := a.RedactCell(cellName)
 			if err != nil {
 				return err
 			}
@@ -12,7 +12,6 @@ import (
 func (a *ActionExecutor) RedactRow() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	row := a.Action.Value
 	/*
 		User input validation
@@ -62,7 +61,7 @@ func (a *ActionExecutor) RedactRow() (err error) {
 			return err
 		}
 		// Redact the cell
-		err = cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+		err = a.RedactCell(cellName)
 		if err != nil {
 			return err
 		}
@@ -11,7 +11,6 @@ import (
 func (a *ActionExecutor) RedactTextColor() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	colorHex := a.Action.Value
 	foundTextColor := false
 
@@ -37,7 +36,7 @@ func (a *ActionExecutor) RedactTextColor() (err error) {
 			if textColor == colorHex {
 				foundTextColor = true
 				// Redact the cell
-				err = cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+				err = a.RedactCell(cellName)
 				if err != nil {
 					return err
 				}
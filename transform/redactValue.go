@@ -1,15 +1,12 @@
 package transform
 
 import (
-	"xlsx-processor/pkg/cell"
-
 	"github.com/xuri/excelize/v2"
 )
 
 func (a *ActionExecutor) RedactValue() (err error) {
 	file := a.File
 	sheetName := a.SheetName
-	nonEmptyValueRedact := a.NonEmptyValueRedact
 	valueToRedact := a.Action.Value
 
 	cols, err := file.GetCols(sheetName)
@@ -28,7 +25,7 @@ func (a *ActionExecutor) RedactValue() (err error) {
 			// Check if the cell value is the same as the valueToRedact
 			if cellValue == valueToRedact {
 				// Redact the cell
-				err = cell.SetValue(file, sheetName, cellName, nonEmptyValueRedact, "**redacted**")
+				err = a.RedactCell(cellName)
 				if err != nil {
 					return err
 				}
@@ -0,0 +1,193 @@
+package transform
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Operation runs an action on the sheet of the executor. The executor gives the workbook, the
+// sheet, the action and the rule settings, and the cells are reported through RedactCell or
+// RecordCell so they end up in the manifest
+type Operation interface {
+	Execute(a *ActionExecutor) error
+}
+
+// OperationFunc adapts a function to the Operation interface
+type OperationFunc func(a *ActionExecutor) error
+
+// Execute calls the function
+func (f OperationFunc) Execute(a *ActionExecutor) error {
+	return f(a)
+}
+
+// CellMatcher reports whether a cell is targeted by an action
+type CellMatcher func(colNum, rowNum int, cellName, cellValue string) (bool, error)
+
+// MatcherFunc builds the matcher of the cells an action targets, the allowlist mode keeps these
+// cells instead of redacting them
+type MatcherFunc func(a *ActionExecutor) (CellMatcher, error)
+
+// registeredOperation is an operation along with the request field its errors are reported under
+// and, for the operations allowlist rules support, the matcher of the cells it targets
+type registeredOperation struct {
+	name      string
+	operation Operation
+	errorKey  string
+	matcher   MatcherFunc
+}
+
+// registry holds the operations of each action type in the order they were registered
+var registry = struct {
+	sync.RWMutex
+	actionTypes []string
+	operations  map[string][]registeredOperation
+}{operations: map[string][]registeredOperation{}}
+
+// The built-in operations, in the order the validation lists them
+func init() {
+	builtins := []struct {
+		actionType string
+		name       string
+		operation  OperationFunc
+		errorKey   string
+	}{
+		{REDACT, RANGE, (*ActionExecutor).RedactRange, "value"},
+		{REDACT, VALUE, (*ActionExecutor).RedactValue, "value"},
+		{REDACT, TEXT_COLOR, (*ActionExecutor).RedactTextColor, "value"},
+		{REDACT, BG_COLOR, (*ActionExecutor).RedactBgColor, "value"},
+		{REDACT, COLUMN, (*ActionExecutor).RedactColumn, "value"},
+		{REDACT, ROW, (*ActionExecutor).RedactRow, "value"},
+		{REDACT, DICTIONARY, (*ActionExecutor).RedactDictionary, "dictionary"},
+		{REDACT, LABEL_ADJACENT, (*ActionExecutor).RedactLabelAdjacent, "label"},
+		{REDACT, COMMENT, (*ActionExecutor).RedactComment, "value"},
+		{EXCLUDE, ROW, (*ActionExecutor).ExcludeRow, "value"},
+		{EXCLUDE, COLUMN, (*ActionExecutor).ExcludeColumn, "value"},
+		{EXCLUDE, COMMENT, (*ActionExecutor).ExcludeComment, "value"},
+		{COMPUTE, RANGE, (*ActionExecutor).Compute, "value"},
+		{COMPUTE, COLUMN, (*ActionExecutor).Compute, "value"},
+		{COMPUTE, ROW, (*ActionExecutor).Compute, "value"},
+		{SHEET, REMOVE, (*ActionExecutor).RemoveSheets, "value"},
+		{SHEET, KEEP, (*ActionExecutor).KeepSheets, "value"},
+		{SHEET, RENAME, (*ActionExecutor).RenameSheet, "value"},
+		{SHEET, MOVE, (*ActionExecutor).MoveSheet, "value"},
+		{SHEET, COPY, (*ActionExecutor).CopySheet, "value"},
+	}
+	for _, builtin := range builtins {
+		RegisterOperation(builtin.actionType, builtin.name, builtin.operation)
+		SetOperationErrorKey(builtin.actionType, builtin.name, builtin.errorKey)
+	}
+
+	// The redact operations allowlist rules support
+	matchers := map[string]MatcherFunc{
+		RANGE:      (*ActionExecutor).rangeMatcher,
+		VALUE:      (*ActionExecutor).valueMatcher,
+		TEXT_COLOR: (*ActionExecutor).textColorMatcher,
+		BG_COLOR:   (*ActionExecutor).bgColorMatcher,
+		COLUMN:     (*ActionExecutor).columnMatcher,
+		ROW:        (*ActionExecutor).rowMatcher,
+	}
+	for name, matcher := range matchers {
+		SetOperationMatcher(REDACT, name, matcher)
+	}
+}
+
+// RegisterOperation adds an operation to an action type, the action type is created by its first
+// operation. Names are compared as they are, like the built-in REDACT and RANGE. It panics when
+// the operation is already registered, as registering twice is a programming error
+func RegisterOperation(actionType, name string, operation Operation) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if operation == nil {
+		panic(fmt.Sprintf("transform: operation %s %s is nil", actionType, name))
+	}
+	operations, found := registry.operations[actionType]
+	if !found {
+		registry.actionTypes = append(registry.actionTypes, actionType)
+	}
+	for _, registered := range operations {
+		if registered.name == name {
+			panic(fmt.Sprintf("transform: operation %s %s is already registered", actionType, name))
+		}
+	}
+	registry.operations[actionType] = append(operations, registeredOperation{name: name, operation: operation, errorKey: "value"})
+}
+
+// SetOperationErrorKey changes the request field the errors of an operation are reported under,
+// value by default
+func SetOperationErrorKey(actionType, name, key string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for i, registered := range registry.operations[actionType] {
+		if registered.name == name {
+			registry.operations[actionType][i].errorKey = key
+			return
+		}
+	}
+	panic(fmt.Sprintf("transform: operation %s %s is not registered", actionType, name))
+}
+
+// SetOperationMatcher gives a redact operation the matcher of the cells it targets, allowlist rules
+// then support it and keep these cells
+func SetOperationMatcher(actionType, name string, matcher MatcherFunc) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for i, registered := range registry.operations[actionType] {
+		if registered.name == name {
+			registry.operations[actionType][i].matcher = matcher
+			return
+		}
+	}
+	panic(fmt.Sprintf("transform: operation %s %s is not registered", actionType, name))
+}
+
+// ActionTypes returns the registered action types, the built-in ones first
+func ActionTypes() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return slices.Clone(registry.actionTypes)
+}
+
+// Operations returns the operations registered for an action type
+func Operations(actionType string) []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var names []string
+	for _, registered := range registry.operations[actionType] {
+		names = append(names, registered.name)
+	}
+	return names
+}
+
+// matcherOperations returns the operations of an action type that have a matcher
+func matcherOperations(actionType string) []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var names []string
+	for _, registered := range registry.operations[actionType] {
+		if registered.matcher != nil {
+			names = append(names, registered.name)
+		}
+	}
+	return names
+}
+
+// lookupOperation returns the operation of an action, found is false for an unknown action type
+func lookupOperation(actionType, name string) (operation *registeredOperation, found bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	operations, found := registry.operations[actionType]
+	for _, registered := range operations {
+		if registered.name == name {
+			return &registered, true
+		}
+	}
+	return nil, found
+}
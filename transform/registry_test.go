@@ -0,0 +1,121 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// maskLast4 keeps the last 4 characters of the cells holding the value, a client specific operation
+func maskLast4(a *ActionExecutor) error {
+	rows, err := a.File.GetRows(a.SheetName)
+	if err != nil {
+		return err
+	}
+	for rowIndex, row := range rows {
+		for colIndex, cellValue := range row {
+			if !strings.Contains(cellValue, a.Action.Value) || len(cellValue) <= 4 {
+				continue
+			}
+			cellName, _ := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+			masked := strings.Repeat("*", len(cellValue)-4) + cellValue[len(cellValue)-4:]
+			if err := a.File.SetCellStr(a.SheetName, cellName, masked); err != nil {
+				return err
+			}
+			a.RecordCell(cellName, cellValue)
+		}
+	}
+	return nil
+}
+
+func TestRegisterOperation(t *testing.T) {
+	RegisterOperation("MASK", "LAST4", OperationFunc(maskLast4))
+	RegisterOperation(REDACT, "FAIL", OperationFunc(func(a *ActionExecutor) error {
+		return fmt.Errorf("failed")
+	}))
+	SetOperationErrorKey(REDACT, "FAIL", "custom")
+
+	t.Run("run a registered operation", func(t *testing.T) {
+		f := excelize.NewFile()
+		defer f.Close()
+		f.SetSheetCol("Sheet1", "A1", &[]string{"Card 4111111111111111", "Rent"})
+
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true},
+			Actions:       []types.Action{{Operation: "LAST4", Value: "Card", ActionType: "MASK"}},
+		}}
+		assert.Equal(t, true, ValidateRules(rules, f).Valid)
+
+		rulesExecutor := MakeRulesExecutor(f, rules)
+		if transformErr := rulesExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+		value, _ := f.GetCellValue("Sheet1", "A1")
+		assert.Equal(t, "*****************1111", value)
+		assert.Equal(t, 1, len(rulesExecutor.Manifest.RedactedCells))
+		assert.Equal(t, "Card 4111111111111111", rulesExecutor.Manifest.RedactedCells[0].Value)
+	})
+
+	t.Run("report the errors under the key of the operation", func(t *testing.T) {
+		action := &types.Action{Operation: "FAIL", Value: "x", ActionType: REDACT}
+		transformErr := MakeActionExecutor(excelize.NewFile(), "Sheet1", true, action, 0, 0).Execute()
+		assert.Equal(t, "custom", transformErr.Key)
+
+		action = &types.Action{Operation: RANGE, Value: "A1:B2", ActionType: "mask"}
+		transformErr = MakeActionExecutor(excelize.NewFile(), "Sheet1", true, action, 0, 0).Execute()
+		assert.Equal(t, "actionType", transformErr.Key)
+	})
+
+	t.Run("run the operations through the exported wrappers", func(t *testing.T) {
+		f := excelize.NewFile()
+		defer f.Close()
+		f.SetSheetCol("Sheet1", "A1", &[]string{"Jane Doe", "John Roe", "Total"})
+
+		action := &types.Action{Operation: RANGE, Value: "A1:A1"}
+		assert.Equal(t, (*types.TransformError)(nil), MakeActionExecutor(f, "Sheet1", true, action, 0, 0).ExecuteRedact())
+		action = &types.Action{Operation: ROW, Value: "2"}
+		assert.Equal(t, (*types.TransformError)(nil), MakeActionExecutor(f, "Sheet1", true, action, 0, 0).ExecuteExclude())
+
+		cols, _ := f.GetCols("Sheet1")
+		assert.Equal(t, [][]string{{"**redacted**", "Total"}}, cols)
+	})
+
+	t.Run("keep the cells of the registered matcher in allowlist rules", func(t *testing.T) {
+		SetOperationMatcher(REDACT, RANGE, func(a *ActionExecutor) (CellMatcher, error) {
+			return func(_, _ int, _, cellValue string) (bool, error) {
+				return strings.HasPrefix(cellValue, "ID-"), nil
+			}, nil
+		})
+		defer SetOperationMatcher(REDACT, RANGE, (*ActionExecutor).rangeMatcher)
+		f := excelize.NewFile()
+		defer f.Close()
+		f.SetSheetCol("Sheet1", "A1", &[]string{"ID-1", "Jane Doe", "ID-2"})
+
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", NonEmptyValueRedact: true, Mode: "allowlist"},
+			Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"}},
+		}}
+		if transformErr := MakeRulesExecutor(f, rules).Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+		cols, _ := f.GetCols("Sheet1")
+		assert.Equal(t, [][]string{{"ID-1", "**redacted**", "ID-2"}}, cols)
+	})
+
+	t.Run("list the registered operations", func(t *testing.T) {
+		assert.Equal(t, []string{REDACT, EXCLUDE, COMPUTE, SHEET, "MASK"}, ActionTypes())
+		assert.Equal(t, []string{ROW, COLUMN, COMMENT}, Operations(EXCLUDE))
+	})
+
+	t.Run("refuse a second registration", func(t *testing.T) {
+		defer func() {
+			assert.Equal(t, "transform: operation MASK LAST4 is already registered", recover())
+		}()
+		RegisterOperation("MASK", "LAST4", OperationFunc(maskLast4))
+	})
+}
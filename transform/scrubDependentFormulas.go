@@ -0,0 +1,166 @@
+package transform
+
+import (
+	"github.com/xuri/excelize/v2"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+)
+
+// formulaCell is a cell holding a formula along with the areas the formula depends on
+type formulaCell struct {
+	sheetName  string
+	cellName   string
+	col        int
+	row        int
+	references []formula.Reference
+}
+
+// redactedSource is a redacted cell that formulas must not depend on
+type redactedSource struct {
+	col         int
+	row         int
+	ruleIndex   *int
+	actionIndex *int
+}
+
+// scrubDependentFormulas clears every formula that depends on a redacted cell, directly or
+// through other formulas, and redacts the cached result it would otherwise reveal
+func (r *RulesExecutor) scrubDependentFormulas() *types.TransformError {
+	file := r.File
+	rules := *r.rules
+
+	sources := map[string][]redactedSource{}
+	for _, redactedCell := range r.Manifest.RedactedCells {
+		if redactedCell.Excluded {
+			continue
+		}
+		col, row, err := excelize.CellNameToCoordinates(redactedCell.Cell)
+		if err != nil {
+			return &types.TransformError{Message: err.Error(), RuleIndex: redactedCell.RuleIndex, Key: "includeFormulas"}
+		}
+		sources[redactedCell.SheetName] = append(sources[redactedCell.SheetName], redactedSource{col: col, row: row, ruleIndex: redactedCell.RuleIndex, actionIndex: redactedCell.ActionIndex})
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	formulaCells, err := collectFormulaCells(file)
+	if err != nil {
+		return &types.TransformError{Message: err.Error(), Key: "includeFormulas"}
+	}
+
+	dependsOn := func(fc formulaCell) (redactedSource, bool) {
+		return findRedactedSource(fc.references, sources)
+	}
+	nonEmptyValueRedact := func(source redactedSource) bool {
+		if source.ruleIndex == nil {
+			return true
+		}
+		return rules[*source.ruleIndex].PageCondition.NonEmptyValueRedact
+	}
+	scrubbed, source, err := scrubFormulas(file, formulaCells, sources, dependsOn, nonEmptyValueRedact)
+	r.Manifest.ScrubbedFormulas = append(r.Manifest.ScrubbedFormulas, scrubbed...)
+	if err != nil {
+		return &types.TransformError{Message: err.Error(), RuleIndex: source.ruleIndex, Key: "includeFormulas"}
+	}
+
+	return nil
+}
+
+// scrubFormulas clears the formulas the predicate finds depending on a source and redacts their
+// cached results. Scrubbed formulas become sources themselves so it repeats until nothing else
+// depends on them. On failure the source of the formula being scrubbed is returned
+func scrubFormulas(f *excelize.File, formulaCells []formulaCell, sources map[string][]redactedSource,
+	dependsOn func(fc formulaCell) (redactedSource, bool), nonEmptyValueRedact func(source redactedSource) bool,
+) ([]types.RedactedCell, redactedSource, error) {
+	var scrubbedFormulas []types.RedactedCell
+
+	scrubbed := make([]bool, len(formulaCells))
+	for changed := true; changed; {
+		changed = false
+		for i, fc := range formulaCells {
+			if scrubbed[i] {
+				continue
+			}
+			source, depends := dependsOn(fc)
+			if !depends {
+				continue
+			}
+
+			cachedValue, err := f.GetCellValue(fc.sheetName, fc.cellName, excelize.Options{RawCellValue: true})
+			if err != nil {
+				return scrubbedFormulas, source, err
+			}
+			if err = f.SetCellFormula(fc.sheetName, fc.cellName, ""); err != nil {
+				return scrubbedFormulas, source, err
+			}
+			err = cell.SetValue(f, fc.sheetName, fc.cellName, nonEmptyValueRedact(source), "**redacted**")
+			if err != nil {
+				return scrubbedFormulas, source, err
+			}
+
+			scrubbedFormulas = append(scrubbedFormulas, types.RedactedCell{
+				SheetName:   fc.sheetName,
+				Cell:        fc.cellName,
+				RuleIndex:   source.ruleIndex,
+				ActionIndex: source.actionIndex,
+				Value:       cachedValue,
+			})
+			sources[fc.sheetName] = append(sources[fc.sheetName], redactedSource{fc.col, fc.row, source.ruleIndex, source.actionIndex})
+			scrubbed[i] = true
+			changed = true
+		}
+	}
+
+	return scrubbedFormulas, redactedSource{}, nil
+}
+
+// findRedactedSource returns the first redacted cell inside any of the references
+func findRedactedSource(references []formula.Reference, sources map[string][]redactedSource) (redactedSource, bool) {
+	for _, reference := range references {
+		for _, source := range sources[reference.SheetName] {
+			if reference.Contains(reference.SheetName, source.col, source.row) {
+				return source, true
+			}
+		}
+	}
+	return redactedSource{}, false
+}
+
+// collectFormulaCells returns every cell of the workbook that holds a formula
+func collectFormulaCells(f *excelize.File) ([]formulaCell, error) {
+	var formulaCells []formulaCell
+
+	for _, sheetName := range f.GetSheetList() {
+		cellNames, err := sheet.FormulaCells(f, sheetName)
+		if err != nil {
+			return nil, err
+		}
+		for _, cellName := range cellNames {
+			col, row, err := excelize.CellNameToCoordinates(cellName)
+			if err != nil {
+				return nil, err
+			}
+			cellFormula, err := f.GetCellFormula(sheetName, cellName)
+			if err != nil {
+				return nil, err
+			}
+			if cellFormula == "" {
+				continue
+			}
+			formulaCells = append(formulaCells, formulaCell{
+				sheetName:  sheetName,
+				cellName:   cellName,
+				col:        col,
+				row:        row,
+				references: formula.GetReferences(f, sheetName, cellFormula),
+			})
+		}
+	}
+
+	return formulaCells, nil
+}
@@ -0,0 +1,66 @@
+package transform
+
+import (
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestScrubDependentFormulas(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if _, err := f.NewSheet("Summary"); err != nil {
+		t.Fatalf("failed to create sheet: %v", err)
+	}
+	f.SetCellValue("Sheet1", "B4", 1250)
+	f.SetCellValue("Sheet1", "B5", 300)
+	// Direct and chained dependents of B4, plus an unrelated formula. The cached
+	// results are set first since setting a value drops the formula
+	f.SetCellValue("Summary", "A1", 1250)
+	f.SetCellFormula("Summary", "A1", "Sheet1!B4")
+	f.SetCellValue("Summary", "A2", 2500)
+	f.SetCellFormula("Summary", "A2", "A1*2")
+	f.SetCellValue("Summary", "A3", 300)
+	f.SetCellFormula("Summary", "A3", "Sheet1!B5")
+	// A dependent whose cached result is empty, at the end of its row
+	f.SetCellFormula("Summary", "C2", "IF(A1>0,\"\",A1)")
+
+	rules := []types.Rule{
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, NonEmptyValueRedact: true},
+			Actions: []types.Action{
+				{ActionType: REDACT, Operation: VALUE, Value: "9999"},
+				{ActionType: REDACT, Operation: VALUE, Value: "1250"},
+			},
+		},
+	}
+	rulesExecutor := MakeRulesExecutor(f, rules)
+	transformErr := rulesExecutor.Execute()
+	if transformErr != nil {
+		t.Fatalf("failed to execute rules: %s", transformErr.Message)
+	}
+
+	for _, cellName := range []string{"A1", "A2"} {
+		formula, _ := f.GetCellFormula("Summary", cellName)
+		assert.Equal(t, "", formula)
+		value, _ := f.GetCellValue("Summary", cellName)
+		assert.Equal(t, "**redacted**", value)
+	}
+	formula, _ := f.GetCellFormula("Summary", "A3")
+	assert.Equal(t, "Sheet1!B5", formula)
+
+	formula, _ = f.GetCellFormula("Summary", "C2")
+	assert.Equal(t, "", formula)
+
+	// The scrubbed formulas are attributed to the action that redacted their source
+	var scrubbed []string
+	for _, scrubbedFormula := range rulesExecutor.Manifest.ScrubbedFormulas {
+		scrubbed = append(scrubbed, scrubbedFormula.Cell)
+		assert.Equal(t, 0, *scrubbedFormula.RuleIndex)
+		assert.Equal(t, 1, *scrubbedFormula.ActionIndex)
+	}
+	assert.Equal(t, []string{"A1", "A2", "C2"}, scrubbed)
+}
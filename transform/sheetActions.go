@@ -0,0 +1,239 @@
+package transform
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/file"
+	"xlsx-processor/pkg/formula"
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// isSheetRule reports whether every action of a rule works on whole sheets, such rules leave
+// the formulas of their sheet as they are so a copy keeps them
+func isSheetRule(rule types.Rule) bool {
+	for _, action := range rule.Actions {
+		if action.ActionType != SHEET {
+			return false
+		}
+	}
+	return len(rule.Actions) > 0
+}
+
+// sheetPatterns reads the comma separated sheet names of a remove or keep action, the names can
+// use the wildcards * and ?, eg: Internal*, Notes
+func sheetPatterns(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid pattern", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("'%s' is invalid, expected sheet names such as Internal*, Notes", value)
+	}
+	return patterns, nil
+}
+
+// matchesSheet compares the sheet names without case, as Excel does
+func matchesSheet(patterns []string, sheetName string) bool {
+	for _, pattern := range patterns {
+		if matches, _ := path.Match(strings.ToLower(pattern), strings.ToLower(sheetName)); matches {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSheets removes the sheets matching the value of the action
+func (a *ActionExecutor) RemoveSheets() error {
+	return a.removeMatchingSheets(true)
+}
+
+// KeepSheets removes the sheets that do not match the value of the action
+func (a *ActionExecutor) KeepSheets() error {
+	return a.removeMatchingSheets(false)
+}
+
+func (a *ActionExecutor) removeMatchingSheets(matching bool) error {
+	patterns, err := sheetPatterns(a.Action.Value)
+	if err != nil {
+		return err
+	}
+	var removed []string
+	for _, sheetName := range a.File.GetSheetList() {
+		if matchesSheet(patterns, sheetName) == matching {
+			removed = append(removed, sheetName)
+		}
+	}
+	return a.removeSheets(removed)
+}
+
+// removeSheets deletes the sheets, see DeleteSheets
+func (a *ActionExecutor) removeSheets(sheetNames []string) error {
+	if len(sheetNames) == len(a.File.GetSheetList()) {
+		return fmt.Errorf("the action would remove every sheet, a workbook needs at least one")
+	}
+	removedSource := redactedSource{ruleIndex: &a.RuleIndex, actionIndex: &a.ActionIndex}
+	return deleteSheets(a.File, a.Manifest, sheetNames, removedSource, a.NonEmptyValueRedact)
+}
+
+// DeleteSheets deletes sheets along with the defined names referring to them. The formulas of
+// the other sheets referring to them, directly or through other formulas, are cleared and their
+// cached results redacted, as they would otherwise show what the removed sheets held
+func DeleteSheets(f *excelize.File, manifest *types.Manifest, sheetNames []string) error {
+	return deleteSheets(f, manifest, sheetNames, redactedSource{}, true)
+}
+
+func deleteSheets(f *excelize.File, manifest *types.Manifest, sheetNames []string, removedSource redactedSource, nonEmptyValueRedact bool) error {
+	if len(sheetNames) == 0 {
+		return nil
+	}
+	removed := map[string]bool{}
+	for _, sheetName := range sheetNames {
+		removed[strings.ToLower(sheetName)] = true
+	}
+
+	formulaCells, err := collectFormulaCells(f)
+	if err != nil {
+		return err
+	}
+	sources := map[string][]redactedSource{}
+	dependsOn := func(fc formulaCell) (redactedSource, bool) {
+		if removed[strings.ToLower(fc.sheetName)] {
+			return redactedSource{}, false
+		}
+		for _, reference := range fc.references {
+			if removed[strings.ToLower(reference.SheetName)] {
+				return removedSource, true
+			}
+		}
+		return findRedactedSource(fc.references, sources)
+	}
+	scrubbed, _, err := scrubFormulas(f, formulaCells, sources, dependsOn, func(redactedSource) bool {
+		return nonEmptyValueRedact
+	})
+	if manifest != nil {
+		manifest.ScrubbedFormulas = append(manifest.ScrubbedFormulas, scrubbed...)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The defined names of the removed sheets are deleted with them, the others must not refer to them
+	err = sheet.RewriteDefinedNames(f, func(refersTo string) (string, bool) {
+		for _, reference := range formula.GetReferences(f, "", refersTo) {
+			if removed[strings.ToLower(reference.SheetName)] {
+				return refersTo, false
+			}
+		}
+		return refersTo, true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sheetName := range sheetNames {
+		if err = f.DeleteSheet(sheetName); err != nil {
+			return err
+		}
+		ExcludeRedactedSheet(manifest, sheetName)
+	}
+	return nil
+}
+
+// checkNewSheetName checks the name of a renamed or copied sheet, Excel compares sheet names
+// without case
+func (a *ActionExecutor) checkNewSheetName(newName string) error {
+	if err := sheet.ValidateName(newName); err != nil {
+		return err
+	}
+	for _, sheetName := range a.File.GetSheetList() {
+		if strings.EqualFold(sheetName, newName) {
+			return fmt.Errorf("a sheet named '%s' already exists", sheetName)
+		}
+	}
+	return nil
+}
+
+// RenameSheet renames the sheet of the rule, the formulas, defined names, charts and pivot
+// sources referring to it follow. The later actions of the rule apply to the renamed sheet
+func (a *ActionExecutor) RenameSheet() error {
+	newName := a.Action.Value
+	if newName == a.SheetName {
+		return nil
+	}
+	if err := a.checkNewSheetName(newName); err != nil {
+		return err
+	}
+
+	// excelize drops the quotes of every defined name and misses the charts and pivot sources
+	// while renaming a sheet, so the package is renamed textually and the workbook reopened
+	buffer, err := a.File.WriteToBuffer()
+	if err != nil {
+		return err
+	}
+	p, err := ooxml.ReadPackage(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	if err = sheet.Rename(p, map[string]string{a.SheetName: newName}); err != nil {
+		return err
+	}
+	fileContents, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+	f, err := file.InitFileFromBytes(fileContents)
+	if err != nil {
+		return err
+	}
+	a.File.Close()
+	a.File = f
+
+	renameRedactedSheet(a.Manifest, a.SheetName, newName)
+	a.SheetName = newName
+	return nil
+}
+
+// MoveSheet moves the sheet of the rule to the position of the value, starting from 1
+func (a *ActionExecutor) MoveSheet() error {
+	sheetCount := len(a.File.GetSheetList())
+	position, err := strconv.Atoi(a.Action.Value)
+	if err != nil || position < 1 || position > sheetCount {
+		return fmt.Errorf("'%s' is not a valid position, expected 1 to %d", a.Action.Value, sheetCount)
+	}
+	return sheet.Move(a.File, a.SheetName, position-1)
+}
+
+// CopySheet copies the sheet of the rule to a new sheet named by the value, placed right after
+// it. Like excelize, the pictures, tables and page setup are not copied
+func (a *ActionExecutor) CopySheet() error {
+	f := a.File
+	copyName := a.Action.Value
+	if err := a.checkNewSheetName(copyName); err != nil {
+		return err
+	}
+
+	copyIndex, err := f.NewSheet(copyName)
+	if err != nil {
+		return err
+	}
+	sheetIndex := slices.Index(f.GetSheetList(), a.SheetName)
+	if err = f.CopySheet(sheetIndex, copyIndex); err != nil {
+		return err
+	}
+	return sheet.Move(f, copyName, sheetIndex+1)
+}
@@ -0,0 +1,224 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"xlsx-processor/pkg/ooxml"
+	"xlsx-processor/pkg/types"
+	"xlsx-processor/pkg/verify"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSheetActions(t *testing.T) {
+	newFile := func() *excelize.File {
+		f := excelize.NewFile()
+		f.SetSheetName("Sheet1", "Summary")
+		f.NewSheet("Internal")
+		f.NewSheet("Data")
+		f.SetCellValue("Internal", "A1", "margin")
+		f.SetSheetCol("Data", "A1", &[]string{"Jane Doe", "John Roe"})
+		f.SetCellFormula("Summary", "A1", "Internal!A1")
+		f.SetCellFormula("Summary", "A2", "A1*2")
+		f.SetCellFormula("Summary", "A3", "Data!A1")
+		f.SetDefinedName(&excelize.DefinedName{Name: "Margin", RefersTo: "Internal!$A$1"})
+		f.SetDefinedName(&excelize.DefinedName{Name: "Customer", RefersTo: "Data!$A$1"})
+		return f
+	}
+	sheetRule := func(sheetName string, actions ...types.Action) types.Rule {
+		return types.Rule{PageCondition: types.PageCondition{SheetName: sheetName}, Actions: actions}
+	}
+	execute := func(t *testing.T, f *excelize.File, rules ...types.Rule) *RulesExecutor {
+		rulesExecutor := MakeRulesExecutor(f, rules)
+		if transformErr := rulesExecutor.Execute(); transformErr != nil {
+			t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+		}
+		return rulesExecutor
+	}
+	definedNames := func(f *excelize.File) map[string]string {
+		names := map[string]string{}
+		for _, definedName := range f.GetDefinedName() {
+			names[definedName.Name] = definedName.RefersTo
+		}
+		return names
+	}
+
+	t.Run("remove sheets along with the names and formulas referring to them", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rulesExecutor := execute(t, f, sheetRule("Summary", types.Action{ActionType: SHEET, Operation: REMOVE, Value: "intern*, Notes"}))
+
+		assert.Equal(t, []string{"Summary", "Data"}, f.GetSheetList())
+		assert.Equal(t, map[string]string{"Customer": "Data!$A$1"}, definedNames(f))
+		for cellName, expected := range map[string]string{"A1": "", "A2": "", "A3": "Data!A1"} {
+			cellFormula, _ := f.GetCellFormula("Summary", cellName)
+			assert.Equal(t, expected, cellFormula)
+		}
+		var scrubbed []string
+		for _, scrubbedFormula := range rulesExecutor.Manifest.ScrubbedFormulas {
+			scrubbed = append(scrubbed, scrubbedFormula.SheetName+"!"+scrubbedFormula.Cell)
+		}
+		assert.Equal(t, []string{"Summary!A1", "Summary!A2"}, scrubbed)
+		assert.Equal(t, []types.Exclusion{{SheetName: "Internal"}}, rulesExecutor.Manifest.Exclusions)
+	})
+
+	t.Run("keep only the listed sheets", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		execute(t, f, sheetRule("Summary", types.Action{ActionType: SHEET, Operation: KEEP, Value: "summary,Data"}))
+
+		assert.Equal(t, []string{"Summary", "Data"}, f.GetSheetList())
+	})
+
+	t.Run("refuse to remove every sheet", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rulesExecutor := MakeRulesExecutor(f, []types.Rule{sheetRule("Summary", types.Action{ActionType: SHEET, Operation: REMOVE, Value: "*"})})
+		transformErr := rulesExecutor.Execute()
+
+		assert.Equal(t, "the action would remove every sheet, a workbook needs at least one", transformErr.Message)
+		assert.Equal(t, "value", transformErr.Key)
+		assert.Equal(t, 3, len(f.GetSheetList()))
+	})
+
+	t.Run("stop the actions of a rule once its sheet is removed", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		execute(t, f, sheetRule("Data",
+			types.Action{ActionType: SHEET, Operation: REMOVE, Value: "Data"},
+			types.Action{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"},
+		))
+
+		assert.Equal(t, []string{"Summary", "Internal"}, f.GetSheetList())
+	})
+
+	t.Run("rename a sheet along with the formulas, names and later actions", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		f.NewSheet("Customer Data")
+		f.SetDefinedName(&excelize.DefinedName{Name: "Totals", RefersTo: "'Customer Data'!$A$1"})
+		for i, definedName := range f.WorkBook.DefinedNames.DefinedName {
+			f.WorkBook.DefinedNames.DefinedName[i].Hidden = definedName.Name == "Margin"
+		}
+		rulesExecutor := execute(t, f, sheetRule("Data",
+			types.Action{ActionType: SHEET, Operation: RENAME, Value: "Customer List"},
+			types.Action{ActionType: REDACT, Operation: RANGE, Value: "A2:A2"},
+		))
+		renamed := rulesExecutor.File
+		defer renamed.Close()
+
+		assert.Equal(t, []string{"Summary", "Internal", "Customer List", "Customer Data"}, renamed.GetSheetList())
+		cellFormula, _ := renamed.GetCellFormula("Summary", "A3")
+		assert.Equal(t, "'Customer List'!A1", cellFormula)
+		// The other names keep their quotes and attributes
+		assert.Equal(t, map[string]string{
+			"Margin":   "Internal!$A$1",
+			"Customer": "'Customer List'!$A$1",
+			"Totals":   "'Customer Data'!$A$1",
+		}, definedNames(renamed))
+		workbook := string(ooxml.ReadFilePart(renamed, "xl/workbook.xml"))
+		assert.Equal(t, true, strings.Contains(workbook, `<definedName hidden="true" name="Margin">`))
+		assert.Equal(t, "Customer List", rulesExecutor.Manifest.RedactedCells[0].SheetName)
+		cellValue, _ := renamed.GetCellValue("Customer List", "A2")
+		assert.Equal(t, "", cellValue)
+	})
+
+	t.Run("refuse a name already taken", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		rulesExecutor := MakeRulesExecutor(f, []types.Rule{sheetRule("Data", types.Action{ActionType: SHEET, Operation: RENAME, Value: "summary"})})
+
+		assert.Equal(t, "a sheet named 'Summary' already exists", rulesExecutor.Execute().Message)
+	})
+
+	t.Run("move a sheet along with its sheet scoped names", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		f.SetDefinedName(&excelize.DefinedName{Name: "Total", RefersTo: "Data!$A$1", Scope: "Data"})
+		f.SetActiveSheet(1)
+		execute(t, f, sheetRule("Data", types.Action{ActionType: SHEET, Operation: MOVE, Value: "1"}))
+
+		buffer, _ := f.WriteToBuffer()
+		reopened, err := excelize.OpenReader(buffer)
+		if err != nil {
+			t.Fatalf("failed to reopen the workbook: %v", err)
+		}
+		defer reopened.Close()
+		assert.Equal(t, []string{"Data", "Summary", "Internal"}, reopened.GetSheetList())
+		assert.Equal(t, "Internal", reopened.GetSheetName(reopened.GetActiveSheetIndex()))
+		for _, definedName := range reopened.GetDefinedName() {
+			if definedName.Name == "Total" {
+				assert.Equal(t, "Data", definedName.Scope)
+			}
+		}
+	})
+
+	t.Run("copy a sheet before it is redacted", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		f.SetCellFormula("Data", "B1", "UPPER(A1)")
+		copyRule := sheetRule("Data", types.Action{ActionType: SHEET, Operation: COPY, Value: "Data (raw)"})
+		copyRule.Priority = 1
+		redactRule := sheetRule("Data", types.Action{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"})
+		execute(t, f, redactRule, copyRule)
+
+		assert.Equal(t, []string{"Summary", "Internal", "Data", "Data (raw)"}, f.GetSheetList())
+		rawValue, _ := f.GetCellValue("Data (raw)", "A1")
+		assert.Equal(t, "Jane Doe", rawValue)
+		rawFormula, _ := f.GetCellFormula("Data (raw)", "B1")
+		assert.Equal(t, "UPPER(A1)", rawFormula)
+		redactedValue, _ := f.GetCellValue("Data", "A1")
+		assert.Equal(t, "", redactedValue)
+	})
+
+	t.Run("keep the copy out of the redaction verification", func(t *testing.T) {
+		f := newFile()
+		defer f.Close()
+		copyRule := sheetRule("Data", types.Action{ActionType: SHEET, Operation: COPY, Value: "Raw"})
+		copyRule.Priority = 1
+		redactRule := sheetRule("Data", types.Action{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"})
+		rulesExecutor := execute(t, f, redactRule, copyRule)
+
+		buffer, err := f.WriteToBuffer()
+		if err != nil {
+			t.Fatalf("failed to write the file: %v", err)
+		}
+		p, err := ooxml.ReadPackage(buffer.Bytes())
+		if err != nil {
+			t.Fatalf("failed to read the package: %v", err)
+		}
+		redactedCells := append(rulesExecutor.Manifest.RedactedCells, rulesExecutor.Manifest.ScrubbedFormulas...)
+		hits, err := verify.CheckTraces(f, p, redactedCells, verify.ValueMatcher)
+		if err != nil {
+			t.Fatalf("failed to check the traces: %v", err)
+		}
+
+		// The copy holds the original on purpose, it is not a trace of the redacted cell
+		assert.Equal(t, 0, len(hits))
+		rawValue, _ := f.GetCellValue("Raw", "A1")
+		assert.Equal(t, "Jane Doe", rawValue)
+	})
+
+	t.Run("validate the sheet actions", func(t *testing.T) {
+		validation := ValidateRules([]types.Rule{sheetRule("Data",
+			types.Action{ActionType: SHEET, Operation: REMOVE, Value: "Notes[, Internal"},
+			types.Action{ActionType: SHEET, Operation: RENAME, Value: "Q1/Q2"},
+			types.Action{ActionType: SHEET, Operation: MOVE, Value: "0"},
+			types.Action{ActionType: SHEET, Operation: COPY, Value: "Data (raw)"},
+			types.Action{ActionType: SHEET, Operation: "DELETE", Value: "Data"},
+		)}, nil)
+
+		var messages []string
+		for _, problem := range validation.Errors {
+			messages = append(messages, problem.Message)
+		}
+		assert.Equal(t, []string{
+			"'Notes[' is not a valid pattern",
+			"'Q1/Q2' is not a valid sheet name",
+			"'0' is not a valid position, expected a number from 1",
+			"Invalid operation 'DELETE', expected one of REMOVE, KEEP, RENAME, MOVE, COPY",
+		}, messages)
+	})
+}
@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"slices"
+
+	"xlsx-processor/pkg/formula"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// staleFormulas returns whether a formula of the sheet depends on a cell the run changed, or on a
+// sheet that lost rows or columns, its cached value is then out of date
+func (r *RulesExecutor) staleFormulas(sheetName string) func(cellName, cellFormula string) bool {
+	changed := map[string][][2]int{}
+	for _, changedCell := range slices.Concat(r.Manifest.RedactedCells, r.Manifest.ScrubbedFormulas, r.Manifest.ComputedCells) {
+		if changedCell.Excluded {
+			continue
+		}
+		col, row, err := excelize.CellNameToCoordinates(changedCell.Cell)
+		if err != nil {
+			continue
+		}
+		changed[changedCell.SheetName] = append(changed[changedCell.SheetName], [2]int{col, row})
+	}
+	// Removing rows or columns shrinks the ranges the formulas refer to
+	shrunk := map[string]bool{}
+	for _, exclusion := range r.Manifest.Exclusions {
+		if exclusion.Row > 0 || exclusion.Column > 0 {
+			shrunk[exclusion.SheetName] = true
+		}
+	}
+
+	return func(cellName, cellFormula string) bool {
+		for _, reference := range formula.GetReferences(r.File, sheetName, cellFormula) {
+			if shrunk[reference.SheetName] {
+				return true
+			}
+			for _, position := range changed[reference.SheetName] {
+				if reference.Contains(reference.SheetName, position[0], position[1]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
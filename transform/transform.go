@@ -3,6 +3,7 @@ package transform
 import (
 	"fmt"
 	"slices"
+	"strings"
 	"xlsx-processor/pkg/sheet"
 	"xlsx-processor/pkg/types"
 
@@ -13,61 +14,264 @@ import (
 const (
 	REDACT    string = "REDACT"
 	EXCLUDE   string = "EXCLUDE"
+	COMPUTE   string = "COMPUTE"
+	SHEET     string = "SHEET"
 	VALUE     string = "VALUE"
 	RANGE     string = "RANGE"
 	TEXT_COLOR string = "TEXT_COLOR"
 	BG_COLOR   string = "BG_COLOR"
 	COLUMN    string = "COLUMN"
 	ROW       string = "ROW"
+	DICTIONARY string = "DICTIONARY"
+	LABEL_ADJACENT string = "LABEL_ADJACENT"
+	COMMENT   string = "COMMENT"
+	REMOVE    string = "REMOVE"
+	RENAME    string = "RENAME"
+	MOVE      string = "MOVE"
+	COPY      string = "COPY"
+)
+
+// Constants for the comments removed by the exclude comment operation
+const (
+	ALL      string = "ALL"
+	REDACTED string = "REDACTED"
+)
+
+// Constants for the directions of the label adjacent operation
+const (
+	RIGHT string = "RIGHT"
+	BELOW string = "BELOW"
+	LEFT  string = "LEFT"
+	ABOVE string = "ABOVE"
+)
+
+// Constants for the page condition modes
+const (
+	BLOCKLIST string = "BLOCKLIST"
+	ALLOWLIST string = "ALLOWLIST"
+)
+
+// Constants for the merge policies
+const (
+	EXPAND  string = "EXPAND"
+	UNMERGE string = "UNMERGE"
+)
+
+// Constants for the formula modes, KEEP is also the sheet operation keeping the listed sheets
+const (
+	KEEP    string = "KEEP"
+	CLEAR   string = "CLEAR"
+	FLATTEN string = "FLATTEN"
 )
 
 type RulesExecutor struct {
 	File *excelize.File
 	rules *[]types.Rule
+	// Manifest records every cell redacted while executing the rules
+	Manifest *types.Manifest
+	// RemoveExcludedPictures deletes the pictures anchored in the excluded rows and columns,
+	// otherwise excelize moves them to the neighbouring cells
+	RemoveExcludedPictures bool
+	// FileName is matched by the file name conditions of the rules
+	FileName string
 }
 
 func MakeRulesExecutor(file *excelize.File, rules []types.Rule) *RulesExecutor {
 	return &RulesExecutor{
 		File: file,
 		rules: &rules,
+		Manifest: &types.Manifest{},
 	}
 }
 
+// getFormulaMode returns the formula mode of a rule and the key to report errors under.
+// Rules without a formula mode fall back to includeFormulas: keep when true, clear when false
+func getFormulaMode(pageCondition types.PageCondition) (formulaMode string, key string) {
+	if pageCondition.FormulaMode != "" {
+		return strings.ToUpper(pageCondition.FormulaMode), "formulaMode"
+	}
+	if pageCondition.IncludeFormulas {
+		return KEEP, "includeFormulas"
+	}
+	return CLEAR, "includeFormulas"
+}
+
+// checkModes checks the formula mode, the mode and the merge policy of a rule
+func checkModes(ruleIndex int, rule types.Rule) *types.TransformError {
+	formulaMode, key := getFormulaMode(rule.PageCondition)
+	if formulaMode != KEEP && formulaMode != CLEAR && formulaMode != FLATTEN {
+		return &types.TransformError{Message: "Invalid formula mode", RuleIndex: &ruleIndex, Key: key}
+	}
+
+	mode := strings.ToUpper(rule.PageCondition.Mode)
+	if mode != "" && mode != BLOCKLIST && mode != ALLOWLIST {
+		return &types.TransformError{Message: "Invalid mode", RuleIndex: &ruleIndex, Key: "mode"}
+	}
+
+	mergePolicy := strings.ToUpper(rule.PageCondition.MergePolicy)
+	if mergePolicy != "" && mergePolicy != EXPAND && mergePolicy != UNMERGE {
+		return &types.TransformError{Message: "Invalid merge policy", RuleIndex: &ruleIndex, Key: "mergePolicy"}
+	}
+	return nil
+}
+
 func (r *RulesExecutor) Execute() *types.TransformError {
 	file := r.File
 	rules := r.rules
 
+	// Checking the modes and the conditions on the workbook as received, before any rule changes it
+	skipReasons := make([]string, len(*rules))
 	for ruleIndex, rule := range *rules {
+		if transformErr := checkModes(ruleIndex, rule); transformErr != nil {
+			return transformErr
+		}
+		reason, transformErr := r.checkConditions(ruleIndex, rule)
+		if transformErr != nil {
+			return transformErr
+		}
+		skipReasons[ruleIndex] = reason
+	}
+
+	// The rule that stopped the later rules of each sheet
+	stoppedBy := map[string]int{}
+	// The sheets whose formulas are flattened once every rule ran
+	var flattenedSheets []flattenedSheet
+
+	for _, ruleIndex := range ruleOrder(*rules) {
+		rule := (*rules)[ruleIndex]
 		var sheetName string = rule.PageCondition.SheetName
 		var nonEmptyValueRedact bool = rule.PageCondition.NonEmptyValueRedact
 
 		doesSheetNameExist := slices.Contains(file.GetSheetList(), sheetName)
 		if !doesSheetNameExist {
 			fmt.Println("Sheet name does not exist, skipping rule", sheetName)
-			return nil
+			r.skipRule(ruleIndex, fmt.Sprintf("sheet '%s' does not exist", sheetName))
+			continue
+		}
+		if stopper, stopped := stoppedBy[sheetName]; stopped {
+			r.skipRule(ruleIndex, fmt.Sprintf("stopped by rule %d", stopper))
+			continue
+		}
+		if skipReasons[ruleIndex] != "" {
+			r.skipRule(ruleIndex, skipReasons[ruleIndex])
+			continue
+		}
+		if rule.StopOnMatch {
+			stoppedBy[sheetName] = ruleIndex
 		}
 
-		// Removing formulas if the rule is set to not include them
-		if !rule.PageCondition.IncludeFormulas {
-			err := sheet.ClearFormulas(file, sheetName)
-			if err != nil {
-				return &types.TransformError{
-					Message: err.Error(),
-					RuleIndex: &ruleIndex,
-					Key: "includeFormulas",
-				}
+		// Clearing or flattening the formulas unless the rule keeps them, or only works on whole sheets
+		formulaMode, key := getFormulaMode(rule.PageCondition)
+		var err error
+		switch {
+		case isSheetRule(rule):
+		case formulaMode == KEEP:
+		case formulaMode == CLEAR:
+			err = sheet.ClearFormulas(file, sheetName)
+		case formulaMode == FLATTEN:
+			flattenedSheets = append(flattenedSheets, flattenedSheet{sheetName, ruleIndex, key})
+		}
+		if err != nil {
+			return &types.TransformError{
+				Message: err.Error(),
+				RuleIndex: &ruleIndex,
+				Key: key,
 			}
 		}
 
+		mode := strings.ToUpper(rule.PageCondition.Mode)
+		mergePolicy := strings.ToUpper(rule.PageCondition.MergePolicy)
+
+		// Allowlist rules keep what the actions target and remove everything else
+		if mode == ALLOWLIST {
+			allowlistExecutor := MakeAllowlistExecutor(file, sheetName, nonEmptyValueRedact, rule.Actions, ruleIndex)
+			allowlistExecutor.Manifest = r.Manifest
+			allowlistExecutor.RemoveExcludedPictures = r.RemoveExcludedPictures
+			allowlistExecutor.MergePolicy = mergePolicy
+			transformErr := allowlistExecutor.Execute()
+			if transformErr != nil {
+				return transformErr
+			}
+			continue
+		}
+
 		for actionIndex, action := range rule.Actions {
 			// Initialize the operations
 			actionExecutor := MakeActionExecutor(file, sheetName, nonEmptyValueRedact, &action, actionIndex, ruleIndex)
+			actionExecutor.Manifest = r.Manifest
+			actionExecutor.MergePolicy = mergePolicy
+			actionExecutor.RemoveExcludedPictures = r.RemoveExcludedPictures
 			// Execute the action
 			transformErr := actionExecutor.Execute()
 			if transformErr != nil {
 				return transformErr
 			}
+
+			// Sheet actions can rename or remove the sheet of the rule, renaming reopens the workbook
+			file = actionExecutor.File
+			r.File = file
+			if renamed := actionExecutor.SheetName; renamed != sheetName {
+				if stopper, stopped := stoppedBy[sheetName]; stopped {
+					delete(stoppedBy, sheetName)
+					stoppedBy[renamed] = stopper
+				}
+				for i := range flattenedSheets {
+					if flattenedSheets[i].sheetName == sheetName {
+						flattenedSheets[i].sheetName = renamed
+					}
+				}
+				sheetName = renamed
+			}
+			if !slices.Contains(file.GetSheetList(), sheetName) {
+				break
+			}
 		}
 	}
+
+	// Redacting the copies of redacted values on every other sheet
+	transformErr := r.redactAcrossSheets()
+	if transformErr != nil {
+		return transformErr
+	}
+
+	// Clearing the formulas that would reveal the redacted cells through their results
+	transformErr = r.scrubDependentFormulas()
+	if transformErr != nil {
+		return transformErr
+	}
+
+	// Flattening last, the formulas depending on redacted cells are already scrubbed instead
+	// of turned into the values they would reveal
+	for _, flattened := range flattenedSheets {
+		if !slices.Contains(r.File.GetSheetList(), flattened.sheetName) {
+			continue
+		}
+		ruleIndex := flattened.ruleIndex
+		failed, err := sheet.FlattenFormulasWhere(r.File, flattened.sheetName, sheet.FlattenOptions{IsStale: r.staleFormulas(flattened.sheetName)})
+		if err != nil {
+			return &types.TransformError{
+				Message:   err.Error(),
+				RuleIndex: &ruleIndex,
+				Key:       flattened.key,
+			}
+		}
+		// The formulas failing to calculate keep their formula rather than lose their value
+		for _, cellName := range failed {
+			r.Manifest.UnflattenedFormulas = append(r.Manifest.UnflattenedFormulas, types.RedactedCell{
+				SheetName: flattened.sheetName,
+				Cell:      cellName,
+				RuleIndex: &ruleIndex,
+			})
+		}
+	}
+
 	return nil
 }
+
+// flattenedSheet is a sheet whose formulas a rule flattens, along with the key to report
+// errors under
+type flattenedSheet struct {
+	sheetName string
+	ruleIndex int
+	key       string
+}
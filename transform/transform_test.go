@@ -1,8 +1,11 @@
 package transform
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
+	"xlsx-processor/pkg/ooxml"
 	"xlsx-processor/pkg/types"
 
 	"github.com/go-playground/assert/v2"
@@ -13,12 +16,14 @@ type formulaTestCase struct {
 	name            string
 	inputFile       string
 	includeFormulas bool
+	formulaMode     string
 	expectedFormula string
+	expectedValue   string
 	sheetName       string
 	cellReference   string
 }
 
-func executeFormulaRule(filePath string, includeFormulas bool, sheetName string) (file *excelize.File, err error) {
+func executeFormulaRule(filePath string, includeFormulas bool, formulaMode string, sheetName string) (file *excelize.File, err error) {
 	file, err = excelize.OpenFile(filePath)
 	if err != nil {
 		return nil, err
@@ -30,6 +35,7 @@ func executeFormulaRule(filePath string, includeFormulas bool, sheetName string)
 			PageCondition: types.PageCondition{
 				SheetName:       sheetName,
 				IncludeFormulas: includeFormulas,
+				FormulaMode:     formulaMode,
 			},
 			Actions: []types.Action{},
 		},
@@ -47,7 +53,7 @@ func executeFormulaRule(filePath string, includeFormulas bool, sheetName string)
 func runFormulaTestCase(t *testing.T, tc formulaTestCase) {
 	t.Helper()
 
-	file, err := executeFormulaRule(tc.inputFile, tc.includeFormulas, tc.sheetName)
+	file, err := executeFormulaRule(tc.inputFile, tc.includeFormulas, tc.formulaMode, tc.sheetName)
 	if err != nil {
 		t.Fatalf("failed to execute formula rule: %v", err)
 	}
@@ -57,6 +63,14 @@ func runFormulaTestCase(t *testing.T, tc formulaTestCase) {
 		t.Fatalf("failed to get cell formula: %v", err)
 	}
 	assert.Equal(t, formulas, tc.expectedFormula)
+
+	if tc.expectedValue != "" {
+		value, err := file.GetCellValue(tc.sheetName, tc.cellReference)
+		if err != nil {
+			t.Fatalf("failed to get cell value: %v", err)
+		}
+		assert.Equal(t, value, tc.expectedValue)
+	}
 }
 
 func TestCheckFormulas(t *testing.T) {
@@ -77,6 +91,25 @@ func TestCheckFormulas(t *testing.T) {
 			sheetName:       "Sheet1",
 			cellReference:   "E2",
 		},
+		{
+			name:            "check formula mode overrides include formulas",
+			inputFile:       "../assets/goldenFiles/testCheckFormulas.xlsx",
+			includeFormulas: false,
+			formulaMode:     "keep",
+			expectedFormula: "A2+B2",
+			sheetName:       "Sheet1",
+			cellReference:   "E2",
+		},
+		{
+			name:            "check formulas are flattened",
+			inputFile:       "../assets/goldenFiles/testCheckFormulas.xlsx",
+			includeFormulas: true,
+			formulaMode:     "flatten",
+			expectedFormula: "",
+			expectedValue:   "3",
+			sheetName:       "Sheet1",
+			cellReference:   "E2",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -85,3 +118,151 @@ func TestCheckFormulas(t *testing.T) {
 		})
 	}
 }
+
+func TestFlattenFormulas(t *testing.T) {
+	source := excelize.NewFile()
+	source.SetCellValue("Sheet1", "A1", 1250)
+	// The cached results are set first since setting a value drops the formula
+	source.SetCellValue("Sheet1", "B1", 2500)
+	source.SetCellFormula("Sheet1", "B1", "A1*2")
+	source.SetCellFormula("Sheet1", "C1", "\"0012\"")
+	source.SetCellFormula("Sheet1", "D1", "1+2")
+	buffer, _ := source.WriteToBuffer()
+	source.Close()
+
+	// excelize writes no cached result, the text Excel would cache is added
+	p, _ := ooxml.ReadPackage(buffer.Bytes())
+	sheetXML, _ := p.Part("xl/worksheets/sheet1.xml")
+	p.SetPart("xl/worksheets/sheet1.xml", []byte(strings.Replace(string(sheetXML),
+		`<f>&#34;0012&#34;</f></c>`, `<f>&#34;0012&#34;</f><v>0012</v></c>`, 1)))
+	contents, _ := p.Bytes()
+	f, err := excelize.OpenReader(bytes.NewReader(contents))
+	if err != nil {
+		t.Fatalf("failed to open the workbook: %v", err)
+	}
+	defer f.Close()
+
+	rules := []types.Rule{{
+		PageCondition: types.PageCondition{SheetName: "Sheet1", FormulaMode: "flatten", NonEmptyValueRedact: true},
+		Actions:       []types.Action{{ActionType: REDACT, Operation: VALUE, Value: "1250"}},
+	}}
+	if transformErr := MakeRulesExecutor(f, rules).Execute(); transformErr != nil {
+		t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+	}
+
+	// The dependent of the redacted cell is scrubbed rather than flattened to what it revealed,
+	// the cached text stays a text and the formula without a cached value is calculated
+	for cellName, expected := range map[string]string{"B1": "**redacted**", "C1": "0012", "D1": "3"} {
+		formula, _ := f.GetCellFormula("Sheet1", cellName)
+		assert.Equal(t, "", formula)
+		value, _ := f.GetCellValue("Sheet1", cellName)
+		assert.Equal(t, expected, value)
+	}
+	// excelize writes numbers without a type, unlike texts
+	for cellName, expected := range map[string]excelize.CellType{"C1": excelize.CellTypeSharedString, "D1": excelize.CellTypeUnset} {
+		cellType, _ := f.GetCellType("Sheet1", cellName)
+		assert.Equal(t, expected, cellType)
+	}
+}
+
+func TestFlattenStaleFormulas(t *testing.T) {
+	source := excelize.NewFile()
+	source.SetCellValue("Sheet1", "A2", 1250)
+	source.SetSheetCol("Sheet1", "A5", &[]int{1, 2})
+	source.SetCellFormula("Sheet1", "B2", "A2*2")
+	source.SetCellFormula("Sheet1", "C2", "1/0")
+	source.SetCellFormula("Sheet1", "D2", "SUM(A5:A6)")
+	buffer, _ := source.WriteToBuffer()
+	source.Close()
+
+	// The cached results are those of the workbook before the run
+	p, _ := ooxml.ReadPackage(buffer.Bytes())
+	sheetXML, _ := p.Part("xl/worksheets/sheet1.xml")
+	content := strings.Replace(string(sheetXML), `<f>A2*2</f></c>`, `<f>A2*2</f><v>2500</v></c>`, 1)
+	content = strings.Replace(content, `<f>SUM(A5:A6)</f></c>`, `<f>SUM(A5:A6)</f><v>3</v></c>`, 1)
+	p.SetPart("xl/worksheets/sheet1.xml", []byte(content))
+	contents, _ := p.Bytes()
+	f, err := excelize.OpenReader(bytes.NewReader(contents))
+	if err != nil {
+		t.Fatalf("failed to open the workbook: %v", err)
+	}
+	defer f.Close()
+
+	rules := []types.Rule{{
+		PageCondition: types.PageCondition{SheetName: "Sheet1", FormulaMode: "flatten"},
+		Actions: []types.Action{
+			{ActionType: COMPUTE, Operation: RANGE, Value: "value * 3", Compute: &types.ComputeOptions{Target: "A2:A2"}},
+			{ActionType: EXCLUDE, Operation: ROW, Value: "5"},
+		},
+	}}
+	rulesExecutor := MakeRulesExecutor(f, rules)
+	if transformErr := rulesExecutor.Execute(); transformErr != nil {
+		t.Fatalf("failed to execute the rules: %s", transformErr.Message)
+	}
+
+	// The formulas whose inputs changed are calculated again rather than flattened to their cache
+	for cellName, expected := range map[string]string{"B2": "7500", "D2": "2"} {
+		value, _ := f.GetCellValue("Sheet1", cellName)
+		assert.Equal(t, expected, value)
+	}
+	// The formula failing to calculate is kept and reported
+	formula, _ := f.GetCellFormula("Sheet1", "C2")
+	assert.Equal(t, "1/0", formula)
+	ruleIndex := 0
+	assert.Equal(t, []types.RedactedCell{{SheetName: "Sheet1", Cell: "C2", RuleIndex: &ruleIndex}}, rulesExecutor.Manifest.UnflattenedFormulas)
+}
+
+func TestMissingSheet(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "A1", "Jane Doe")
+	f.SetCellValue("Sheet1", "B1", "Total")
+
+	// A rule naming a missing sheet is skipped, the rules after it still run
+	rules := []types.Rule{
+		{
+			PageCondition: types.PageCondition{SheetName: "Missing", IncludeFormulas: true},
+			Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "B1:B1"}},
+		},
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true, NonEmptyValueRedact: true},
+			Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"}},
+		},
+	}
+	rulesExecutor := MakeRulesExecutor(f, rules)
+	if transformErr := rulesExecutor.Execute(); transformErr != nil {
+		t.Fatalf("failed to execute rules: %s", transformErr.Message)
+	}
+
+	rows, _ := f.GetRows("Sheet1")
+	assert.Equal(t, [][]string{{"**redacted**", "Total"}}, rows)
+	assert.Equal(t, []types.SkippedRule{{RuleIndex: 0, Reason: "sheet 'Missing' does not exist"}}, rulesExecutor.Manifest.SkippedRules)
+}
+
+func TestCheckModesFirst(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "A1", "Jane Doe")
+	f.SetCellFormula("Sheet1", "B1", "A1")
+
+	// The invalid merge policy of the second rule is reported before the first rule redacts and
+	// the second one clears the formulas
+	rules := []types.Rule{
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", IncludeFormulas: true},
+			Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"}},
+		},
+		{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", MergePolicy: "split"},
+			Actions:       []types.Action{{ActionType: REDACT, Operation: RANGE, Value: "A1:A1"}},
+		},
+	}
+	transformErr := MakeRulesExecutor(f, rules).Execute()
+
+	assert.Equal(t, "Invalid merge policy", transformErr.Message)
+	assert.Equal(t, 1, *transformErr.RuleIndex)
+	value, _ := f.GetCellValue("Sheet1", "A1")
+	assert.Equal(t, "Jane Doe", value)
+	formula, _ := f.GetCellFormula("Sheet1", "B1")
+	assert.Equal(t, "A1", formula)
+}
@@ -0,0 +1,296 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"xlsx-processor/pkg/cell"
+	"xlsx-processor/pkg/expr"
+	"xlsx-processor/pkg/sheet"
+	"xlsx-processor/pkg/types"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// hexColorPattern matches the colors of the color operations, eg: 0070C0
+var hexColorPattern = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// The exclude operations allowlist rules support, their redact operations are the ones with a
+// matcher and the other rules support the registered operations
+var allowlistExcludeOperation = []string{ROW, COLUMN}
+
+// rulesValidator collects the problems of the rules
+type rulesValidator struct {
+	file       *excelize.File
+	validation *types.RulesValidation
+}
+
+// ValidateRules checks the rules without running them: the action types and operations, the
+// ranges, rows, columns, colors and regular expressions of the values and, when the workbook is
+// given, the sheets the rules refer to
+func ValidateRules(rules []types.Rule, f *excelize.File) *types.RulesValidation {
+	v := &rulesValidator{
+		file:       f,
+		validation: &types.RulesValidation{Errors: []types.TransformError{}, Warnings: []types.TransformError{}},
+	}
+	for ruleIndex, rule := range rules {
+		v.validateRule(ruleIndex, rule)
+	}
+	v.validation.Valid = len(v.validation.Errors) == 0
+	return v.validation
+}
+
+// addError records a problem that would stop the transform, actionIndex is -1 for the page condition
+func (v *rulesValidator) addError(ruleIndex, actionIndex int, message, key string) {
+	v.validation.Errors = append(v.validation.Errors, newProblem(ruleIndex, actionIndex, message, key))
+}
+
+// addWarning records a rule or an action the transform would skip
+func (v *rulesValidator) addWarning(ruleIndex, actionIndex int, message, key string) {
+	v.validation.Warnings = append(v.validation.Warnings, newProblem(ruleIndex, actionIndex, message, key))
+}
+
+func newProblem(ruleIndex, actionIndex int, message, key string) types.TransformError {
+	problem := types.TransformError{Message: message, RuleIndex: &ruleIndex, Key: key}
+	if actionIndex >= 0 {
+		problem.ActionIndex = &actionIndex
+	}
+	return problem
+}
+
+// validateRule checks the page condition of a rule, then its actions
+func (v *rulesValidator) validateRule(ruleIndex int, rule types.Rule) {
+	pageCondition := rule.PageCondition
+	sheetName := pageCondition.SheetName
+	switch {
+	case sheetName == "":
+		v.addError(ruleIndex, -1, "The sheet name is required", "sheetName")
+	case v.file != nil && !slices.Contains(v.file.GetSheetList(), sheetName):
+		// The transform skips the rule and runs the others
+		v.addWarning(ruleIndex, -1, fmt.Sprintf("Sheet '%s' does not exist, the rule would be skipped", sheetName), "sheetName")
+	}
+
+	if formulaMode, key := getFormulaMode(pageCondition); !slices.Contains([]string{KEEP, CLEAR, FLATTEN}, formulaMode) {
+		v.addError(ruleIndex, -1, "Invalid formula mode", key)
+	}
+	mode := strings.ToUpper(pageCondition.Mode)
+	if mode != "" && mode != BLOCKLIST && mode != ALLOWLIST {
+		v.addError(ruleIndex, -1, "Invalid mode", "mode")
+	}
+	mergePolicy := strings.ToUpper(pageCondition.MergePolicy)
+	if mergePolicy != "" && mergePolicy != EXPAND && mergePolicy != UNMERGE {
+		v.addError(ruleIndex, -1, "Invalid merge policy", "mergePolicy")
+	}
+
+	for conditionIndex, condition := range rule.Conditions {
+		if message := v.validateCondition(condition); message != "" {
+			v.addError(ruleIndex, -1, fmt.Sprintf("condition %d: %s", conditionIndex, message), "conditions")
+		}
+	}
+
+	for actionIndex, action := range rule.Actions {
+		v.validateAction(ruleIndex, actionIndex, action, mode == ALLOWLIST)
+	}
+}
+
+// validateCondition checks the type, the cell and the match of a condition
+func (v *rulesValidator) validateCondition(condition types.RuleCondition) string {
+	defaultMatch := EQUALS
+	switch conditionName(condition.Type) {
+	case HEADER:
+		if condition.HeaderRow < 0 {
+			return fmt.Sprintf("'%d' is not a valid header row", condition.HeaderRow)
+		}
+	case CELL:
+		if _, _, err := excelize.CellNameToCoordinates(condition.Cell); err != nil {
+			return fmt.Sprintf("'%s' is not a valid cell", condition.Cell)
+		}
+	case FILE_NAME:
+		defaultMatch = GLOB
+	default:
+		return invalidMessage("Invalid condition type", condition.Type, []string{HEADER, CELL, FILE_NAME})
+	}
+	if sheetName := condition.SheetName; sheetName != "" && v.file != nil && !slices.Contains(v.file.GetSheetList(), sheetName) {
+		return fmt.Sprintf("Sheet '%s' does not exist, the condition would not hold", sheetName)
+	}
+	if _, err := conditionMatches(condition, "", defaultMatch); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// validateAction checks the action type, the operation and the value of an action
+func (v *rulesValidator) validateAction(ruleIndex, actionIndex int, action types.Action, allowlist bool) {
+	operations, actionTypes := Operations(action.ActionType), ActionTypes()
+	if allowlist {
+		actionTypes = []string{REDACT, EXCLUDE}
+		switch action.ActionType {
+		case REDACT:
+			operations = matcherOperations(REDACT)
+		case EXCLUDE:
+			operations = allowlistExcludeOperation
+		default:
+			operations = nil
+		}
+	}
+	if operations == nil {
+		v.addError(ruleIndex, actionIndex, invalidMessage("Invalid action type", action.ActionType, actionTypes), "actionType")
+		return
+	}
+	if !slices.Contains(operations, action.Operation) {
+		v.addError(ruleIndex, actionIndex, invalidMessage("Invalid operation", action.Operation, operations), "operation")
+		return
+	}
+
+	// Empty values are skipped, dictionaries can list their terms outside of the value
+	if action.Value == "" && (action.ActionType == EXCLUDE || allowlist || action.Dictionary == nil) {
+		v.addWarning(ruleIndex, actionIndex, "The value is empty, the action would be skipped", "value")
+		return
+	}
+
+	if action.ActionType == SHEET {
+		if message := validateSheetAction(action); message != "" {
+			v.addError(ruleIndex, actionIndex, message, "value")
+		}
+		return
+	}
+	if action.ActionType == COMPUTE {
+		if message, key := validateCompute(action); message != "" {
+			v.addError(ruleIndex, actionIndex, message, key)
+		}
+		return
+	}
+	if message, key := v.validateValue(action); message != "" {
+		v.addError(ruleIndex, actionIndex, message, key)
+	}
+}
+
+// validateValue checks the value and the options of an action against its operation, it returns
+// the problem and the key to report it under
+func (v *rulesValidator) validateValue(action types.Action) (message string, key string) {
+	value := action.Value
+	switch action.Operation {
+	case RANGE:
+		split := strings.Split(value, ":")
+		if len(split) != 2 {
+			return fmt.Sprintf("'%s' is invalid, expected a range such as A1:C10", value), "value"
+		}
+		for _, cellName := range split {
+			if _, _, err := excelize.CellNameToCoordinates(cellName); err != nil {
+				return fmt.Sprintf("'%s' is invalid, expected a range such as A1:C10", value), "value"
+			}
+		}
+	case ROW:
+		if rowNum, err := strconv.Atoi(value); err != nil || rowNum < 1 || rowNum > excelize.TotalRows {
+			return fmt.Sprintf("'%s' is not a valid row number", value), "value"
+		}
+	case COLUMN:
+		if colNum := cell.ColumnToNumber(value); colNum == 0 || colNum > excelize.MaxColumns {
+			return fmt.Sprintf("'%s' is invalid, expected a column such as C", value), "value"
+		}
+	case TEXT_COLOR, BG_COLOR:
+		if !hexColorPattern.MatchString(value) {
+			return fmt.Sprintf("'%s' is not a hex color, expected 6 hex digits without # such as 0070C0", value), "value"
+		}
+	case DICTIONARY:
+		return v.validateDictionary(action)
+	case LABEL_ADJACENT:
+		options := action.Label
+		if options == nil {
+			return "", ""
+		}
+		if direction := strings.ToUpper(options.Direction); direction != "" && !slices.Contains([]string{RIGHT, BELOW, LEFT, ABOVE}, direction) {
+			return fmt.Sprintf("'%s' is not a valid direction", options.Direction), "label"
+		}
+		if options.Offset < 0 || options.Count < 0 {
+			return "The offset and the count cannot be negative", "label"
+		}
+		if options.Regex {
+			return compileMessage(value), "value"
+		}
+	case COMMENT:
+		if action.ActionType == EXCLUDE {
+			if scope := strings.ToUpper(value); scope != ALL && scope != REDACTED {
+				return fmt.Sprintf("'%s' is invalid, expected all or redacted", value), "value"
+			}
+		} else if action.Comment != nil && action.Comment.Regex {
+			return compileMessage(value), "value"
+		}
+	}
+	return "", ""
+}
+
+// validateDictionary checks a dictionary has terms and, when the workbook is given, that the
+// sheet of a column of terms exists
+func (v *rulesValidator) validateDictionary(action types.Action) (message string, key string) {
+	options := action.Dictionary
+	if action.Value == "" && (options == nil || (len(options.Terms) == 0 && options.Source == nil)) {
+		return "The dictionary has no terms", "dictionary"
+	}
+	matches := sheetColumnPattern.FindStringSubmatch(action.Value)
+	if matches != nil && v.file != nil && !slices.Contains(v.file.GetSheetList(), matches[1]) {
+		return fmt.Sprintf("Sheet '%s' of the dictionary column '%s' does not exist", matches[1], action.Value), "value"
+	}
+	return "", ""
+}
+
+// validateCompute checks the expression and the target of a compute action
+func validateCompute(action types.Action) (message string, key string) {
+	expression, err := expr.Parse(action.Value)
+	if err == nil {
+		err = expression.Check(computeVariables, computeFunctions)
+	}
+	if err != nil {
+		return fmt.Sprintf("'%s' is not a valid expression: %v", action.Value, err), "value"
+	}
+	target := ""
+	if action.Compute != nil {
+		target = action.Compute.Target
+	}
+	if _, _, _, _, err = parseComputeTarget(action.Operation, target); err != nil {
+		return err.Error(), "compute"
+	}
+	return "", ""
+}
+
+// validateSheetAction checks the sheet names, the patterns or the position of a sheet action.
+// Whether the sheets exist is left to the transform, as the earlier rules can change them
+func validateSheetAction(action types.Action) string {
+	var err error
+	switch action.Operation {
+	case REMOVE, KEEP:
+		_, err = sheetPatterns(action.Value)
+	case RENAME, COPY:
+		err = sheet.ValidateName(action.Value)
+	case MOVE:
+		if position, convErr := strconv.Atoi(action.Value); convErr != nil || position < 1 {
+			err = fmt.Errorf("'%s' is not a valid position, expected a number from 1", action.Value)
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// compileMessage returns the problem of a regular expression, if any
+func compileMessage(pattern string) string {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Sprintf("'%s' is not a valid regular expression: %v", pattern, err)
+	}
+	return ""
+}
+
+// invalidMessage suggests the expected value when only its case differs, eg: redact for REDACT
+func invalidMessage(message, value string, expected []string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(value, " ", "_"))
+	for _, candidate := range expected {
+		if normalized == candidate || strings.ReplaceAll(normalized, "_", "") == strings.ReplaceAll(candidate, "_", "") {
+			return fmt.Sprintf("%s '%s', did you mean '%s'?", message, value, candidate)
+		}
+	}
+	return fmt.Sprintf("%s '%s', expected one of %s", message, value, strings.Join(expected, ", "))
+}
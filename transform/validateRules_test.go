@@ -0,0 +1,108 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+
+	"xlsx-processor/pkg/types"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// problems returns the message of each problem along with its rule and action
+func problems(list []types.TransformError) []string {
+	var messages []string
+	for _, problem := range list {
+		location := fmt.Sprintf("rule %d", *problem.RuleIndex)
+		if problem.ActionIndex != nil {
+			location += fmt.Sprintf(" action %d", *problem.ActionIndex)
+		}
+		messages = append(messages, location+" "+problem.Key+": "+problem.Message)
+	}
+	return messages
+}
+
+func TestValidateRules(t *testing.T) {
+	t.Run("valid rules", func(t *testing.T) {
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1", FormulaMode: "flatten"},
+			Actions: []types.Action{
+				{Operation: RANGE, Value: "A1:C10", ActionType: REDACT},
+				{Operation: BG_COLOR, Value: "0070C0", ActionType: REDACT},
+				{Operation: COMMENT, Value: "redacted", ActionType: EXCLUDE},
+				{Operation: DICTIONARY, ActionType: REDACT, Dictionary: &types.DictionaryOptions{Terms: []string{"Jane"}}},
+			},
+		}}
+
+		validation := ValidateRules(rules, nil)
+		assert.Equal(t, true, validation.Valid)
+		assert.Equal(t, 0, len(validation.Warnings))
+	})
+
+	t.Run("report every problem", func(t *testing.T) {
+		rules := []types.Rule{
+			{
+				PageCondition: types.PageCondition{SheetName: "Sheet1", Mode: "denylist"},
+				Actions: []types.Action{
+					{Operation: "range", Value: "A1:C10", ActionType: REDACT},
+					{Operation: RANGE, Value: "A1", ActionType: REDACT},
+					{Operation: ROW, Value: "0", ActionType: EXCLUDE},
+					{Operation: COLUMN, Value: "C1", ActionType: EXCLUDE},
+					{Operation: TEXT_COLOR, Value: "#0070C0", ActionType: REDACT},
+					{Operation: COMMENT, Value: "SSN(", ActionType: REDACT, Comment: &types.CommentOptions{Regex: true}},
+					{Operation: VALUE, Value: "", ActionType: REDACT},
+					{Operation: VALUE, Value: "x", ActionType: "redact"},
+				},
+			},
+			{
+				PageCondition: types.PageCondition{SheetName: "Missing", Mode: "allowlist"},
+				Actions: []types.Action{
+					{Operation: LABEL_ADJACENT, Value: "SSN:", ActionType: REDACT},
+					{Operation: DICTIONARY, Value: "Roster!B", ActionType: REDACT},
+				},
+			},
+		}
+
+		validation := ValidateRules(rules, excelize.NewFile())
+		assert.Equal(t, false, validation.Valid)
+		assert.Equal(t, []string{
+			"rule 0 mode: Invalid mode",
+			"rule 0 action 0 operation: Invalid operation 'range', did you mean 'RANGE'?",
+			"rule 0 action 1 value: 'A1' is invalid, expected a range such as A1:C10",
+			"rule 0 action 2 value: '0' is not a valid row number",
+			"rule 0 action 3 value: 'C1' is invalid, expected a column such as C",
+			"rule 0 action 4 value: '#0070C0' is not a hex color, expected 6 hex digits without # such as 0070C0",
+			"rule 0 action 5 value: 'SSN(' is not a valid regular expression: error parsing regexp: missing closing ): `SSN(`",
+			"rule 0 action 7 actionType: Invalid action type 'redact', did you mean 'REDACT'?",
+			"rule 1 action 0 operation: Invalid operation 'LABEL_ADJACENT', expected one of RANGE, VALUE, TEXT_COLOR, BG_COLOR, COLUMN, ROW",
+			"rule 1 action 1 operation: Invalid operation 'DICTIONARY', expected one of RANGE, VALUE, TEXT_COLOR, BG_COLOR, COLUMN, ROW",
+		}, problems(validation.Errors))
+		assert.Equal(t, []string{
+			"rule 0 action 6 value: The value is empty, the action would be skipped",
+			"rule 1 sheetName: Sheet 'Missing' does not exist, the rule would be skipped",
+		}, problems(validation.Warnings))
+	})
+
+	t.Run("warn about a missing sheet", func(t *testing.T) {
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Missing"},
+			Actions:       []types.Action{{Operation: RANGE, Value: "A1:B2", ActionType: REDACT}},
+		}}
+
+		validation := ValidateRules(rules, excelize.NewFile())
+		assert.Equal(t, true, validation.Valid)
+		assert.Equal(t, []string{"rule 0 sheetName: Sheet 'Missing' does not exist, the rule would be skipped"}, problems(validation.Warnings))
+	})
+
+	t.Run("check the sheet of a dictionary column", func(t *testing.T) {
+		rules := []types.Rule{{
+			PageCondition: types.PageCondition{SheetName: "Sheet1"},
+			Actions:       []types.Action{{Operation: DICTIONARY, Value: "Roster!B", ActionType: REDACT}},
+		}}
+
+		assert.Equal(t, true, ValidateRules(rules, nil).Valid)
+		assert.Equal(t, []string{"rule 0 action 0 value: Sheet 'Roster' of the dictionary column 'Roster!B' does not exist"},
+			problems(ValidateRules(rules, excelize.NewFile()).Errors))
+	})
+}